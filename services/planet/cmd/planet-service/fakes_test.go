@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// The fakes below keep one planet and its defense in memory. Writes run the
+// planner they are given against that state, like the real repositories do
+// under the planet lock, so the services' own checks still apply.
+
+type fakePlanetRepo struct {
+	planet generated.Planet
+}
+
+func (r *fakePlanetRepo) GetByID(_ context.Context, id uuid.UUID) (generated.Planet, error) {
+	if id != r.planet.ID {
+		return generated.Planet{}, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+	}
+	return r.planet, nil
+}
+
+func (r *fakePlanetRepo) GetByPlayerID(_ context.Context, playerID uuid.UUID) (generated.Planet, error) {
+	if playerID != r.planet.PlayerID {
+		return generated.Planet{}, apperrors.NewNotFoundError("planet", "player has no planet")
+	}
+	return r.planet, nil
+}
+
+func (r *fakePlanetRepo) Update(ctx context.Context, id uuid.UUID, apply repository.PlanetUpdater) (generated.Planet, error) {
+	planet, err := r.GetByID(ctx, id)
+	if err != nil {
+		return generated.Planet{}, err
+	}
+	params, err := apply(planet)
+	if err != nil {
+		return generated.Planet{}, err
+	}
+
+	planet.Name = params.Name
+	planet.Resources = params.Resources
+	planet.CurrentWave = params.CurrentWave
+	planet.Health = params.Health
+	planet.Shields = params.Shields
+	planet.Status = params.Status
+	r.planet = planet
+	return planet, nil
+}
+
+func (r *fakePlanetRepo) Rebuild(ctx context.Context, id uuid.UUID, apply repository.PlanetUpdater) (generated.Planet, error) {
+	if _, err := r.Update(ctx, id, apply); err != nil {
+		return generated.Planet{}, err
+	}
+	r.planet.Status = types.PlanetActive
+	return r.planet, nil
+}
+
+func (r *fakePlanetRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	_, err := r.GetByID(ctx, id)
+	return err
+}
+
+func (r *fakePlanetRepo) pay(params generated.UpdatePlanetResourcesParams) []byte {
+	r.planet.Resources = params.Resources
+	r.planet.Health = params.Health
+	return params.Resources
+}
+
+type fakeDefenseRepo struct {
+	planets *fakePlanetRepo
+	defense generated.DefenseSystem
+}
+
+func (r *fakeDefenseRepo) Create(_ context.Context, arg generated.CreateDefenseSystemParams) (generated.DefenseSystem, error) {
+	return generated.DefenseSystem{
+		ID:          uuid.New(),
+		PlanetID:    arg.PlanetID,
+		Kind:        arg.Kind,
+		Name:        arg.Name,
+		Damage:      arg.Damage,
+		Range:       arg.Range,
+		FireRate:    arg.FireRate,
+		DamageType:  arg.DamageType,
+		Level:       1,
+		UpgradeCost: arg.UpgradeCost,
+		Position:    arg.Position,
+	}, nil
+}
+
+func (r *fakeDefenseRepo) ListByPlanetID(context.Context, uuid.UUID) ([]generated.DefenseSystem, error) {
+	return []generated.DefenseSystem{r.defense}, nil
+}
+
+func (r *fakeDefenseRepo) Delete(_ context.Context, _, defenseID uuid.UUID) error {
+	if defenseID != r.defense.ID {
+		return apperrors.NewNotFoundError("defense", "defense with given ID does not exist")
+	}
+	return nil
+}
+
+func (r *fakeDefenseRepo) Move(_ context.Context, _, defenseID uuid.UUID, position int32) ([]generated.DefenseSystem, error) {
+	if defenseID != r.defense.ID {
+		return nil, apperrors.NewNotFoundError("defense", "defense with given ID does not exist")
+	}
+	r.defense.Position = position
+	return []generated.DefenseSystem{r.defense}, nil
+}
+
+func (r *fakeDefenseRepo) Upgrade(_ context.Context, _, defenseID uuid.UUID, plan repository.UpgradePlanner) (generated.DefenseSystem, []byte, error) {
+	if defenseID != r.defense.ID {
+		return generated.DefenseSystem{}, nil, apperrors.NewNotFoundError("defense", "defense with given ID does not exist")
+	}
+	pay, upgrade, err := plan(r.planets.planet, r.defense)
+	if err != nil {
+		return generated.DefenseSystem{}, nil, err
+	}
+	r.defense.UpgradeStartedAt = upgrade.UpgradeStartedAt
+	r.defense.UpgradeCompletesAt = upgrade.UpgradeCompletesAt
+	return r.defense, r.planets.pay(pay), nil
+}
+
+type fakeBuildingRepo struct {
+	planets *fakePlanetRepo
+}
+
+func (r *fakeBuildingRepo) ListByPlanetID(context.Context, uuid.UUID) ([]generated.PlanetBuilding, error) {
+	return nil, nil
+}
+
+func (r *fakeBuildingRepo) Queue(_ context.Context, planetID uuid.UUID, kind string, plan repository.BuildPlanner) (generated.PlanetBuilding, []byte, error) {
+	pay, build, err := plan(r.planets.planet, generated.PlanetBuilding{PlanetID: planetID, Kind: kind})
+	if err != nil {
+		return generated.PlanetBuilding{}, nil, err
+	}
+	return generated.PlanetBuilding{
+		ID:               uuid.New(),
+		PlanetID:         planetID,
+		Kind:             kind,
+		Level:            build.Level,
+		BuildStartedAt:   build.BuildStartedAt,
+		BuildCompletesAt: build.BuildCompletesAt,
+	}, r.planets.pay(pay), nil
+}
+
+type fakeResearchRepo struct {
+	planets *fakePlanetRepo
+}
+
+func (r *fakeResearchRepo) ListByPlanetID(context.Context, uuid.UUID) ([]generated.PlanetResearch, error) {
+	return nil, nil
+}
+
+func (r *fakeResearchRepo) Queue(_ context.Context, planetID uuid.UUID, tech string, plan repository.ResearchPlanner) (generated.PlanetResearch, []byte, error) {
+	pay, research, _, err := plan(r.planets.planet, generated.PlanetResearch{PlanetID: planetID, Tech: tech})
+	if err != nil {
+		return generated.PlanetResearch{}, nil, err
+	}
+	return generated.PlanetResearch{
+		ID:                  uuid.New(),
+		PlanetID:            planetID,
+		Tech:                tech,
+		Level:               research.Level,
+		ResearchStartedAt:   research.ResearchStartedAt,
+		ResearchCompletesAt: research.ResearchCompletesAt,
+	}, r.planets.pay(pay), nil
+}
+
+type fakeLedgerRepo struct{}
+
+func (fakeLedgerRepo) List(context.Context, generated.ListPlanetLedgerParams) ([]generated.ResourceLedger, error) {
+	return nil, nil
+}
+
+func (fakeLedgerRepo) FindEntry(context.Context, string, uuid.UUID) (*generated.ResourceLedger, error) {
+	return nil, nil
+}
+
+type fakeBattleRepo struct {
+	planets  *fakePlanetRepo
+	defenses *fakeDefenseRepo
+	alien    generated.AlienTemplate
+	battle   generated.Battle // a battle already fought by the planet
+}
+
+func (r *fakeBattleRepo) GetByID(_ context.Context, id uuid.UUID) (generated.Battle, error) {
+	if id != r.battle.ID {
+		return generated.Battle{}, apperrors.NewNotFoundError("battle", "battle with given ID does not exist")
+	}
+	return r.battle, nil
+}
+
+func (r *fakeBattleRepo) List(context.Context, generated.ListPlanetBattlesParams) ([]generated.ListPlanetBattlesRow, error) {
+	return nil, nil
+}
+
+func (r *fakeBattleRepo) Record(_ context.Context, planetID uuid.UUID, idempotencyKey string, fight repository.BattleFighter) (generated.Battle, bool, error) {
+	state, params, _, err := fight(r.planets.planet, []generated.DefenseSystem{r.defenses.defense})
+	if err != nil {
+		return generated.Battle{}, false, err
+	}
+	r.planets.planet.Health = state.Health
+	r.planets.planet.Shields = state.Shields
+	r.planets.planet.CurrentWave = state.CurrentWave
+
+	return generated.Battle{
+		ID:               uuid.New(),
+		PlanetID:         planetID,
+		WaveID:           params.WaveID,
+		WaveNumber:       params.WaveNumber,
+		Seed:             params.Seed,
+		Outcome:          params.Outcome,
+		DamageTaken:      params.DamageTaken,
+		ShieldsRemaining: params.ShieldsRemaining,
+		HpRemaining:      params.HpRemaining,
+		AliensDestroyed:  params.AliensDestroyed,
+		Loot:             params.Loot,
+		Events:           params.Events,
+		Snapshot:         params.Snapshot,
+		IdempotencyKey:   pgtype.Text{String: idempotencyKey, Valid: true},
+		FoughtAt:         params.FoughtAt,
+	}, true, nil
+}
+
+func (r *fakeBattleRepo) GetWave(context.Context, uuid.UUID) (generated.Wave, []generated.WaveSpawn, error) {
+	return generated.Wave{}, nil, apperrors.NewNotFoundError("wave", "wave with given ID does not exist")
+}
+
+func (r *fakeBattleRepo) FindAlienTemplates(context.Context, []uuid.UUID) ([]generated.AlienTemplate, error) {
+	return []generated.AlienTemplate{r.alien}, nil
+}
+
+func (r *fakeBattleRepo) ListActiveAlienTemplates(context.Context) ([]generated.AlienTemplate, error) {
+	return []generated.AlienTemplate{r.alien}, nil
+}
+
+type fakeAPIKeyRepo struct{}
+
+func (fakeAPIKeyRepo) Create(_ context.Context, params generated.CreateAPIKeyParams) (generated.ApiKey, error) {
+	return generated.ApiKey{ID: uuid.New(), Name: params.Name, KeyHash: params.KeyHash, Scopes: params.Scopes}, nil
+}
+
+func (fakeAPIKeyRepo) List(context.Context) ([]generated.ApiKey, error) {
+	return nil, nil
+}
+
+func (fakeAPIKeyRepo) Rotate(_ context.Context, id uuid.UUID, keyHash string, _ *time.Time, _ time.Time) (generated.ApiKey, error) {
+	return generated.ApiKey{ID: uuid.New(), Name: "rotated", KeyHash: keyHash, RotatedFrom: pgtype.UUID{Bytes: id, Valid: true}}, nil
+}
+
+func (fakeAPIKeyRepo) Revoke(_ context.Context, id uuid.UUID) (generated.ApiKey, error) {
+	return generated.ApiKey{ID: id, Name: "revoked", RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}, nil
+}
+
+// fakeKeyStore holds API keys by the raw key sent in requests.
+type fakeKeyStore map[string]auth.APIKey
+
+func (s fakeKeyStore) LookupAPIKey(_ context.Context, hash string) (auth.APIKey, error) {
+	for key, apiKey := range s {
+		if auth.HashAPIKey(key) == hash {
+			return apiKey, nil
+		}
+	}
+	return auth.APIKey{}, auth.ErrInvalidAPIKey
+}
+
+func mustJSON(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -11,9 +14,9 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 
-	"github.com/novaru/scallopticon/services/planet/internal/handlers"
 	"github.com/novaru/scallopticon/services/planet/internal/repository"
 	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/auth"
 	"github.com/novaru/scallopticon/shared/db/generated"
 )
 
@@ -30,6 +33,11 @@ func main() {
 		logger.Fatal("DATABASE_URL not set")
 	}
 
+	signer, err := auth.NewSigner([]byte(os.Getenv("AUTH_SECRET")), service.AccessTokenTTL)
+	if err != nil {
+		logger.Fatal("AUTH_SECRET not usable", zap.Error(err))
+	}
+
 	pool, err := pgxpool.New(context.Background(), dbURL)
 	if err != nil {
 		logger.Fatal("DB connect error", zap.Error(err))
@@ -37,22 +45,40 @@ func main() {
 	defer pool.Close()
 
 	q := generated.New(pool)
-	repo := repository.NewPlayerRepository(q, logger)
-	svc := service.NewPlayerService(repo, logger)
-	handler := handlers.NewPlayerHandler(svc)
+	h := newHandlers(repositories{
+		players:   repository.NewPlayerRepository(q, pool, logger),
+		auth:      repository.NewAuthRepository(q, pool, logger),
+		apiKeys:   repository.NewAPIKeyRepository(q, pool, logger),
+		planets:   repository.NewPlanetRepository(q, pool, logger),
+		defenses:  repository.NewDefenseRepository(q, pool, logger),
+		buildings: repository.NewBuildingRepository(q, pool, logger),
+		research:  repository.NewResearchRepository(q, pool, logger),
+		ledger:    repository.NewLedgerRepository(q, logger),
+		battles:   repository.NewBattleRepository(q, pool, logger),
+	}, signer, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheduler := service.NewScheduler(repository.NewJobRepository(q, pool, logger), service.DefaultSchedulerInterval, logger)
+	go scheduler.Run(ctx)
 
 	r := chi.NewRouter()
 
 	r.Use(middleware.Logger)
 
-	r.Route("/players", func(r chi.Router) {
-		r.Get("/", handler.GetPlayers)
-		r.Get("/{id}", handler.GetPlayerByID)
-		r.Post("/", handler.CreatePlayer)
-	})
+	routes(r, auth.NewAuthenticator(signer, auth.NewKeyStore(q)), h)
+
+	srv := &http.Server{Addr: ":5000", Handler: r}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			logger.Error("HTTP server shutdown error", zap.Error(err))
+		}
+	}()
 
 	logger.Info("Planet service running on :5000")
-	if err := http.ListenAndServe(":5000", r); err != nil {
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Fatal("HTTP server error", zap.Error(err))
 	}
 }
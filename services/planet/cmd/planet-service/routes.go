@@ -0,0 +1,127 @@
+package main
+
+import (
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/handlers"
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/auth"
+)
+
+// repositories is what the planet service's handlers are built on.
+type repositories struct {
+	players   repository.PlayerRepository
+	auth      repository.AuthRepository
+	apiKeys   repository.APIKeyRepository
+	planets   repository.PlanetRepository
+	defenses  repository.DefenseRepository
+	buildings repository.BuildingRepository
+	research  repository.ResearchRepository
+	ledger    repository.LedgerRepository
+	battles   repository.BattleRepository
+}
+
+type apiHandlers struct {
+	players   *handlers.PlayerHandler
+	auth      *handlers.AuthHandler
+	apiKeys   *handlers.APIKeyHandler
+	planets   *handlers.PlanetHandler
+	defenses  *handlers.DefenseHandler
+	buildings *handlers.BuildingHandler
+	research  *handlers.ResearchHandler
+	ledger    *handlers.LedgerHandler
+	battles   *handlers.BattleHandler
+}
+
+func newHandlers(repos repositories, signer *auth.Signer, logger *zap.Logger) apiHandlers {
+	authz := service.NewAuthorizer(repos.planets, logger)
+
+	return apiHandlers{
+		players:   handlers.NewPlayerHandler(service.NewPlayerService(repos.players, logger)),
+		auth:      handlers.NewAuthHandler(service.NewAuthService(repos.auth, repos.players, signer, logger)),
+		apiKeys:   handlers.NewAPIKeyHandler(service.NewAPIKeyService(repos.apiKeys, logger)),
+		planets:   handlers.NewPlanetHandler(service.NewPlanetService(repos.planets, repos.defenses, repos.buildings, authz, logger)),
+		defenses:  handlers.NewDefenseHandler(service.NewDefenseService(repos.defenses, repos.planets, repos.buildings, authz, logger)),
+		buildings: handlers.NewBuildingHandler(service.NewBuildingService(repos.buildings, repos.planets, authz, logger)),
+		research:  handlers.NewResearchHandler(service.NewResearchService(repos.research, repos.planets, repos.buildings, authz, logger)),
+		ledger:    handlers.NewLedgerHandler(service.NewLedgerService(repos.ledger, repos.planets, authz, logger)),
+		battles: handlers.NewBattleHandler(service.NewBattleService(repos.battles, repos.buildings,
+			repos.research, repos.ledger, authz, logger)),
+	}
+}
+
+// routes registers the planet service's API on r. Reads only need a
+// caller; changes to a planet, and replaying its battles, also need an API
+// key scope. The services check the caller owns the planet for changes and
+// for its battles and ledger.
+func routes(r chi.Router, authn *auth.Authenticator, h apiHandlers) {
+	canWritePlanets := auth.RequireScope(auth.ScopePlanetsWrite)
+	canRunBattles := auth.RequireScope(auth.ScopeBattlesRun)
+
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", h.auth.Login)
+		r.Post("/refresh", h.auth.Refresh)
+	})
+
+	r.Route("/players", func(r chi.Router) {
+		r.Post("/", h.players.CreatePlayer)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authn.Authenticate)
+			r.Get("/", h.players.GetPlayers)
+			r.Get("/{id}", h.players.GetPlayerByID)
+			r.Get("/{id}/planet", h.planets.GetPlayerPlanet)
+		})
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(authn.Authenticate)
+
+		r.Route("/planets/{id}", func(r chi.Router) {
+			r.Get("/", h.planets.GetPlanetByID)
+			r.With(canWritePlanets).Patch("/", h.planets.UpdatePlanet)
+			r.With(canWritePlanets).Delete("/", h.planets.DeletePlanet)
+			r.With(canWritePlanets).Post("/repair", h.planets.RepairPlanet)
+			r.With(canWritePlanets).Post("/rebuild", h.planets.RebuildPlanet)
+			r.Get("/ledger", h.ledger.GetLedger)
+
+			r.Route("/defenses", func(r chi.Router) {
+				r.Get("/", h.defenses.GetDefenses)
+				r.With(canWritePlanets).Post("/", h.defenses.AddDefense)
+				r.With(canWritePlanets).Delete("/{defenseID}", h.defenses.RemoveDefense)
+				r.With(canWritePlanets).Put("/{defenseID}/position", h.defenses.MoveDefense)
+				r.With(canWritePlanets).Post("/{defenseID}/upgrade", h.defenses.UpgradeDefense)
+			})
+
+			r.Route("/buildings", func(r chi.Router) {
+				r.Get("/", h.buildings.GetBuildings)
+				r.With(canWritePlanets).Post("/", h.buildings.QueueBuilding)
+			})
+
+			r.Route("/research", func(r chi.Router) {
+				r.Get("/", h.research.GetResearch)
+				r.With(canWritePlanets).Post("/", h.research.QueueResearch)
+			})
+
+			r.Route("/battles", func(r chi.Router) {
+				r.Get("/", h.battles.GetBattles)
+				r.With(canRunBattles).Post("/", h.battles.FightBattle)
+			})
+		})
+
+		r.Route("/battles/{id}", func(r chi.Router) {
+			r.Get("/", h.battles.GetBattle)
+			r.With(canRunBattles).Post("/replay", h.battles.ReplayBattle)
+			r.With(canRunBattles).Get("/stream", h.battles.StreamBattle)
+		})
+
+		r.Route("/api-keys", func(r chi.Router) {
+			r.Get("/", h.apiKeys.GetAPIKeys)
+			r.Post("/", h.apiKeys.CreateAPIKey)
+			r.Post("/{keyID}/rotate", h.apiKeys.RotateAPIKey)
+			r.Delete("/{keyID}", h.apiKeys.RevokeAPIKey)
+		})
+	})
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// API keys known to the fake key store.
+const (
+	planetsKey = "sk_planets"
+	battlesKey = "sk_battles"
+	noScopeKey = "sk_none"
+)
+
+// world is a router over fake repositories holding one planet, owned by
+// owner, with one defense on it.
+type world struct {
+	router    http.Handler
+	planets   *fakePlanetRepo
+	planetID  uuid.UUID
+	defenseID uuid.UUID
+	battleID  uuid.UUID
+	keyID     uuid.UUID
+	tokens    map[string]string // bearer tokens by caller name
+}
+
+func newWorld(t *testing.T) *world {
+	t.Helper()
+
+	signer, err := auth.NewSigner([]byte(strings.Repeat("k", 32)), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	owner, other, admin := uuid.New(), uuid.New(), uuid.New()
+	w := &world{planetID: uuid.New(), defenseID: uuid.New(), battleID: uuid.New(), keyID: uuid.New(), tokens: map[string]string{}}
+	for name, caller := range map[string]struct {
+		id   uuid.UUID
+		role string
+	}{
+		"owner": {owner, auth.RolePlayer},
+		"other": {other, auth.RolePlayer},
+		"admin": {admin, auth.RoleAdmin},
+	} {
+		token, _, err := signer.Issue(caller.id, caller.role, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.tokens[name] = token
+	}
+
+	w.planets = &fakePlanetRepo{planet: generated.Planet{
+		ID:               w.planetID,
+		PlayerID:         owner,
+		Name:             "Home",
+		Resources:        mustJSON(types.Resources{Minerals: 1_000_000, Energy: 1_000_000, TechParts: 1_000_000}),
+		Health:           50,
+		MaxHealth:        100,
+		MaxShields:       50,
+		ShieldRegen:      1,
+		ShieldRegenDelay: 2,
+		Status:           types.PlanetActive,
+		UpdatedAt:        pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}}
+	defense := generated.DefenseSystem{
+		ID:          w.defenseID,
+		PlanetID:    w.planetID,
+		Kind:        "autocannon",
+		Name:        "Autocannon",
+		Damage:      10,
+		Range:       5,
+		FireRate:    1,
+		DamageType:  types.DamageKinetic,
+		Level:       1,
+		UpgradeCost: mustJSON(types.Resources{Minerals: 100}),
+	}
+	alien := generated.AlienTemplate{
+		ID:           uuid.New(),
+		Name:         "Drone",
+		Hp:           10,
+		Damage:       1,
+		Speed:        1,
+		BehaviorType: "basic",
+		DamageType:   types.DamageKinetic,
+		Resistances:  []byte(`{}`),
+		LootDrop:     mustJSON(types.Resources{Minerals: 1}),
+		Version:      1,
+	}
+	// A battle against an empty wave, which ends on its first tick.
+	battle := generated.Battle{
+		ID:       w.battleID,
+		PlanetID: w.planetID,
+		Outcome:  types.OutcomeVictory,
+		Events:   []byte(`[]`),
+		Loot:     []byte(`{}`),
+		Snapshot: mustJSON(map[string]any{"planet": types.Planet{ID: w.planetID.String(), HP: 50, MaxHP: 100}}),
+		FoughtAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+
+	defenses := &fakeDefenseRepo{planets: w.planets, defense: defense}
+	h := newHandlers(repositories{
+		apiKeys:   fakeAPIKeyRepo{},
+		planets:   w.planets,
+		defenses:  defenses,
+		buildings: &fakeBuildingRepo{planets: w.planets},
+		research:  &fakeResearchRepo{planets: w.planets},
+		ledger:    fakeLedgerRepo{},
+		battles:   &fakeBattleRepo{planets: w.planets, defenses: defenses, alien: alien, battle: battle},
+	}, signer, zap.NewNop())
+
+	keys := fakeKeyStore{
+		planetsKey: {ID: uuid.New(), Name: "planets", Scopes: []string{auth.ScopePlanetsWrite}},
+		battlesKey: {ID: uuid.New(), Name: "battles", Scopes: []string{auth.ScopeBattlesRun}},
+		noScopeKey: {ID: uuid.New(), Name: "none"},
+	}
+
+	r := chi.NewRouter()
+	routes(r, auth.NewAuthenticator(signer, keys), h)
+	w.router = r
+	return w
+}
+
+// serve sends a request as caller: a name from w.tokens, an API key, or
+// "" for no credentials.
+func (w *world) serve(method, path, body, caller string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", uuid.NewString())
+	if token, ok := w.tokens[caller]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if caller != "" {
+		req.Header.Set(auth.APIKeyHeader, caller)
+	}
+
+	rec := httptest.NewRecorder()
+	w.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// expect is the outcome of a request: a 2xx status, or status and an error
+// code.
+type expect struct {
+	status int
+	code   string
+}
+
+var (
+	allowed      = expect{}
+	forbidden    = expect{http.StatusForbidden, "FORBIDDEN"}
+	unauthorized = expect{http.StatusUnauthorized, "UNAUTHORIZED"}
+)
+
+func checkResponse(t *testing.T, rec *httptest.ResponseRecorder, want expect) {
+	t.Helper()
+
+	if want.status == 0 {
+		if rec.Code < 200 || rec.Code > 299 {
+			t.Fatalf("status = %d, want 2xx; body %s", rec.Code, rec.Body)
+		}
+		return
+	}
+
+	if rec.Code != want.status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, want.status, rec.Body)
+	}
+	var body response.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding body %q: %v", rec.Body, err)
+	}
+	if body.Success || body.Error == nil || body.Error.Code != want.code {
+		t.Fatalf("body = %s, want error code %s", rec.Body, want.code)
+	}
+}
+
+func TestPlanetWriteRoutes(t *testing.T) {
+	routes := []struct {
+		name    string
+		method  string
+		path    string // %p is the planet ID, %d the defense ID
+		body    string
+		scope   string // the API key granted the route's scope
+		prepare func(*generated.Planet)
+	}{
+		{name: "update planet", method: http.MethodPatch, path: "/planets/%p", body: `{"name":"Renamed"}`, scope: planetsKey},
+		{name: "delete planet", method: http.MethodDelete, path: "/planets/%p", scope: planetsKey},
+		{name: "repair planet", method: http.MethodPost, path: "/planets/%p/repair", scope: planetsKey},
+		{name: "rebuild planet", method: http.MethodPost, path: "/planets/%p/rebuild", scope: planetsKey,
+			prepare: func(p *generated.Planet) {
+				p.Status = types.PlanetDestroyed
+				p.Health = 0
+			}},
+		{name: "add defense", method: http.MethodPost, path: "/planets/%p/defenses", body: `{"kind":"laser_turret"}`, scope: planetsKey},
+		{name: "remove defense", method: http.MethodDelete, path: "/planets/%p/defenses/%d", scope: planetsKey},
+		{name: "move defense", method: http.MethodPut, path: "/planets/%p/defenses/%d/position", body: `{"position":3}`, scope: planetsKey},
+		{name: "upgrade defense", method: http.MethodPost, path: "/planets/%p/defenses/%d/upgrade", scope: planetsKey},
+		{name: "queue building", method: http.MethodPost, path: "/planets/%p/buildings", body: `{"kind":"mineral_mine"}`, scope: planetsKey},
+		{name: "queue research", method: http.MethodPost, path: "/planets/%p/research", body: `{"tech":"shield_capacity"}`, scope: planetsKey},
+		{name: "fight battle", method: http.MethodPost, path: "/planets/%p/battles", body: `{"seed":1}`, scope: battlesKey},
+	}
+
+	for _, rt := range routes {
+		callers := []struct {
+			name   string
+			caller string
+			want   expect
+		}{
+			{"owner", "owner", allowed},
+			{"other player", "other", forbidden},
+			{"admin", "admin", allowed},
+			{"API key with scope", rt.scope, allowed},
+			{"API key without scope", noScopeKey, forbidden},
+			{"no credentials", "", unauthorized},
+		}
+		for _, c := range callers {
+			t.Run(rt.name+"/"+c.name, func(t *testing.T) {
+				w := newWorld(t)
+				if rt.prepare != nil {
+					rt.prepare(&w.planets.planet)
+				}
+				path := strings.NewReplacer("%p", w.planetID.String(), "%d", w.defenseID.String()).Replace(rt.path)
+
+				checkResponse(t, w.serve(rt.method, path, rt.body, c.caller), c.want)
+			})
+		}
+	}
+}
+
+func TestPlanetReadRoutes(t *testing.T) {
+	routes := []struct {
+		name   string
+		method string
+		path   string // %p is the planet ID, %b the battle ID
+		scoped bool   // API keys need the battles:run scope
+	}{
+		{name: "list battles", method: http.MethodGet, path: "/planets/%p/battles"},
+		{name: "get battle", method: http.MethodGet, path: "/battles/%b"},
+		{name: "replay battle", method: http.MethodPost, path: "/battles/%b/replay", scoped: true},
+		{name: "stream battle", method: http.MethodGet, path: "/battles/%b/stream", scoped: true},
+		{name: "ledger", method: http.MethodGet, path: "/planets/%p/ledger"},
+	}
+
+	for _, rt := range routes {
+		withoutScope := allowed
+		if rt.scoped {
+			withoutScope = forbidden
+		}
+		callers := []struct {
+			name   string
+			caller string
+			want   expect
+		}{
+			{"owner", "owner", allowed},
+			{"other player", "other", forbidden},
+			{"admin", "admin", allowed},
+			{"API key with scope", battlesKey, allowed},
+			{"API key without scope", noScopeKey, withoutScope},
+			{"no credentials", "", unauthorized},
+		}
+		for _, c := range callers {
+			t.Run(rt.name+"/"+c.name, func(t *testing.T) {
+				w := newWorld(t)
+				path := strings.NewReplacer("%p", w.planetID.String(), "%b", w.battleID.String()).Replace(rt.path)
+
+				checkResponse(t, w.serve(rt.method, path, "", c.caller), c.want)
+			})
+		}
+	}
+}
+
+func TestUpdatePlanetStateFields(t *testing.T) {
+	bodies := map[string]string{
+		"hp":           `{"hp":100}`,
+		"shields":      `{"shields":10}`,
+		"current_wave": `{"current_wave":9}`,
+		"resources":    `{"resources":{"minerals":1,"energy":1,"tech_parts":1}}`,
+	}
+	callers := []struct {
+		caller string
+		want   expect
+	}{
+		{"owner", forbidden},
+		{"other", forbidden},
+		{"admin", allowed},
+		{planetsKey, allowed},
+	}
+
+	for field, body := range bodies {
+		for _, c := range callers {
+			t.Run(field+"/"+c.caller, func(t *testing.T) {
+				w := newWorld(t)
+				rec := w.serve(http.MethodPatch, "/planets/"+w.planetID.String(), body, c.caller)
+				checkResponse(t, rec, c.want)
+			})
+		}
+	}
+}
+
+func TestAPIKeyRoutes(t *testing.T) {
+	routes := []struct {
+		name   string
+		method string
+		path   string // %k is the key ID
+		body   string
+	}{
+		{name: "list keys", method: http.MethodGet, path: "/api-keys"},
+		{name: "create key", method: http.MethodPost, path: "/api-keys", body: `{"name":"sim","scopes":["battles:run"]}`},
+		{name: "rotate key", method: http.MethodPost, path: "/api-keys/%k/rotate", body: `{}`},
+		{name: "revoke key", method: http.MethodDelete, path: "/api-keys/%k"},
+	}
+	callers := []struct {
+		name   string
+		caller string
+		want   expect
+	}{
+		{"player", "owner", forbidden},
+		{"admin", "admin", allowed},
+		{"API key", planetsKey, forbidden},
+		{"no credentials", "", unauthorized},
+	}
+
+	for _, rt := range routes {
+		for _, c := range callers {
+			t.Run(rt.name+"/"+c.name, func(t *testing.T) {
+				w := newWorld(t)
+				path := strings.ReplaceAll(rt.path, "%k", w.keyID.String())
+
+				checkResponse(t, w.serve(rt.method, path, rt.body, c.caller), c.want)
+			})
+		}
+	}
+}
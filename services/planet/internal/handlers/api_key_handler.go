@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyHandler(s service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: s}
+}
+
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays *int     `json:"expires_in_days"` // omitted for a key that never expires
+}
+
+type RotateAPIKeyRequest struct {
+	ExpiresInDays *int `json:"expires_in_days"`
+	GraceMinutes  *int `json:"grace_minutes"` // how long the old key keeps working
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListAPIKeys(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, keys)
+}
+
+// CreateAPIKey issues a key for a service. The key is in the response only.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req CreateAPIKeyRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	result, err := h.service.CreateAPIKey(r.Context(), req.Name, req.Scopes, days(req.ExpiresInDays))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, result)
+}
+
+// RotateAPIKey replaces a key with a new one. The body is optional.
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if err := request.DecodeOptionalJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	var grace *time.Duration
+	if req.GraceMinutes != nil {
+		d := time.Duration(*req.GraceMinutes) * time.Minute
+		grace = &d
+	}
+
+	result, err := h.service.RotateAPIKey(r.Context(), id, days(req.ExpiresInDays), grace)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, result)
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	key, err := h.service.RevokeAPIKey(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, key)
+}
+
+func parseAPIKeyID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid API key ID", err))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// days converts a number of days from a request into a duration.
+func days(n *int) *time.Duration {
+	if n == nil {
+		return nil
+	}
+	d := time.Duration(*n) * 24 * time.Hour
+	return &d
+}
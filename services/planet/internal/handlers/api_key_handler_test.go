@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+)
+
+func TestAPIKeyHandlerRoutes(t *testing.T) {
+	keyID := uuid.New()
+	key := "/api-keys/" + keyID.String()
+	h := func(s *fakeService) *APIKeyHandler { return NewAPIKeyHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list keys", Method: http.MethodGet, Pattern: "/api-keys", Target: "/api-keys",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetAPIKeys },
+			Status:  http.StatusOK,
+			Call:    call("ListAPIKeys"),
+			Data:    []service.APIKeyResponse{testKey},
+		},
+		{
+			Name: "create key", Method: http.MethodPost, Pattern: "/api-keys", Target: "/api-keys",
+			Body:    `{"name":"simulator","scopes":["battles:run"],"expires_in_days":30}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).CreateAPIKey },
+			Status:  http.StatusCreated,
+			Call:    call("CreateAPIKey", "simulator", []string{"battles:run"}, ptr(30*24*time.Hour)),
+			Data:    service.CreateAPIKeyResponse{APIKey: testKey, Key: "sk_new"},
+			Invalid: []handlertest.InvalidRequest{{Name: "wrong type", Body: `{"name":"simulator","scopes":"battles:run"}`}},
+		},
+		{
+			Name: "rotate key", Method: http.MethodPost, Pattern: "/api-keys/{keyID}/rotate", Target: key + "/rotate",
+			Body:         `{"expires_in_days":30,"grace_minutes":5}`,
+			Handler:      func(s *fakeService) http.HandlerFunc { return h(s).RotateAPIKey },
+			Status:       http.StatusCreated,
+			OptionalBody: true,
+			Call:         call("RotateAPIKey", keyID, ptr(30*24*time.Hour), ptr(5*time.Minute)),
+			Data:         service.CreateAPIKeyResponse{APIKey: testKey, Key: "sk_rotated"},
+			Invalid:      []handlertest.InvalidRequest{{Name: "bad key ID", Target: "/api-keys/nope/rotate"}},
+		},
+		{
+			Name: "revoke key", Method: http.MethodDelete, Pattern: "/api-keys/{keyID}", Target: key,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).RevokeAPIKey },
+			Status:  http.StatusOK,
+			Call:    call("RevokeAPIKey", keyID),
+			Data:    testKey,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad key ID", Target: "/api-keys/nope"}},
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type AuthHandler struct {
+	service service.AuthService
+}
+
+func NewAuthHandler(s service.AuthService) *AuthHandler {
+	return &AuthHandler{service: s}
+}
+
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (r *LoginRequest) Validate() error {
+	if strings.TrimSpace(r.Username) == "" || r.Password == "" {
+		return apperrors.NewInvalidInputError("username and password are required", nil)
+	}
+	return nil
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r *RefreshRequest) Validate() error {
+	if r.RefreshToken == "" {
+		return apperrors.NewInvalidInputError("refresh_token is required", nil)
+	}
+	return nil
+}
+
+// Login exchanges a username and password for an access and refresh token.
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req LoginRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	tokens, err := h.service.Login(r.Context(), req.Username, req.Password)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tokens)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The old refresh
+// token can't be used again.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	tokens, err := h.service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tokens)
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/shared/handlertest"
+)
+
+func TestAuthHandlerRoutes(t *testing.T) {
+	h := func(s *fakeService) *AuthHandler { return NewAuthHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "login", Method: http.MethodPost, Pattern: "/auth/login", Target: "/auth/login",
+			Body:    `{"username":"nova","password":"correct horse"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).Login },
+			Status:  http.StatusOK,
+			Call:    call("Login", "nova", "correct horse"),
+			Data:    testTokens,
+			Invalid: []handlertest.InvalidRequest{{Name: "no password", Body: `{"username":"nova"}`}},
+		},
+		{
+			Name: "refresh", Method: http.MethodPost, Pattern: "/auth/refresh", Target: "/auth/refresh",
+			Body:    `{"refresh_token":"token"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).Refresh },
+			Status:  http.StatusOK,
+			Call:    call("Refresh", "token"),
+			Data:    testTokens,
+			Invalid: []handlertest.InvalidRequest{{Name: "no token", Body: `{"refresh_token":""}`}},
+		},
+	})
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type BattleHandler struct {
+	service service.BattleService
+}
+
+func NewBattleHandler(s service.BattleService) *BattleHandler {
+	return &BattleHandler{service: s}
+}
+
+// FightBattleRequest may be omitted entirely to fight the planet's next
+// wave with a random seed, as long as the Idempotency-Key header is sent.
+type FightBattleRequest struct {
+	WaveID         *uuid.UUID `json:"wave_id,omitempty"`
+	Seed           *int64     `json:"seed,omitempty"`
+	IdempotencyKey string     `json:"idempotency_key,omitempty"`
+}
+
+func (r *FightBattleRequest) Validate() error {
+	if r.IdempotencyKey == "" {
+		return apperrors.NewInvalidInputError("Idempotency-Key header is required", nil)
+	}
+	return nil
+}
+
+// FightBattle fights a battle on the planet and stores it. The
+// Idempotency-Key header, or idempotency_key in the body, is required:
+// retries with the same key return the stored battle with 200 instead of
+// fighting again, so loot is credited once. Pass ?events=text to add the
+// text event log.
+func (h *BattleHandler) FightBattle(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req FightBattleRequest
+	if err := request.DecodeOptionalJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	battle, created, err := h.service.FightBattle(r.Context(), planetID, service.FightBattleRequest{
+		WaveID:         req.WaveID,
+		Seed:           req.Seed,
+		IdempotencyKey: req.IdempotencyKey,
+	})
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if wantsEventLog(r) {
+		battle.RenderEventLog()
+	}
+
+	if !created {
+		response.WriteSuccess(w, battle)
+		return
+	}
+	response.WriteCreated(w, battle)
+}
+
+// GetBattles lists the planet's battles, newest first. It accepts
+// ?outcome=, ?since= and ?until= (RFC 3339, on when the battle was fought),
+// ?limit= and the ?cursor= from the previous page.
+func (h *BattleHandler) GetBattles(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := service.BattleFilter{
+		Outcome: query.Get("outcome"),
+		Cursor:  query.Get("cursor"),
+	}
+	for name, dst := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				response.WriteError(w, apperrors.NewInvalidInputError(name+" must be an RFC 3339 timestamp", err))
+				return
+			}
+			*dst = &t
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.WriteError(w, apperrors.NewInvalidInputError("limit must be a positive integer", err))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	page, err := h.service.ListBattles(r.Context(), planetID, filter)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WritePage(w, page.Battles, response.Pagination{
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	})
+}
+
+// GetBattle returns a battle with its full event log. Pass ?events=text to
+// add the text rendering of the events.
+func (h *BattleHandler) GetBattle(w http.ResponseWriter, r *http.Request) {
+	battleID, ok := parseBattleID(w, r)
+	if !ok {
+		return
+	}
+
+	battle, err := h.service.GetBattle(r.Context(), battleID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if wantsEventLog(r) {
+		battle.RenderEventLog()
+	}
+
+	response.WriteSuccess(w, battle)
+}
+
+// ReplayBattle re-runs a stored battle and compares it with the original.
+func (h *BattleHandler) ReplayBattle(w http.ResponseWriter, r *http.Request) {
+	battleID, ok := parseBattleID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ReplayBattle(r.Context(), battleID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if wantsEventLog(r) {
+		result.Original.RenderEventLog()
+		result.Replay.RenderEventLog()
+	}
+
+	response.WriteSuccess(w, result)
+}
+
+// Streaming pace limits. At speed 1 a battle streams in real time, at
+// simulation.TicksPerSecond ticks per second.
+const (
+	maxStreamSpeed    = 20
+	streamKeepAlive   = 15 * time.Second
+	streamEventTick   = "tick"
+	streamEventResult = "result"
+)
+
+// BattleFrame is the data of a "tick" stream event: everything that happened
+// in one tick.
+type BattleFrame struct {
+	Tick   int                 `json:"tick"`
+	Events []types.BattleEvent `json:"events"`
+}
+
+// StreamBattle plays a stored battle as Server-Sent Events. Every tick with
+// events is sent as a "tick" event whose ID is the tick number, and the
+// full result follows as a final "result" event, after which clients should
+// close the stream. ?speed= (1 to 20) speeds the playback up. Playback
+// starts at ?from_tick=, or resumes after the tick in the Last-Event-ID
+// header when reconnecting.
+func (h *BattleHandler) StreamBattle(w http.ResponseWriter, r *http.Request) {
+	battleID, ok := parseBattleID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from := 0
+	if v := query.Get("from_tick"); v != "" {
+		tick, err := strconv.Atoi(v)
+		if err != nil || tick < 0 {
+			response.WriteError(w, apperrors.NewInvalidInputError("from_tick must be a non-negative integer", err))
+			return
+		}
+		from = tick
+	}
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		tick, err := strconv.Atoi(v)
+		if err != nil || tick < 0 {
+			response.WriteError(w, apperrors.NewInvalidInputError("invalid Last-Event-ID", err))
+			return
+		}
+		from = tick + 1
+	}
+	speed := 1
+	if v := query.Get("speed"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxStreamSpeed {
+			response.WriteError(w, apperrors.NewInvalidInputError(
+				fmt.Sprintf("speed must be an integer between 1 and %d", maxStreamSpeed), err))
+			return
+		}
+		speed = n
+	}
+
+	battle, err := h.service.StreamBattle(r.Context(), battleID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	// Fast-forward through the ticks the client has already seen.
+	for !battle.Done() && battle.Tick() < from {
+		battle.Step()
+	}
+
+	stream, err := response.NewEventStream(w)
+	if err != nil {
+		response.WriteError(w, apperrors.NewInternalError("streaming is not supported", err))
+		return
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(simulation.TicksPerSecond*speed))
+	defer ticker.Stop()
+	lastSent := time.Now()
+
+	for !battle.Done() {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+
+		tick := battle.Tick()
+		events := battle.Step()
+		if len(events) == 0 {
+			if time.Since(lastSent) >= streamKeepAlive {
+				if err := stream.Comment("keep-alive"); err != nil {
+					return
+				}
+				lastSent = time.Now()
+			}
+			continue
+		}
+
+		if err := stream.Send(strconv.Itoa(tick), streamEventTick, BattleFrame{Tick: tick, Events: events}); err != nil {
+			return
+		}
+		lastSent = time.Now()
+	}
+
+	result := battle.Result()
+	if wantsEventLog(r) {
+		result.RenderEventLog()
+	}
+	_ = stream.Send("", streamEventResult, result) // nothing left to do if the client is gone
+}
+
+func parseBattleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid battle ID", err))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// wantsEventLog reports whether the client asked for the text event log
+// alongside the structured events.
+func wantsEventLog(r *http.Request) bool {
+	return r.URL.Query().Get("events") == "text"
+}
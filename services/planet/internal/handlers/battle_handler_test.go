@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// withEventLog returns the battle as rendered with ?events=text.
+func withEventLog(b types.Battle) types.Battle {
+	b.RenderEventLog()
+	return b
+}
+
+func TestBattleHandlerRoutes(t *testing.T) {
+	battleID := uuid.New()
+	waveID := uuid.New()
+	battles := "/planets/" + testPlanetID.String() + "/battles"
+	battle := "/battles/" + battleID.String()
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	replayed := withEventLog(testBattle).SimulationResult
+	h := func(s *fakeService) *BattleHandler { return NewBattleHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "fight battle", Method: http.MethodPost, Pattern: "/planets/{id}/battles", Target: battles + "?events=text",
+			Body:         `{"wave_id":"` + waveID.String() + `","seed":1}`,
+			Header:       http.Header{"Idempotency-Key": {"retry-1"}},
+			Handler:      func(s *fakeService) http.HandlerFunc { return h(s).FightBattle },
+			Status:       http.StatusCreated,
+			OptionalBody: true,
+			Call: call("FightBattle", testPlanetID, service.FightBattleRequest{
+				WaveID: &waveID, Seed: ptr(int64(1)), IdempotencyKey: "retry-1",
+			}),
+			Data: withEventLog(testBattle),
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/battles"},
+				{Name: "bad wave ID", Body: `{"wave_id":"nope"}`},
+			},
+		},
+		{
+			Name: "list battles", Method: http.MethodGet, Pattern: "/planets/{id}/battles",
+			Target:  battles + "?outcome=victory&since=2026-01-01T00:00:00Z&limit=5&cursor=abc",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetBattles },
+			Status:  http.StatusOK,
+			Call: call("ListBattles", testPlanetID, service.BattleFilter{
+				Outcome: types.OutcomeVictory, Since: &since, Cursor: "abc", Limit: 5,
+			}),
+			Data: []types.Battle{testBattle},
+			Page: &testPage,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/battles"},
+				{Name: "bad since", Target: battles + "?since=yesterday"},
+				{Name: "bad limit", Target: battles + "?limit=-1"},
+			},
+		},
+		{
+			Name: "get battle", Method: http.MethodGet, Pattern: "/battles/{id}", Target: battle,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetBattle },
+			Status:  http.StatusOK,
+			Call:    call("GetBattle", battleID),
+			Data:    testBattle,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad battle ID", Target: "/battles/nope"}},
+		},
+		{
+			Name: "get battle with event log", Method: http.MethodGet, Pattern: "/battles/{id}", Target: battle + "?events=text",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetBattle },
+			Status:  http.StatusOK,
+			Call:    call("GetBattle", battleID),
+			Data:    withEventLog(testBattle),
+		},
+		{
+			Name: "replay battle", Method: http.MethodPost, Pattern: "/battles/{id}/replay", Target: battle + "/replay?events=text",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).ReplayBattle },
+			Status:  http.StatusOK,
+			Call:    call("ReplayBattle", battleID),
+			Data:    service.ReplayBattleResponse{Original: replayed, Replay: replayed, Matches: true},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad battle ID", Target: "/battles/nope/replay"}},
+		},
+	})
+}
+
+func TestFightBattleIdempotencyKey(t *testing.T) {
+	battles := "/planets/" + testPlanetID.String() + "/battles"
+	tests := []struct {
+		name   string
+		body   string
+		header http.Header
+		status int
+	}{
+		{"header", "", http.Header{"Idempotency-Key": {"retry-1"}}, http.StatusCreated},
+		{"body", `{"idempotency_key":"retry-1"}`, nil, http.StatusCreated},
+		{"header wins over body", `{"idempotency_key":"stale"}`, http.Header{"Idempotency-Key": {"retry-1"}}, http.StatusCreated},
+		{"missing", `{"seed":1}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newFakeService(nil)
+			rec := handlertest.ServeWithHeader("/planets/{id}/battles", NewBattleHandler(svc).FightBattle,
+				http.MethodPost, battles, tt.body, tt.header)
+			if tt.status == http.StatusBadRequest {
+				handlertest.ExpectError(t, rec, tt.status, "INVALID_INPUT", "Idempotency-Key header is required")
+				handlertest.ExpectCalls(t, svc)
+				return
+			}
+			handlertest.ExpectSuccess(t, rec, tt.status)
+			handlertest.ExpectCalls(t, svc, call("FightBattle", testPlanetID, service.FightBattleRequest{IdempotencyKey: "retry-1"}))
+		})
+	}
+}
+
+// noFlushWriter hides the recorder's Flush method.
+type noFlushWriter struct {
+	http.ResponseWriter
+}
+
+func TestStreamBattle(t *testing.T) {
+	battleID := uuid.New()
+	stream := "/battles/" + battleID.String() + "/stream"
+	newService := func(t *testing.T) *fakeService {
+		battle, err := simulation.NewBattle(simulation.Input{
+			Planet: types.Planet{ID: uuid.NewString(), HP: 100, MaxHP: 100},
+			Seed:   1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		svc := newFakeService(nil)
+		svc.battle = battle
+		return svc
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		svc := newService(t)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, stream+"?speed=20", nil)
+		handlertest.ServeRequest("/battles/{id}/stream", NewBattleHandler(svc).StreamBattle, rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body %s", rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+		}
+		if !strings.Contains(rec.Body.String(), "event: "+streamEventResult+"\n") {
+			t.Fatalf("body = %q, want a result event", rec.Body)
+		}
+		handlertest.ExpectCalls(t, svc, call("StreamBattle", battleID))
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		svc := newFakeService(apperrors.NewNotFoundError("battle", "battle with given ID does not exist"))
+		rec := handlertest.Serve("/battles/{id}/stream", NewBattleHandler(svc).StreamBattle, http.MethodGet, stream, "")
+		handlertest.ExpectError(t, rec, http.StatusNotFound, "NOT_FOUND", "not found")
+	})
+
+	t.Run("streaming unsupported", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, stream, nil)
+		handlertest.ServeRequest("/battles/{id}/stream", NewBattleHandler(newService(t)).StreamBattle, noFlushWriter{rec}, req)
+		handlertest.ExpectError(t, rec, http.StatusInternalServerError, "INTERNAL_ERROR", "")
+	})
+
+	invalid := map[string]func(*http.Request){
+		"bad battle ID":      func(r *http.Request) { r.URL.Path = "/battles/nope/stream" },
+		"bad from_tick":      func(r *http.Request) { r.URL.RawQuery = "from_tick=-1" },
+		"bad Last-Event-ID":  func(r *http.Request) { r.Header.Set("Last-Event-ID", "latest") },
+		"speed out of range": func(r *http.Request) { r.URL.RawQuery = "speed=21" },
+	}
+	for name, modify := range invalid {
+		t.Run(name, func(t *testing.T) {
+			svc := newService(t)
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, stream, nil)
+			modify(req)
+			handlertest.ServeRequest("/battles/{id}/stream", NewBattleHandler(svc).StreamBattle, rec, req)
+			handlertest.ExpectError(t, rec, http.StatusBadRequest, "INVALID_INPUT", "")
+			handlertest.ExpectCalls(t, svc)
+		})
+	}
+}
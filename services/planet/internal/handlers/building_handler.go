@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type BuildingHandler struct {
+	service service.BuildingService
+}
+
+func NewBuildingHandler(s service.BuildingService) *BuildingHandler {
+	return &BuildingHandler{service: s}
+}
+
+type QueueBuildingRequest struct {
+	Kind string `json:"kind"`
+}
+
+func (r *QueueBuildingRequest) Validate() error {
+	if r.Kind == "" {
+		return apperrors.NewInvalidInputError("kind is required", nil)
+	}
+	return nil
+}
+
+func (h *BuildingHandler) GetBuildings(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	buildings, err := h.service.ListBuildings(r.Context(), planetID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, buildings)
+}
+
+// QueueBuilding starts construction of a building's next level.
+func (h *BuildingHandler) QueueBuilding(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req QueueBuildingRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	result, err := h.service.QueueBuilding(r.Context(), planetID, req.Kind)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, result)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestBuildingHandlerRoutes(t *testing.T) {
+	buildings := "/planets/" + testPlanetID.String() + "/buildings"
+	h := func(s *fakeService) *BuildingHandler { return NewBuildingHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list buildings", Method: http.MethodGet, Pattern: "/planets/{id}/buildings", Target: buildings,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetBuildings },
+			Status:  http.StatusOK,
+			Call:    call("ListBuildings", testPlanetID),
+			Data:    []types.Building{testBuilding},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/buildings"}},
+		},
+		{
+			Name: "queue building", Method: http.MethodPost, Pattern: "/planets/{id}/buildings", Target: buildings, Body: `{"kind":"mineral_mine"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).QueueBuilding },
+			Status:  http.StatusCreated,
+			Call:    call("QueueBuilding", testPlanetID, "mineral_mine"),
+			Data:    service.QueueBuildingResponse{Building: testBuilding, Resources: testLeft},
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/buildings"},
+				{Name: "no kind", Body: `{"kind":""}`},
+			},
+		},
+	})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type DefenseHandler struct {
+	service service.DefenseService
+}
+
+func NewDefenseHandler(s service.DefenseService) *DefenseHandler {
+	return &DefenseHandler{service: s}
+}
+
+type AddDefenseRequest struct {
+	Kind     string `json:"kind"`
+	Position *int   `json:"position,omitempty"` // first free slot when omitted
+}
+
+func (r *AddDefenseRequest) Validate() error {
+	if r.Kind == "" {
+		return apperrors.NewInvalidInputError("kind is required", nil)
+	}
+	return nil
+}
+
+type MoveDefenseRequest struct {
+	Position *int `json:"position"`
+}
+
+func (r *MoveDefenseRequest) Validate() error {
+	if r.Position == nil {
+		return apperrors.NewInvalidInputError("position is required", nil)
+	}
+	return nil
+}
+
+func (h *DefenseHandler) GetDefenses(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	defenses, err := h.service.ListDefenses(r.Context(), planetID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, defenses)
+}
+
+func (h *DefenseHandler) AddDefense(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddDefenseRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	defense, err := h.service.AddDefense(r.Context(), planetID, req.Kind, req.Position)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, defense)
+}
+
+func (h *DefenseHandler) RemoveDefense(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+	defenseID, ok := parseDefenseID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveDefense(r.Context(), planetID, defenseID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *DefenseHandler) MoveDefense(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+	defenseID, ok := parseDefenseID(w, r)
+	if !ok {
+		return
+	}
+
+	var req MoveDefenseRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	defenses, err := h.service.MoveDefense(r.Context(), planetID, defenseID, *req.Position)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, defenses)
+}
+
+func (h *DefenseHandler) UpgradeDefense(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+	defenseID, ok := parseDefenseID(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.UpgradeDefense(r.Context(), planetID, defenseID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, result)
+}
+
+func parseDefenseID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "defenseID"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid defense ID", err))
+		return uuid.Nil, false
+	}
+	return id, true
+}
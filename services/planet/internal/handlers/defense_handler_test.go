@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestDefenseHandlerRoutes(t *testing.T) {
+	defenseID := uuid.New()
+	defenses := "/planets/" + testPlanetID.String() + "/defenses"
+	defense := defenses + "/" + defenseID.String()
+	h := func(s *fakeService) *DefenseHandler { return NewDefenseHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list defenses", Method: http.MethodGet, Pattern: "/planets/{id}/defenses", Target: defenses,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetDefenses },
+			Status:  http.StatusOK,
+			Call:    call("ListDefenses", testPlanetID),
+			Data:    []types.DefenseSystem{testDefense},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/defenses"}},
+		},
+		{
+			Name: "add defense", Method: http.MethodPost, Pattern: "/planets/{id}/defenses", Target: defenses, Body: `{"kind":"autocannon","position":2}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).AddDefense },
+			Status:  http.StatusCreated,
+			Call:    call("AddDefense", testPlanetID, "autocannon", ptr(2)),
+			Data:    testDefense,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/defenses"},
+				{Name: "no kind", Body: `{"position":2}`},
+			},
+		},
+		{
+			Name: "add defense in the first free slot", Method: http.MethodPost, Pattern: "/planets/{id}/defenses", Target: defenses, Body: `{"kind":"autocannon"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).AddDefense },
+			Status:  http.StatusCreated,
+			Call:    call("AddDefense", testPlanetID, "autocannon", (*int)(nil)),
+			Data:    testDefense,
+		},
+		{
+			Name: "remove defense", Method: http.MethodDelete, Pattern: "/planets/{id}/defenses/{defenseID}", Target: defense,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).RemoveDefense },
+			Status:  http.StatusOK,
+			Call:    call("RemoveDefense", testPlanetID, defenseID),
+			Invalid: []handlertest.InvalidRequest{{Name: "bad defense ID", Target: defenses + "/nope"}},
+		},
+		{
+			Name: "move defense", Method: http.MethodPut, Pattern: "/planets/{id}/defenses/{defenseID}/position", Target: defense + "/position", Body: `{"position":3}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).MoveDefense },
+			Status:  http.StatusOK,
+			Call:    call("MoveDefense", testPlanetID, defenseID, 3),
+			Data:    []types.DefenseSystem{testDefense},
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad defense ID", Target: defenses + "/nope/position"},
+				{Name: "no position", Body: `{}`},
+			},
+		},
+		{
+			Name: "upgrade defense", Method: http.MethodPost, Pattern: "/planets/{id}/defenses/{defenseID}/upgrade", Target: defense + "/upgrade",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).UpgradeDefense },
+			Status:  http.StatusOK,
+			Call:    call("UpgradeDefense", testPlanetID, defenseID),
+			Data:    service.UpgradeDefenseResponse{Defense: testDefense, Resources: testLeft},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad defense ID", Target: defenses + "/nope/upgrade"}},
+		},
+	})
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// fakeService implements every service the handlers call. Each method
+// records its arguments and returns the matching test value below, along
+// with the recorder's error.
+type fakeService struct {
+	handlertest.Recorder
+	battle *simulation.Battle // returned by StreamBattle
+}
+
+func newFakeService(err error) *fakeService {
+	return &fakeService{Recorder: handlertest.Recorder{Err: err}}
+}
+
+// Values the fake returns, distinct enough that a handler rendering the
+// wrong one fails the test.
+var (
+	testNow      = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	testPlanetID = uuid.MustParse("8d4bd1d4-3c4b-4a3e-9d3a-6f1e2b0c4a11")
+	testPlayerID = uuid.MustParse("2f0c8a55-7b61-4d2e-8f9a-0c3d5e7a9b22")
+
+	testPage   = response.Pagination{Limit: 5, NextCursor: "next"}
+	testPlanet = types.Planet{
+		ID: testPlanetID.String(), PlayerID: testPlayerID.String(), Name: "Home",
+		HP: 80, MaxHP: 100, Status: types.PlanetActive, Resources: types.Resources{Minerals: 120},
+	}
+	testBattle = types.Battle{
+		ID: uuid.NewString(), PlanetID: testPlanetID.String(), WaveID: uuid.NewString(),
+		SimulationResult: types.SimulationResult{
+			Outcome: types.OutcomeVictory, DamageTaken: 12, HPRemaining: 88, AliensDestroyed: 1, Seed: 7,
+			Events: []types.BattleEvent{{Tick: 3, Kind: types.EventAlienDestroyed, Target: "drone-1"}},
+		},
+	}
+	testBuilding = types.Building{ID: uuid.NewString(), PlanetID: testPlanetID.String(), Kind: "mineral_mine", Level: 2}
+	testDefense  = types.DefenseSystem{ID: uuid.NewString(), PlanetID: testPlanetID.String(), Kind: "autocannon", Damage: 5, Level: 1, Position: 2}
+	testResearch = types.Research{ID: uuid.NewString(), PlanetID: testPlanetID.String(), Tech: "salvage", Level: 1}
+	testLedger   = types.LedgerEntry{ID: uuid.NewString(), PlanetID: testPlanetID.String(), Source: "battle", Amount: types.Resources{Energy: 4}}
+	testPlayer   = service.PlayerResponse{ID: testPlayerID, Username: "nova", CreatedAt: testNow}
+	testKey      = service.APIKeyResponse{ID: uuid.New(), Name: "simulator", Scopes: []string{"battles:run"}, CreatedAt: testNow}
+	testTokens   = service.TokenResponse{PlayerID: testPlayerID, Role: "player", AccessToken: "access", TokenType: "Bearer", RefreshToken: "refresh", ExpiresAt: testNow}
+	testLeft     = types.Resources{Minerals: 20, Energy: 5}
+)
+
+func (f *fakeService) CreateAPIKey(_ context.Context, name string, scopes []string, expiresIn *time.Duration) (service.CreateAPIKeyResponse, error) {
+	return service.CreateAPIKeyResponse{APIKey: testKey, Key: "sk_new"}, f.Record("CreateAPIKey", name, scopes, expiresIn)
+}
+
+func (f *fakeService) ListAPIKeys(context.Context) ([]service.APIKeyResponse, error) {
+	return []service.APIKeyResponse{testKey}, f.Record("ListAPIKeys")
+}
+
+func (f *fakeService) RotateAPIKey(_ context.Context, id uuid.UUID, expiresIn, grace *time.Duration) (service.CreateAPIKeyResponse, error) {
+	return service.CreateAPIKeyResponse{APIKey: testKey, Key: "sk_rotated"}, f.Record("RotateAPIKey", id, expiresIn, grace)
+}
+
+func (f *fakeService) RevokeAPIKey(_ context.Context, id uuid.UUID) (service.APIKeyResponse, error) {
+	return testKey, f.Record("RevokeAPIKey", id)
+}
+
+func (f *fakeService) Login(_ context.Context, username, password string) (service.TokenResponse, error) {
+	return testTokens, f.Record("Login", username, password)
+}
+
+func (f *fakeService) Refresh(_ context.Context, token string) (service.TokenResponse, error) {
+	return testTokens, f.Record("Refresh", token)
+}
+
+func (f *fakeService) FightBattle(_ context.Context, planetID uuid.UUID, req service.FightBattleRequest) (types.Battle, bool, error) {
+	return testBattle, true, f.Record("FightBattle", planetID, req)
+}
+
+func (f *fakeService) GetBattle(_ context.Context, id uuid.UUID) (types.Battle, error) {
+	return testBattle, f.Record("GetBattle", id)
+}
+
+func (f *fakeService) ListBattles(_ context.Context, planetID uuid.UUID, filter service.BattleFilter) (service.BattlePage, error) {
+	return service.BattlePage{Battles: []types.Battle{testBattle}, Limit: testPage.Limit, NextCursor: testPage.NextCursor},
+		f.Record("ListBattles", planetID, filter)
+}
+
+func (f *fakeService) ReplayBattle(_ context.Context, id uuid.UUID) (service.ReplayBattleResponse, error) {
+	return service.ReplayBattleResponse{Original: testBattle.SimulationResult, Replay: testBattle.SimulationResult, Matches: true},
+		f.Record("ReplayBattle", id)
+}
+
+func (f *fakeService) StreamBattle(_ context.Context, id uuid.UUID) (*simulation.Battle, error) {
+	return f.battle, f.Record("StreamBattle", id)
+}
+
+func (f *fakeService) ListBuildings(_ context.Context, planetID uuid.UUID) ([]types.Building, error) {
+	return []types.Building{testBuilding}, f.Record("ListBuildings", planetID)
+}
+
+func (f *fakeService) QueueBuilding(_ context.Context, planetID uuid.UUID, kind string) (service.QueueBuildingResponse, error) {
+	return service.QueueBuildingResponse{Building: testBuilding, Resources: testLeft}, f.Record("QueueBuilding", planetID, kind)
+}
+
+func (f *fakeService) AddDefense(_ context.Context, planetID uuid.UUID, kind string, position *int) (types.DefenseSystem, error) {
+	return testDefense, f.Record("AddDefense", planetID, kind, position)
+}
+
+func (f *fakeService) ListDefenses(_ context.Context, planetID uuid.UUID) ([]types.DefenseSystem, error) {
+	return []types.DefenseSystem{testDefense}, f.Record("ListDefenses", planetID)
+}
+
+func (f *fakeService) RemoveDefense(_ context.Context, planetID, defenseID uuid.UUID) error {
+	return f.Record("RemoveDefense", planetID, defenseID)
+}
+
+func (f *fakeService) MoveDefense(_ context.Context, planetID, defenseID uuid.UUID, position int) ([]types.DefenseSystem, error) {
+	return []types.DefenseSystem{testDefense}, f.Record("MoveDefense", planetID, defenseID, position)
+}
+
+func (f *fakeService) UpgradeDefense(_ context.Context, planetID, defenseID uuid.UUID) (service.UpgradeDefenseResponse, error) {
+	return service.UpgradeDefenseResponse{Defense: testDefense, Resources: testLeft}, f.Record("UpgradeDefense", planetID, defenseID)
+}
+
+func (f *fakeService) ListLedger(_ context.Context, planetID uuid.UUID, filter service.LedgerFilter) (service.LedgerPage, error) {
+	return service.LedgerPage{Entries: []types.LedgerEntry{testLedger}, Limit: testPage.Limit, NextCursor: testPage.NextCursor},
+		f.Record("ListLedger", planetID, filter)
+}
+
+func (f *fakeService) GetPlanet(_ context.Context, id uuid.UUID, withDefenses bool) (types.Planet, error) {
+	return testPlanet, f.Record("GetPlanet", id, withDefenses)
+}
+
+func (f *fakeService) GetPlayerPlanet(_ context.Context, playerID uuid.UUID, withDefenses bool) (types.Planet, error) {
+	return testPlanet, f.Record("GetPlayerPlanet", playerID, withDefenses)
+}
+
+func (f *fakeService) UpdatePlanet(_ context.Context, id uuid.UUID, update service.PlanetUpdate) (types.Planet, error) {
+	return testPlanet, f.Record("UpdatePlanet", id, update)
+}
+
+func (f *fakeService) DeletePlanet(_ context.Context, id uuid.UUID) error {
+	return f.Record("DeletePlanet", id)
+}
+
+func (f *fakeService) RepairPlanet(_ context.Context, id uuid.UUID, hp *int) (types.Planet, error) {
+	return testPlanet, f.Record("RepairPlanet", id, hp)
+}
+
+func (f *fakeService) RebuildPlanet(_ context.Context, id uuid.UUID) (types.Planet, error) {
+	return testPlanet, f.Record("RebuildPlanet", id)
+}
+
+func (f *fakeService) ListPlayers(_ context.Context, filter service.PlayerFilter) (service.PlayerPage, error) {
+	return service.PlayerPage{Players: []service.PlayerResponse{testPlayer}, Limit: testPage.Limit, NextCursor: testPage.NextCursor},
+		f.Record("ListPlayers", filter)
+}
+
+func (f *fakeService) GetPlayerByID(_ context.Context, id uuid.UUID) (service.PlayerResponse, error) {
+	return testPlayer, f.Record("GetPlayerByID", id)
+}
+
+func (f *fakeService) CreatePlayerWithPlanet(_ context.Context, username, password, planetName string) (service.CreatePlayerResponse, error) {
+	return service.CreatePlayerResponse{Player: testPlayer, Planet: testPlanet}, f.Record("CreatePlayerWithPlanet", username, password, planetName)
+}
+
+func (f *fakeService) ListResearch(_ context.Context, planetID uuid.UUID) ([]types.Research, error) {
+	return []types.Research{testResearch}, f.Record("ListResearch", planetID)
+}
+
+func (f *fakeService) QueueResearch(_ context.Context, planetID uuid.UUID, tech string) (service.QueueResearchResponse, error) {
+	return service.QueueResearchResponse{Research: testResearch, Resources: testLeft}, f.Record("QueueResearch", planetID, tech)
+}
+
+// call builds the service call a route is expected to make.
+func call(method string, args ...any) handlertest.Call {
+	return handlertest.Call{Method: method, Args: args}
+}
+
+// ptr returns a pointer to v, for expected optional arguments.
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type LedgerHandler struct {
+	service service.LedgerService
+}
+
+func NewLedgerHandler(s service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{service: s}
+}
+
+// GetLedger lists the resources credited to the planet, newest first. It
+// accepts ?limit= and the ?cursor= from the previous page.
+func (h *LedgerHandler) GetLedger(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := service.LedgerFilter{Cursor: query.Get("cursor")}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.WriteError(w, apperrors.NewInvalidInputError("limit must be a positive integer", err))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	page, err := h.service.ListLedger(r.Context(), planetID, filter)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WritePage(w, page.Entries, response.Pagination{
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	})
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestLedgerHandlerRoutes(t *testing.T) {
+	ledger := "/planets/" + testPlanetID.String() + "/ledger"
+	h := func(s *fakeService) *LedgerHandler { return NewLedgerHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list ledger", Method: http.MethodGet, Pattern: "/planets/{id}/ledger", Target: ledger + "?limit=5&cursor=abc",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetLedger },
+			Status:  http.StatusOK,
+			Call:    call("ListLedger", testPlanetID, service.LedgerFilter{Cursor: "abc", Limit: 5}),
+			Data:    []types.LedgerEntry{testLedger},
+			Page:    &testPage,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/ledger"},
+				{Name: "bad limit", Target: ledger + "?limit=0"},
+			},
+		},
+	})
+}
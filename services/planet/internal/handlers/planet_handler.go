@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type PlanetHandler struct {
+	service service.PlanetService
+}
+
+func NewPlanetHandler(s service.PlanetService) *PlanetHandler {
+	return &PlanetHandler{service: s}
+}
+
+// UpdatePlanetRequest is a partial update; omitted fields keep their value.
+type UpdatePlanetRequest struct {
+	Name        *string          `json:"name,omitempty"`
+	HP          *int             `json:"hp,omitempty"`
+	Shields     *int             `json:"shields,omitempty"`
+	CurrentWave *int             `json:"current_wave,omitempty"`
+	Resources   *types.Resources `json:"resources,omitempty"`
+}
+
+func (r *UpdatePlanetRequest) Validate() error {
+	if r.Name == nil && r.HP == nil && r.Shields == nil && r.CurrentWave == nil && r.Resources == nil {
+		return apperrors.NewInvalidInputError("at least one field must be set", nil)
+	}
+	return nil
+}
+
+// GetPlanetByID returns a planet. Pass ?include=defenses to embed its
+// defense systems.
+func (h *PlanetHandler) GetPlanetByID(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	planet, err := h.service.GetPlanet(r.Context(), planetID, includesDefenses(r))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, planet)
+}
+
+// GetPlayerPlanet returns the planet owned by a player. Pass
+// ?include=defenses to embed its defense systems.
+func (h *PlanetHandler) GetPlayerPlanet(w http.ResponseWriter, r *http.Request) {
+	playerID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid player ID", err))
+		return
+	}
+
+	planet, err := h.service.GetPlayerPlanet(r.Context(), playerID, includesDefenses(r))
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, planet)
+}
+
+func (h *PlanetHandler) UpdatePlanet(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdatePlanetRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	planet, err := h.service.UpdatePlanet(r.Context(), planetID, service.PlanetUpdate{
+		Name:        req.Name,
+		HP:          req.HP,
+		Shields:     req.Shields,
+		CurrentWave: req.CurrentWave,
+		Resources:   req.Resources,
+	})
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, planet)
+}
+
+func (h *PlanetHandler) DeletePlanet(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePlanet(r.Context(), planetID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+// RepairPlanetRequest may be omitted to repair all missing HP.
+type RepairPlanetRequest struct {
+	HP *int `json:"hp,omitempty"`
+}
+
+// RepairPlanet spends resources to restore the planet's HP.
+func (h *PlanetHandler) RepairPlanet(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RepairPlanetRequest
+	if err := request.DecodeOptionalJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	planet, err := h.service.RepairPlanet(r.Context(), planetID, req.HP)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, planet)
+}
+
+// RebuildPlanet brings a destroyed planet back.
+func (h *PlanetHandler) RebuildPlanet(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	planet, err := h.service.RebuildPlanet(r.Context(), planetID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, planet)
+}
+
+func includesDefenses(r *http.Request) bool {
+	return slices.Contains(strings.Split(r.URL.Query().Get("include"), ","), "defenses")
+}
+
+func parsePlanetID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid planet ID", err))
+		return uuid.Nil, false
+	}
+	return id, true
+}
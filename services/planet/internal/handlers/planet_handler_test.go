@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestPlanetHandlerRoutes(t *testing.T) {
+	planet := "/planets/" + testPlanetID.String()
+	h := func(s *fakeService) *PlanetHandler { return NewPlanetHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "get planet", Method: http.MethodGet, Pattern: "/planets/{id}", Target: planet + "?include=defenses",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetPlanetByID },
+			Status:  http.StatusOK,
+			Call:    call("GetPlanet", testPlanetID, true),
+			Data:    testPlanet,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope"}},
+		},
+		{
+			Name: "get planet without defenses", Method: http.MethodGet, Pattern: "/planets/{id}", Target: planet,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetPlanetByID },
+			Status:  http.StatusOK,
+			Call:    call("GetPlanet", testPlanetID, false),
+			Data:    testPlanet,
+		},
+		{
+			Name: "get player planet", Method: http.MethodGet, Pattern: "/players/{id}/planet", Target: "/players/" + testPlayerID.String() + "/planet",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetPlayerPlanet },
+			Status:  http.StatusOK,
+			Call:    call("GetPlayerPlanet", testPlayerID, false),
+			Data:    testPlanet,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad player ID", Target: "/players/nope/planet"}},
+		},
+		{
+			Name: "update planet", Method: http.MethodPatch, Pattern: "/planets/{id}", Target: planet,
+			Body:    `{"name":"Renamed","shields":5,"resources":{"minerals":3}}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).UpdatePlanet },
+			Status:  http.StatusOK,
+			Call: call("UpdatePlanet", testPlanetID, service.PlanetUpdate{
+				Name: ptr("Renamed"), Shields: ptr(5), Resources: &types.Resources{Minerals: 3},
+			}),
+			Data: testPlanet,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope"},
+				{Name: "no fields", Body: `{}`},
+				{Name: "wrong type", Body: `{"hp":"full"}`},
+			},
+		},
+		{
+			Name: "delete planet", Method: http.MethodDelete, Pattern: "/planets/{id}", Target: planet,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).DeletePlanet },
+			Status:  http.StatusOK,
+			Call:    call("DeletePlanet", testPlanetID),
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope"}},
+		},
+		{
+			Name: "repair planet", Method: http.MethodPost, Pattern: "/planets/{id}/repair", Target: planet + "/repair", Body: `{"hp":10}`,
+			Handler:      func(s *fakeService) http.HandlerFunc { return h(s).RepairPlanet },
+			Status:       http.StatusOK,
+			OptionalBody: true,
+			Call:         call("RepairPlanet", testPlanetID, ptr(10)),
+			Data:         testPlanet,
+			Invalid:      []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/repair"}},
+		},
+		{
+			Name: "rebuild planet", Method: http.MethodPost, Pattern: "/planets/{id}/rebuild", Target: planet + "/rebuild",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).RebuildPlanet },
+			Status:  http.StatusOK,
+			Call:    call("RebuildPlanet", testPlanetID),
+			Data:    testPlanet,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/rebuild"}},
+		},
+	})
+}
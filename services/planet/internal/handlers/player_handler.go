@@ -1,16 +1,16 @@
 package handlers
 
 import (
-	"database/sql"
-	"encoding/json"
-	"log"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/novaru/scallopticon/services/planet/internal/service"
 	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
 	"github.com/novaru/scallopticon/shared/response"
 )
 
@@ -24,6 +24,7 @@ func NewPlayerHandler(s service.PlayerService) *PlayerHandler {
 
 type CreatePlayerRequest struct {
 	Username   string `json:"username"`
+	Password   string `json:"password"`
 	PlanetName string `json:"planet_name"`
 }
 
@@ -34,53 +35,67 @@ func (r *CreatePlayerRequest) Validate() error {
 	if len(r.Username) < 3 {
 		return apperrors.NewInvalidInputError("username must be at least 3 characters", nil)
 	}
+	if len(r.Password) < service.MinPasswordLength || len(r.Password) > service.MaxPasswordLength {
+		return apperrors.NewInvalidInputError(
+			fmt.Sprintf("password must be %d to %d characters", service.MinPasswordLength, service.MaxPasswordLength), nil)
+	}
 	if strings.TrimSpace(r.PlanetName) == "" {
 		return apperrors.NewInvalidInputError("planet name is required", nil)
 	}
 	return nil
 }
 
+// GetPlayers lists players, newest first. It accepts ?username_prefix=,
+// ?sort=newest|oldest, ?limit= and the ?cursor= from the previous page.
 func (h *PlayerHandler) GetPlayers(w http.ResponseWriter, r *http.Request) {
-	players, err := h.service.GetAllPlayers(r.Context())
+	query := r.URL.Query()
+	filter := service.PlayerFilter{
+		UsernamePrefix: query.Get("username_prefix"),
+		Sort:           query.Get("sort"),
+		Cursor:         query.Get("cursor"),
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.WriteError(w, apperrors.NewInvalidInputError("limit must be a positive integer", err))
+			return
+		}
+		filter.Limit = limit
+	}
+
+	page, err := h.service.ListPlayers(r.Context(), filter)
 	if err != nil {
 		response.WriteError(w, err)
 		return
 	}
 
-	response.WriteSuccess(w, players)
+	response.WritePage(w, page.Players, response.Pagination{
+		Limit:      page.Limit,
+		NextCursor: page.NextCursor,
+	})
 }
 
 func (h *PlayerHandler) GetPlayerByID(w http.ResponseWriter, r *http.Request) {
-	idParam := chi.URLParam(r, "id")
-
-	playerID, err := uuid.Parse(idParam)
+	playerID, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		http.Error(w, "invalid UUID", http.StatusBadRequest)
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid player ID", err))
 		return
 	}
 
 	player, err := h.service.GetPlayerByID(r.Context(), playerID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			http.Error(w, "Player not found", http.StatusNotFound)
-			return
-		}
-		log.Println("Error fetching player:", err)
-		http.Error(w, "Failed to fetch player", http.StatusInternalServerError)
+		response.WriteError(w, err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(player)
+	response.WriteSuccess(w, player)
 }
 
 func (h *PlayerHandler) CreatePlayer(w http.ResponseWriter, r *http.Request) {
 	var req CreatePlayerRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		response.WriteError(w, apperrors.NewInvalidInputError("invalid JSON format", err))
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
 		return
 	}
 
@@ -89,7 +104,7 @@ func (h *PlayerHandler) CreatePlayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.service.CreatePlayerWithPlanet(r.Context(), req.Username, req.PlanetName)
+	result, err := h.service.CreatePlayerWithPlanet(r.Context(), req.Username, req.Password, req.PlanetName)
 	if err != nil {
 		response.WriteError(w, err)
 		return
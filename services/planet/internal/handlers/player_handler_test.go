@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+)
+
+func TestPlayerHandlerRoutes(t *testing.T) {
+	h := func(s *fakeService) *PlayerHandler { return NewPlayerHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list players", Method: http.MethodGet, Pattern: "/players", Target: "/players?username_prefix=ad&sort=oldest&limit=5&cursor=abc",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetPlayers },
+			Status:  http.StatusOK,
+			Call:    call("ListPlayers", service.PlayerFilter{UsernamePrefix: "ad", Sort: "oldest", Cursor: "abc", Limit: 5}),
+			Data:    []service.PlayerResponse{testPlayer},
+			Page:    &testPage,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad limit", Target: "/players?limit=many"}},
+		},
+		{
+			Name: "get player", Method: http.MethodGet, Pattern: "/players/{id}", Target: "/players/" + testPlayerID.String(),
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetPlayerByID },
+			Status:  http.StatusOK,
+			Call:    call("GetPlayerByID", testPlayerID),
+			Data:    testPlayer,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad player ID", Target: "/players/nope"}},
+		},
+		{
+			Name: "create player", Method: http.MethodPost, Pattern: "/players", Target: "/players",
+			Body:    `{"username":"nova","password":"correct horse","planet_name":"Home"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).CreatePlayer },
+			Status:  http.StatusCreated,
+			Call:    call("CreatePlayerWithPlanet", "nova", "correct horse", "Home"),
+			Data:    service.CreatePlayerResponse{Player: testPlayer, Planet: testPlanet},
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "short username", Body: `{"username":"no","password":"correct horse","planet_name":"Home"}`},
+				{Name: "short password", Body: `{"username":"nova","password":"short","planet_name":"Home"}`},
+				{Name: "no planet name", Body: `{"username":"nova","password":"correct horse","planet_name":" "}`},
+			},
+		},
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+type ResearchHandler struct {
+	service service.ResearchService
+}
+
+func NewResearchHandler(s service.ResearchService) *ResearchHandler {
+	return &ResearchHandler{service: s}
+}
+
+type QueueResearchRequest struct {
+	Tech string `json:"tech"`
+}
+
+func (r *QueueResearchRequest) Validate() error {
+	if r.Tech == "" {
+		return apperrors.NewInvalidInputError("tech is required", nil)
+	}
+	return nil
+}
+
+func (h *ResearchHandler) GetResearch(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	research, err := h.service.ListResearch(r.Context(), planetID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, research)
+}
+
+// QueueResearch starts researching a tech's next level.
+func (h *ResearchHandler) QueueResearch(w http.ResponseWriter, r *http.Request) {
+	planetID, ok := parsePlanetID(w, r)
+	if !ok {
+		return
+	}
+
+	var req QueueResearchRequest
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	result, err := h.service.QueueResearch(r.Context(), planetID, req.Tech)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, result)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/planet/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestResearchHandlerRoutes(t *testing.T) {
+	research := "/planets/" + testPlanetID.String() + "/research"
+	h := func(s *fakeService) *ResearchHandler { return NewResearchHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list research", Method: http.MethodGet, Pattern: "/planets/{id}/research", Target: research,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetResearch },
+			Status:  http.StatusOK,
+			Call:    call("ListResearch", testPlanetID),
+			Data:    []types.Research{testResearch},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/research"}},
+		},
+		{
+			Name: "queue research", Method: http.MethodPost, Pattern: "/planets/{id}/research", Target: research, Body: `{"tech":"salvage"}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).QueueResearch },
+			Status:  http.StatusCreated,
+			Call:    call("QueueResearch", testPlanetID, "salvage"),
+			Data:    service.QueueResearchResponse{Research: testResearch, Resources: testLeft},
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "bad planet ID", Target: "/planets/nope/research"},
+				{Name: "no tech", Body: `{"tech":""}`},
+			},
+		},
+	})
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, params generated.CreateAPIKeyParams) (generated.ApiKey, error)
+	List(ctx context.Context) ([]generated.ApiKey, error)
+	Rotate(ctx context.Context, id uuid.UUID, keyHash string, expiresAt *time.Time, oldExpiresAt time.Time) (generated.ApiKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) (generated.ApiKey, error)
+}
+
+type apiKeyRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewAPIKeyRepository(q *generated.Queries, db DB, logger *zap.Logger) APIKeyRepository {
+	return &apiKeyRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, params generated.CreateAPIKeyParams) (generated.ApiKey, error) {
+	key, err := r.q.CreateAPIKey(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to create API key", zap.String("name", params.Name), zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to create API key", err)
+	}
+
+	r.logger.Info("created API key",
+		zap.String("api_key_id", key.ID.String()),
+		zap.String("name", key.Name),
+		zap.Strings("scopes", key.Scopes))
+	return key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]generated.ApiKey, error) {
+	keys, err := r.q.ListAPIKeys(ctx)
+	if err != nil {
+		r.logger.Error("failed to list API keys", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve API keys", err)
+	}
+
+	return keys, nil
+}
+
+// Rotate replaces the key with a new one hashed as keyHash, with the same
+// name and scopes, expiring at expiresAt or never. The old key keeps
+// working until oldExpiresAt, so callers can switch over, but no later than
+// it would have anyway. Both happen in one transaction.
+func (r *apiKeyRepository) Rotate(ctx context.Context, id uuid.UUID, keyHash string, expiresAt *time.Time, oldExpiresAt time.Time) (generated.ApiKey, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	old, err := qtx.ExpireAPIKey(ctx, generated.ExpireAPIKeyParams{
+		ExpiresAt: pgtype.Timestamptz{Time: oldExpiresAt, Valid: true},
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.ApiKey{}, apperrors.NewNotFoundError("API key", "no active API key with given ID")
+		}
+		r.logger.Error("failed to expire API key", zap.String("api_key_id", id.String()), zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to rotate API key", err)
+	}
+
+	params := generated.CreateAPIKeyParams{
+		Name:        old.Name,
+		KeyHash:     keyHash,
+		Scopes:      old.Scopes,
+		RotatedFrom: pgtype.UUID{Bytes: old.ID, Valid: true},
+	}
+	if expiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *expiresAt, Valid: true}
+	}
+	key, err := qtx.CreateAPIKey(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to create API key", zap.String("name", old.Name), zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to rotate API key", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to rotate API key", err)
+	}
+
+	r.logger.Info("rotated API key",
+		zap.String("old_api_key_id", old.ID.String()),
+		zap.String("api_key_id", key.ID.String()))
+	return key, nil
+}
+
+func (r *apiKeyRepository) Revoke(ctx context.Context, id uuid.UUID) (generated.ApiKey, error) {
+	key, err := r.q.RevokeAPIKey(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.ApiKey{}, apperrors.NewNotFoundError("API key", "no active API key with given ID")
+		}
+		r.logger.Error("failed to revoke API key", zap.String("api_key_id", id.String()), zap.Error(err))
+		return generated.ApiKey{}, apperrors.NewInternalError("failed to revoke API key", err)
+	}
+
+	r.logger.Info("revoked API key", zap.String("api_key_id", id.String()))
+	return key, nil
+}
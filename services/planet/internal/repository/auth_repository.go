@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type AuthRepository interface {
+	CreateRefreshToken(ctx context.Context, playerID uuid.UUID, tokenHash string, expiresAt time.Time) error
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (uuid.UUID, error)
+}
+
+type authRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewAuthRepository(q *generated.Queries, db DB, logger *zap.Logger) AuthRepository {
+	return &authRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *authRepository) CreateRefreshToken(ctx context.Context, playerID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	_, err := r.q.CreateRefreshToken(ctx, generated.CreateRefreshTokenParams{
+		PlayerID:  playerID,
+		TokenHash: tokenHash,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to store refresh token",
+			zap.String("player_id", playerID.String()),
+			zap.Error(err))
+		return apperrors.NewInternalError("failed to store refresh token", err)
+	}
+
+	return nil
+}
+
+// RotateRefreshToken revokes the refresh token hashed as oldHash and, in
+// the same transaction, stores newHash for the same player. Each refresh
+// token can be used once; an unknown, used or expired one is rejected. It
+// returns the token's player.
+func (r *authRepository) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (uuid.UUID, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return uuid.Nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	old, err := qtx.ConsumeRefreshToken(ctx, oldHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return uuid.Nil, apperrors.NewUnauthorizedError("invalid or expired refresh token")
+		}
+		r.logger.Error("failed to consume refresh token", zap.Error(err))
+		return uuid.Nil, apperrors.NewInternalError("failed to refresh session", err)
+	}
+
+	_, err = qtx.CreateRefreshToken(ctx, generated.CreateRefreshTokenParams{
+		PlayerID:  old.PlayerID,
+		TokenHash: newHash,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		r.logger.Error("failed to store refresh token",
+			zap.String("player_id", old.PlayerID.String()),
+			zap.Error(err))
+		return uuid.Nil, apperrors.NewInternalError("failed to store refresh token", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return uuid.Nil, apperrors.NewInternalError("failed to refresh session", err)
+	}
+
+	return old.PlayerID, nil
+}
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type BattleRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (generated.Battle, error)
+	List(ctx context.Context, params generated.ListPlanetBattlesParams) ([]generated.ListPlanetBattlesRow, error)
+	Record(ctx context.Context, planetID uuid.UUID, idempotencyKey string, fight BattleFighter) (generated.Battle, bool, error)
+
+	GetWave(ctx context.Context, id uuid.UUID) (generated.Wave, []generated.WaveSpawn, error)
+	FindAlienTemplates(ctx context.Context, ids []uuid.UUID) ([]generated.AlienTemplate, error)
+	ListActiveAlienTemplates(ctx context.Context) ([]generated.AlienTemplate, error)
+}
+
+// BattleFighter fights a battle against the locked planet row and its
+// defenses in slot order. It returns the planet's state after the battle,
+// the battle record and the loot to credit to the planet, or an error to
+// abort. Loot without an amount is not credited.
+type BattleFighter func(planet generated.Planet, defenses []generated.DefenseSystem) (generated.UpdatePlanetStateParams, generated.CreateBattleParams, generated.CreateLedgerEntryParams, error)
+
+type battleRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewBattleRepository(q *generated.Queries, db DB, logger *zap.Logger) BattleRepository {
+	return &battleRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *battleRepository) GetByID(ctx context.Context, id uuid.UUID) (generated.Battle, error) {
+	battle, err := r.q.GetBattleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("battle not found", zap.String("battle_id", id.String()))
+			return generated.Battle{}, apperrors.NewNotFoundError("battle", "battle with given ID does not exist")
+		}
+
+		r.logger.Error("failed to get battle by ID",
+			zap.String("battle_id", id.String()),
+			zap.Error(err))
+		return generated.Battle{}, apperrors.NewInternalError("failed to retrieve battle", err)
+	}
+
+	return battle, nil
+}
+
+func (r *battleRepository) List(ctx context.Context, params generated.ListPlanetBattlesParams) ([]generated.ListPlanetBattlesRow, error) {
+	battles, err := r.q.ListPlanetBattles(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to list battles",
+			zap.String("planet_id", params.PlanetID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve battles", err)
+	}
+
+	return battles, nil
+}
+
+// Record locks the planet and its defenses, lets fight run the battle
+// against them and stores the battle, the planet's new state and the loot
+// credit in one transaction. The defenses are share-locked, so they can't be
+// moved, upgraded or removed mid-battle; one added meanwhile sits this
+// battle out. The loot goes through the resource ledger. A battle already
+// recorded under idempotencyKey is returned as is, with false, and nothing is
+// fought.
+func (r *battleRepository) Record(ctx context.Context, planetID uuid.UUID, idempotencyKey string, fight BattleFighter) (generated.Battle, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	planet, err := qtx.GetPlanetForUpdate(ctx, planetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Battle{}, false, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+		r.logger.Error("failed to lock planet", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	key := pgtype.Text{String: idempotencyKey, Valid: idempotencyKey != ""}
+	if key.Valid {
+		// Retries of the same request wait on the planet lock above, so they
+		// see the battle the first one stored.
+		existing, lookupErr := qtx.GetBattleByIdempotencyKey(ctx, generated.GetBattleByIdempotencyKeyParams{
+			PlanetID:       planetID,
+			IdempotencyKey: key,
+		})
+		switch {
+		case lookupErr == nil:
+			if err := tx.Rollback(ctx); err != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(err))
+			}
+			return existing, false, nil
+		case !errors.Is(lookupErr, sql.ErrNoRows):
+			err = lookupErr
+			r.logger.Error("failed to look up battle by idempotency key", zap.Error(err))
+			return generated.Battle{}, false, apperrors.NewInternalError("failed to retrieve battle", err)
+		}
+	}
+
+	defenses, err := qtx.ListDefenseSystemsByPlanetIDForShare(ctx, planetID)
+	if err != nil {
+		r.logger.Error("failed to lock defenses", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to retrieve defenses", err)
+	}
+
+	state, params, loot, err := fight(planet, defenses)
+	if err != nil {
+		return generated.Battle{}, false, err
+	}
+
+	state.ID = planetID
+	if _, err = qtx.UpdatePlanetState(ctx, state); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Battle{}, false, planetDestroyedError()
+		}
+		r.logger.Error("failed to update planet after battle", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to update planet", err)
+	}
+
+	params.PlanetID = planetID
+	params.IdempotencyKey = key
+	battle, err := qtx.CreateBattle(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to store battle", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to store battle", err)
+	}
+
+	if loot.Amount != nil {
+		if err = r.creditLoot(ctx, qtx, battle, loot); err != nil {
+			return generated.Battle{}, false, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.Battle{}, false, apperrors.NewInternalError("failed to save battle", err)
+	}
+
+	r.logger.Info("successfully recorded battle",
+		zap.String("planet_id", planetID.String()),
+		zap.String("battle_id", battle.ID.String()),
+		zap.String("outcome", battle.Outcome))
+
+	return battle, true, nil
+}
+
+// creditLoot records the battle's loot in the ledger and adds it to the
+// planet's resources. Each battle is stored once, since retries are caught by
+// their idempotency key before fighting, so its loot is never credited twice.
+func (r *battleRepository) creditLoot(ctx context.Context, qtx *generated.Queries, battle generated.Battle, loot generated.CreateLedgerEntryParams) error {
+	loot.PlanetID = battle.PlanetID
+	loot.Source = LedgerBattleLoot
+	loot.ReferenceID = battle.ID
+	if _, err := qtx.CreateLedgerEntry(ctx, loot); err != nil {
+		r.logger.Error("failed to record loot", zap.String("battle_id", battle.ID.String()), zap.Error(err))
+		return apperrors.NewInternalError("failed to record loot", err)
+	}
+
+	err := qtx.CreditPlanetResources(ctx, generated.CreditPlanetResourcesParams{
+		Amount: loot.Amount,
+		ID:     battle.PlanetID,
+	})
+	if err != nil {
+		r.logger.Error("failed to credit loot", zap.String("battle_id", battle.ID.String()), zap.Error(err))
+		return apperrors.NewInternalError("failed to credit loot", err)
+	}
+	return nil
+}
+
+func (r *battleRepository) GetWave(ctx context.Context, id uuid.UUID) (generated.Wave, []generated.WaveSpawn, error) {
+	wave, err := r.q.GetWaveByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Wave{}, nil, apperrors.NewNotFoundError("wave", "wave with given ID does not exist")
+		}
+		r.logger.Error("failed to get wave by ID", zap.String("wave_id", id.String()), zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to retrieve wave", err)
+	}
+
+	spawns, err := r.q.ListWaveSpawns(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to list wave spawns", zap.String("wave_id", id.String()), zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to retrieve wave spawns", err)
+	}
+
+	return wave, spawns, nil
+}
+
+func (r *battleRepository) FindAlienTemplates(ctx context.Context, ids []uuid.UUID) ([]generated.AlienTemplate, error) {
+	templates, err := r.q.ListAlienTemplatesByIDs(ctx, ids)
+	if err != nil {
+		r.logger.Error("failed to look up alien templates", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien templates", err)
+	}
+
+	return templates, nil
+}
+
+func (r *battleRepository) ListActiveAlienTemplates(ctx context.Context) ([]generated.AlienTemplate, error) {
+	templates, err := r.q.ListAlienTemplates(ctx, false)
+	if err != nil {
+		r.logger.Error("failed to list alien templates", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien templates", err)
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type BuildingRepository interface {
+	ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.PlanetBuilding, error)
+	Queue(ctx context.Context, planetID uuid.UUID, kind string, plan BuildPlanner) (generated.PlanetBuilding, []byte, error)
+}
+
+// BuildPlanner decides the outcome of a construction order from the locked
+// planet and building rows. A building that doesn't exist yet is passed as a
+// level 0 row without an ID. It returns the planet's new resources and the
+// building's new state, or an error to abort.
+type BuildPlanner func(planet generated.Planet, building generated.PlanetBuilding) (generated.UpdatePlanetResourcesParams, generated.UpsertPlanetBuildingParams, error)
+
+type buildingRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewBuildingRepository(q *generated.Queries, db DB, logger *zap.Logger) BuildingRepository {
+	return &buildingRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListByPlanetID reads the planet's buildings without locking them, and
+// callers accrue production from the list under the planet lock. That is
+// safe: construction only ever moves forward and a finished level counts
+// from its completion time whether or not it has been recorded, so a stale
+// list accrues the same production.
+func (r *buildingRepository) ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.PlanetBuilding, error) {
+	buildings, err := r.q.ListPlanetBuildings(ctx, planetID)
+	if err != nil {
+		r.logger.Error("failed to list buildings",
+			zap.String("planet_id", planetID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve buildings", err)
+	}
+
+	return buildings, nil
+}
+
+// Queue locks the planet and the building, lets plan price the order and, in
+// one transaction, writes the payment and the building and schedules the job
+// that records the finished level.
+func (r *buildingRepository) Queue(ctx context.Context, planetID uuid.UUID, kind string, plan BuildPlanner) (generated.PlanetBuilding, []byte, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	planet, err := qtx.GetPlanetForUpdate(ctx, planetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.PlanetBuilding{}, nil, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+		r.logger.Error("failed to lock planet", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	building, err := qtx.GetPlanetBuildingForUpdate(ctx, generated.GetPlanetBuildingForUpdateParams{
+		PlanetID: planetID,
+		Kind:     kind,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			r.logger.Error("failed to lock building",
+				zap.String("planet_id", planetID.String()),
+				zap.String("kind", kind),
+				zap.Error(err))
+			return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to retrieve building", err)
+		}
+		building = generated.PlanetBuilding{PlanetID: planetID, Kind: kind}
+	}
+
+	resources, params, err := plan(planet, building)
+	if err != nil {
+		return generated.PlanetBuilding{}, nil, err
+	}
+
+	resources.ID = planetID
+	err = qtx.UpdatePlanetResources(ctx, resources)
+	if err != nil {
+		r.logger.Error("failed to deduct build cost", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to update planet resources", err)
+	}
+
+	params.PlanetID = planetID
+	params.Kind = kind
+	queued, err := qtx.UpsertPlanetBuilding(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to queue building",
+			zap.String("planet_id", planetID.String()),
+			zap.String("kind", kind),
+			zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to queue building", err)
+	}
+
+	_, err = qtx.CreateJob(ctx, generated.CreateJobParams{
+		PlanetID:    planetID,
+		Kind:        JobBuildStructure,
+		TargetID:    queued.ID,
+		TargetLevel: queued.Level + 1,
+		RunAt:       queued.BuildCompletesAt,
+	})
+	if err != nil {
+		r.logger.Error("failed to schedule building",
+			zap.String("building_id", queued.ID.String()),
+			zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to schedule building", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.PlanetBuilding{}, nil, apperrors.NewInternalError("failed to save building", err)
+	}
+
+	r.logger.Info("successfully queued building",
+		zap.String("planet_id", planetID.String()),
+		zap.String("kind", kind),
+		zap.Int32("level", queued.Level+1))
+
+	return queued, resources.Resources, nil
+}
@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type DefenseRepository interface {
+	Create(ctx context.Context, arg generated.CreateDefenseSystemParams) (generated.DefenseSystem, error)
+	ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.DefenseSystem, error)
+	Delete(ctx context.Context, planetID, defenseID uuid.UUID) error
+	Move(ctx context.Context, planetID, defenseID uuid.UUID, position int32) ([]generated.DefenseSystem, error)
+	Upgrade(ctx context.Context, planetID, defenseID uuid.UUID, plan UpgradePlanner) (generated.DefenseSystem, []byte, error)
+}
+
+// UpgradePlanner decides the outcome of an upgrade from the locked planet and
+// defense rows. It returns the planet's new resources and the upgrade's
+// timing, or an error to abort the upgrade.
+type UpgradePlanner func(planet generated.Planet, defense generated.DefenseSystem) (generated.UpdatePlanetResourcesParams, generated.StartDefenseUpgradeParams, error)
+
+type defenseRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewDefenseRepository(q *generated.Queries, db DB, logger *zap.Logger) DefenseRepository {
+	return &defenseRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *defenseRepository) Create(ctx context.Context, arg generated.CreateDefenseSystemParams) (generated.DefenseSystem, error) {
+	defense, err := r.q.CreateDefenseSystem(ctx, arg)
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			r.logger.Debug("defense position taken",
+				zap.String("planet_id", arg.PlanetID.String()),
+				zap.Int32("position", arg.Position))
+			return generated.DefenseSystem{}, apperrors.NewAlreadyExistsError("defense", "a defense already occupies this position")
+		}
+
+		r.logger.Error("failed to create defense",
+			zap.String("planet_id", arg.PlanetID.String()),
+			zap.String("kind", arg.Kind),
+			zap.Error(err))
+		return generated.DefenseSystem{}, apperrors.NewInternalError("failed to create defense", err)
+	}
+
+	r.logger.Info("successfully created defense",
+		zap.String("planet_id", arg.PlanetID.String()),
+		zap.String("defense_id", defense.ID.String()),
+		zap.String("kind", arg.Kind))
+
+	return defense, nil
+}
+
+func (r *defenseRepository) ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.DefenseSystem, error) {
+	defenses, err := r.q.ListDefenseSystemsByPlanetID(ctx, planetID)
+	if err != nil {
+		r.logger.Error("failed to list defenses",
+			zap.String("planet_id", planetID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve defenses", err)
+	}
+
+	return defenses, nil
+}
+
+func (r *defenseRepository) Delete(ctx context.Context, planetID, defenseID uuid.UUID) error {
+	rows, err := r.q.DeleteDefenseSystem(ctx, generated.DeleteDefenseSystemParams{
+		ID:       defenseID,
+		PlanetID: planetID,
+	})
+	if err != nil {
+		r.logger.Error("failed to delete defense",
+			zap.String("defense_id", defenseID.String()),
+			zap.Error(err))
+		return apperrors.NewInternalError("failed to delete defense", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError("defense", "defense with given ID does not exist on this planet")
+	}
+
+	r.logger.Info("successfully removed defense",
+		zap.String("planet_id", planetID.String()),
+		zap.String("defense_id", defenseID.String()))
+	return nil
+}
+
+// Move puts a defense into the given slot. A defense already in that slot
+// takes the moved defense's old slot.
+func (r *defenseRepository) Move(ctx context.Context, planetID, defenseID uuid.UUID, position int32) ([]generated.DefenseSystem, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	defense, err := qtx.GetDefenseSystemForUpdate(ctx, generated.GetDefenseSystemForUpdateParams{
+		ID:       defenseID,
+		PlanetID: planetID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NewNotFoundError("defense", "defense with given ID does not exist on this planet")
+		}
+		r.logger.Error("failed to lock defense", zap.String("defense_id", defenseID.String()), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve defense", err)
+	}
+
+	occupant, err := qtx.GetDefenseSystemAtPosition(ctx, generated.GetDefenseSystemAtPositionParams{
+		PlanetID: planetID,
+		Position: position,
+	})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		err = nil
+	case err != nil:
+		r.logger.Error("failed to look up defense position", zap.Int32("position", position), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve defense", err)
+	case occupant.ID != defense.ID:
+		err = qtx.UpdateDefenseSystemPosition(ctx, generated.UpdateDefenseSystemPositionParams{
+			ID:       occupant.ID,
+			Position: defense.Position,
+		})
+		if err != nil {
+			r.logger.Error("failed to move defense", zap.String("defense_id", occupant.ID.String()), zap.Error(err))
+			return nil, apperrors.NewInternalError("failed to move defense", err)
+		}
+	}
+
+	err = qtx.UpdateDefenseSystemPosition(ctx, generated.UpdateDefenseSystemPositionParams{
+		ID:       defense.ID,
+		Position: position,
+	})
+	if err != nil {
+		r.logger.Error("failed to move defense", zap.String("defense_id", defense.ID.String()), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to move defense", err)
+	}
+
+	defenses, err := qtx.ListDefenseSystemsByPlanetID(ctx, planetID)
+	if err != nil {
+		r.logger.Error("failed to list defenses", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve defenses", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to save defense positions", err)
+	}
+
+	r.logger.Info("successfully moved defense",
+		zap.String("defense_id", defenseID.String()),
+		zap.Int32("position", position))
+
+	return defenses, nil
+}
+
+// Upgrade locks the planet and the defense, lets plan price the upgrade and,
+// in one transaction, deducts the cost, starts the upgrade and schedules the
+// job that applies the new level once it completes. Locking the planet row
+// serializes concurrent upgrades on the same planet, so its resources can't
+// be spent twice.
+func (r *defenseRepository) Upgrade(ctx context.Context, planetID, defenseID uuid.UUID, plan UpgradePlanner) (generated.DefenseSystem, []byte, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	planet, err := qtx.GetPlanetForUpdate(ctx, planetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.DefenseSystem{}, nil, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+		r.logger.Error("failed to lock planet", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	defense, err := qtx.GetDefenseSystemForUpdate(ctx, generated.GetDefenseSystemForUpdateParams{
+		ID:       defenseID,
+		PlanetID: planetID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.DefenseSystem{}, nil, apperrors.NewNotFoundError("defense", "defense with given ID does not exist on this planet")
+		}
+		r.logger.Error("failed to lock defense", zap.String("defense_id", defenseID.String()), zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to retrieve defense", err)
+	}
+
+	resources, params, err := plan(planet, defense)
+	if err != nil {
+		return generated.DefenseSystem{}, nil, err
+	}
+
+	resources.ID = planetID
+	err = qtx.UpdatePlanetResources(ctx, resources)
+	if err != nil {
+		r.logger.Error("failed to deduct upgrade cost", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to update planet resources", err)
+	}
+
+	params.ID = defenseID
+	upgraded, err := qtx.StartDefenseUpgrade(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to start defense upgrade", zap.String("defense_id", defenseID.String()), zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to upgrade defense", err)
+	}
+
+	_, err = qtx.CreateJob(ctx, generated.CreateJobParams{
+		PlanetID:    planetID,
+		Kind:        JobUpgradeDefense,
+		TargetID:    defenseID,
+		TargetLevel: defense.Level + 1,
+		RunAt:       params.UpgradeCompletesAt,
+	})
+	if err != nil {
+		r.logger.Error("failed to schedule defense upgrade", zap.String("defense_id", defenseID.String()), zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to schedule defense upgrade", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.DefenseSystem{}, nil, apperrors.NewInternalError("failed to save defense upgrade", err)
+	}
+
+	r.logger.Info("successfully started defense upgrade",
+		zap.String("planet_id", planetID.String()),
+		zap.String("defense_id", defenseID.String()),
+		zap.Int32("level", upgraded.Level+1))
+
+	return upgraded, resources.Resources, nil
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// Job kinds run by the scheduler.
+const (
+	JobUpgradeDefense = "upgrade_defense"
+	JobBuildStructure = "build_structure"
+	JobResearchTech   = "research_tech"
+)
+
+// Job statuses. A pending job whose handler failed is retried with backoff
+// until it runs out of attempts, then marked failed and abandoned.
+const (
+	JobPending = "pending"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+const (
+	maxJobAttempts = 5
+	jobRetryDelay  = 30 * time.Second
+)
+
+// JobHandler applies a job's effects through q, which runs inside the
+// transaction that marks the job done.
+type JobHandler func(ctx context.Context, q *generated.Queries, job generated.Job) error
+
+type JobRepository interface {
+	RunNext(ctx context.Context, handle, abandon JobHandler) (bool, error)
+}
+
+type jobRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewJobRepository(q *generated.Queries, db DB, logger *zap.Logger) JobRepository {
+	return &jobRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RunNext claims one due job, runs handle on it and records the outcome, all
+// in one transaction. The claim skips rows other replicas have locked, and a
+// job is only marked done together with its effects, so each job takes
+// effect exactly once. A job out of attempts is passed to abandon, in the
+// same transaction, to undo what starting it did. It reports false when no
+// job was due.
+func (r *jobRepository) RunNext(ctx context.Context, handle, abandon JobHandler) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return false, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	job, err := qtx.ClaimDueJob(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		r.logger.Error("failed to claim job", zap.Error(err))
+		return false, apperrors.NewInternalError("failed to claim job", err)
+	}
+
+	// The handler runs in a savepoint so a failure can be undone while the
+	// job row stays locked for recording it.
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to create savepoint", zap.Error(err))
+		return false, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+
+	if handleErr := handle(ctx, r.q.WithTx(sp), job); handleErr != nil {
+		if err = sp.Rollback(ctx); err != nil {
+			r.logger.Error("failed to rollback savepoint", zap.Error(err))
+			return false, apperrors.NewInternalError("failed to rollback job", err)
+		}
+		err = r.recordFailure(ctx, tx, job, handleErr, abandon)
+	} else {
+		if err = sp.Commit(ctx); err != nil {
+			r.logger.Error("failed to release savepoint", zap.Error(err))
+			return false, apperrors.NewInternalError("failed to save job", err)
+		}
+		err = qtx.CompleteJob(ctx, job.ID)
+	}
+	if err != nil {
+		r.logger.Error("failed to update job", zap.String("job_id", job.ID.String()), zap.Error(err))
+		return false, apperrors.NewInternalError("failed to update job", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return false, apperrors.NewInternalError("failed to save job", err)
+	}
+
+	return true, nil
+}
+
+// recordFailure schedules a retry of job or, once it is out of attempts,
+// marks it failed and abandons it. An abandon that fails is rolled back and
+// logged: the job is marked failed regardless, so it can't block the queue.
+func (r *jobRepository) recordFailure(ctx context.Context, tx pgx.Tx, job generated.Job, cause error, abandon JobHandler) error {
+	q := r.q.WithTx(tx)
+	attempts := job.Attempts + 1
+	status := JobPending
+	if attempts >= maxJobAttempts {
+		status = JobFailed
+	}
+
+	r.logger.Warn("job failed",
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", job.Kind),
+		zap.Int32("attempts", attempts),
+		zap.String("status", status),
+		zap.Error(cause))
+
+	if status == JobFailed {
+		if err := r.abandon(ctx, tx, job, abandon); err != nil {
+			return err
+		}
+	}
+
+	return q.FailJob(ctx, generated.FailJobParams{
+		ID:        job.ID,
+		Status:    status,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+		RunAt:     pgtype.Timestamptz{Time: time.Now().Add(time.Duration(attempts) * jobRetryDelay), Valid: true},
+	})
+}
+
+// abandon runs abandon on job in a savepoint. Only errors from the savepoint
+// itself are returned.
+func (r *jobRepository) abandon(ctx context.Context, tx pgx.Tx, job generated.Job, abandon JobHandler) error {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if abandonErr := abandon(ctx, r.q.WithTx(sp), job); abandonErr != nil {
+		r.logger.Error("failed to abandon job",
+			zap.String("job_id", job.ID.String()),
+			zap.String("kind", job.Kind),
+			zap.Error(abandonErr))
+		return sp.Rollback(ctx)
+	}
+	return sp.Commit(ctx)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// Ledger sources. An entry's reference ID is unique within its source.
+const (
+	LedgerBattleLoot    = "battle_loot"
+	LedgerUpgradeRefund = "upgrade_refund" // referenced by the abandoned job
+)
+
+type LedgerRepository interface {
+	List(ctx context.Context, params generated.ListPlanetLedgerParams) ([]generated.ResourceLedger, error)
+	FindEntry(ctx context.Context, source string, referenceID uuid.UUID) (*generated.ResourceLedger, error)
+}
+
+type ledgerRepository struct {
+	q      *generated.Queries
+	logger *zap.Logger
+}
+
+func NewLedgerRepository(q *generated.Queries, logger *zap.Logger) LedgerRepository {
+	return &ledgerRepository{
+		q:      q,
+		logger: logger,
+	}
+}
+
+func (r *ledgerRepository) List(ctx context.Context, params generated.ListPlanetLedgerParams) ([]generated.ResourceLedger, error) {
+	entries, err := r.q.ListPlanetLedger(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to list ledger entries",
+			zap.String("planet_id", params.PlanetID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve ledger", err)
+	}
+
+	return entries, nil
+}
+
+// FindEntry returns what was credited for referenceID, or nil if nothing
+// was.
+func (r *ledgerRepository) FindEntry(ctx context.Context, source string, referenceID uuid.UUID) (*generated.ResourceLedger, error) {
+	entry, err := r.q.GetLedgerEntry(ctx, generated.GetLedgerEntryParams{
+		Source:      source,
+		ReferenceID: referenceID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		r.logger.Error("failed to get ledger entry",
+			zap.String("source", source),
+			zap.String("reference_id", referenceID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve ledger entry", err)
+	}
+
+	return &entry, nil
+}
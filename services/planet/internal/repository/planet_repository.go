@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type PlanetRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (generated.Planet, error)
+	GetByPlayerID(ctx context.Context, playerID uuid.UUID) (generated.Planet, error)
+	Update(ctx context.Context, id uuid.UUID, apply PlanetUpdater) (generated.Planet, error)
+	Rebuild(ctx context.Context, id uuid.UUID, apply PlanetUpdater) (generated.Planet, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// PlanetUpdater computes a planet's new state from its locked current row.
+type PlanetUpdater func(planet generated.Planet) (generated.UpdatePlanetStateParams, error)
+
+// planetDestroyedError is returned when an update would bring a destroyed
+// planet back without rebuilding it.
+func planetDestroyedError() error {
+	return apperrors.NewInvalidInputError("planet is destroyed and must be rebuilt first", nil)
+}
+
+type planetRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewPlanetRepository(q *generated.Queries, db DB, logger *zap.Logger) PlanetRepository {
+	return &planetRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *planetRepository) GetByID(ctx context.Context, id uuid.UUID) (generated.Planet, error) {
+	planet, err := r.q.GetPlanetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("planet not found", zap.String("planet_id", id.String()))
+			return generated.Planet{}, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+
+		r.logger.Error("failed to get planet by ID",
+			zap.String("planet_id", id.String()),
+			zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	return planet, nil
+}
+
+func (r *planetRepository) GetByPlayerID(ctx context.Context, playerID uuid.UUID) (generated.Planet, error) {
+	planet, err := r.q.GetPlanetByPlayerID(ctx, playerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("planet not found for player", zap.String("player_id", playerID.String()))
+			return generated.Planet{}, apperrors.NewNotFoundError("planet", "player has no planet")
+		}
+
+		r.logger.Error("failed to get planet by player ID",
+			zap.String("player_id", playerID.String()),
+			zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	return planet, nil
+}
+
+// Update locks the planet row, lets apply derive the new state from it and
+// writes the result in one transaction, so concurrent updates can't
+// overwrite each other. A destroyed planet can't be made active this way.
+func (r *planetRepository) Update(ctx context.Context, id uuid.UUID, apply PlanetUpdater) (generated.Planet, error) {
+	return r.update(ctx, id, apply, func(qtx *generated.Queries, params generated.UpdatePlanetStateParams) (generated.Planet, error) {
+		planet, err := qtx.UpdatePlanetState(ctx, params)
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Planet{}, planetDestroyedError()
+		}
+		return planet, err
+	})
+}
+
+// Rebuild brings a destroyed planet back. Only the resources, health and
+// updated_at apply returns are written; shields start empty.
+func (r *planetRepository) Rebuild(ctx context.Context, id uuid.UUID, apply PlanetUpdater) (generated.Planet, error) {
+	return r.update(ctx, id, apply, func(qtx *generated.Queries, params generated.UpdatePlanetStateParams) (generated.Planet, error) {
+		planet, err := qtx.RebuildPlanet(ctx, generated.RebuildPlanetParams{
+			ID:        params.ID,
+			Resources: params.Resources,
+			Health:    params.Health,
+			UpdatedAt: params.UpdatedAt,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Planet{}, apperrors.NewInvalidInputError("only destroyed planets can be rebuilt", nil)
+		}
+		return planet, err
+	})
+}
+
+func (r *planetRepository) update(ctx context.Context, id uuid.UUID, apply PlanetUpdater, write func(*generated.Queries, generated.UpdatePlanetStateParams) (generated.Planet, error)) (generated.Planet, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	current, err := qtx.GetPlanetForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.Planet{}, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+		r.logger.Error("failed to lock planet", zap.String("planet_id", id.String()), zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	params, err := apply(current)
+	if err != nil {
+		return generated.Planet{}, err
+	}
+
+	params.ID = id
+	planet, err := write(qtx, params)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			return generated.Planet{}, err
+		}
+		r.logger.Error("failed to update planet", zap.String("planet_id", id.String()), zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to update planet", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.Planet{}, apperrors.NewInternalError("failed to save planet", err)
+	}
+
+	r.logger.Info("successfully updated planet", zap.String("planet_id", id.String()))
+	return planet, nil
+}
+
+func (r *planetRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	rows, err := r.q.DeletePlanet(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to delete planet",
+			zap.String("planet_id", id.String()),
+			zap.Error(err))
+		return apperrors.NewInternalError("failed to delete planet", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+	}
+
+	r.logger.Info("successfully deleted planet", zap.String("planet_id", id.String()))
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 
 	"github.com/novaru/scallopticon/shared/apperrors"
@@ -15,9 +16,10 @@ import (
 )
 
 type PlayerRepository interface {
-	GetPlayers(ctx context.Context) ([]generated.Player, error)
+	List(ctx context.Context, params generated.ListPlayersParams, oldestFirst bool) ([]generated.Player, error)
 	GetByID(ctx context.Context, id uuid.UUID) (generated.Player, error)
-	CreatePlayerWithPlanet(ctx context.Context, username, planetName string) (generated.Player, generated.Planet, error)
+	GetByUsername(ctx context.Context, username string) (generated.Player, error)
+	CreatePlayerWithPlanet(ctx context.Context, username, passwordHash, planetName string) (generated.Player, generated.Planet, error)
 }
 
 type DB interface {
@@ -30,15 +32,24 @@ type playerRepository struct {
 	logger *zap.Logger
 }
 
-func NewPlayerRepository(q *generated.Queries, logger *zap.Logger) PlayerRepository {
+func NewPlayerRepository(q *generated.Queries, db DB, logger *zap.Logger) PlayerRepository {
 	return &playerRepository{
 		q:      q,
+		db:     db,
 		logger: logger,
 	}
 }
 
-func (r *playerRepository) GetPlayers(ctx context.Context) ([]generated.Player, error) {
-	players, err := r.q.ListPlayers(ctx)
+// List returns a page of players, newest first unless oldestFirst. The
+// params' cursor continues in the same order.
+func (r *playerRepository) List(ctx context.Context, params generated.ListPlayersParams, oldestFirst bool) ([]generated.Player, error) {
+	var players []generated.Player
+	var err error
+	if oldestFirst {
+		players, err = r.q.ListPlayersOldestFirst(ctx, generated.ListPlayersOldestFirstParams(params))
+	} else {
+		players, err = r.q.ListPlayers(ctx, params)
+	}
 	if err != nil {
 		r.logger.Error("failed to list players", zap.Error(err))
 		return nil, apperrors.NewInternalError("failed to retrieve players", err)
@@ -56,16 +67,33 @@ func (r *playerRepository) GetByID(ctx context.Context, id uuid.UUID) (generated
 			return generated.Player{}, apperrors.NewNotFoundError("player", "player with given ID does not exist")
 		}
 
-		r.logger.Error("faile to get player by ID",
+		r.logger.Error("failed to get player by ID",
 			zap.String("player_id", id.String()),
 			zap.Error(err))
-		return generated.Player{}, err
+		return generated.Player{}, apperrors.NewInternalError("failed to retrieve player", err)
 	}
 
 	return player, nil
 }
 
-func (r *playerRepository) CreatePlayerWithPlanet(ctx context.Context, username, planetName string) (generated.Player, generated.Planet, error) {
+func (r *playerRepository) GetByUsername(ctx context.Context, username string) (generated.Player, error) {
+	player, err := r.q.GetPlayerByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("player not found", zap.String("username", username))
+			return generated.Player{}, apperrors.NewNotFoundError("player", "player with given username does not exist")
+		}
+
+		r.logger.Error("failed to get player by username",
+			zap.String("username", username),
+			zap.Error(err))
+		return generated.Player{}, apperrors.NewInternalError("failed to retrieve player", err)
+	}
+
+	return player, nil
+}
+
+func (r *playerRepository) CreatePlayerWithPlanet(ctx context.Context, username, passwordHash, planetName string) (generated.Player, generated.Planet, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		r.logger.Error("failed to start transaction", zap.Error(err))
@@ -81,7 +109,10 @@ func (r *playerRepository) CreatePlayerWithPlanet(ctx context.Context, username,
 
 	qtx := r.q.WithTx(tx)
 
-	player, err := qtx.CreatePlayer(ctx, username)
+	player, err := qtx.CreatePlayer(ctx, generated.CreatePlayerParams{
+		Username:     username,
+		PasswordHash: pgtype.Text{String: passwordHash, Valid: true},
+	})
 	if err != nil {
 		if isDuplicateKeyError(err) {
 			r.logger.Debug("player already exists", zap.String("username", username))
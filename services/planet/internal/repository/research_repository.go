@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type ResearchRepository interface {
+	ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.PlanetResearch, error)
+	Queue(ctx context.Context, planetID uuid.UUID, tech string, plan ResearchPlanner) (generated.PlanetResearch, []byte, error)
+}
+
+// ResearchPlanner decides the outcome of a research order from the locked
+// planet and research rows. A tech never researched before is passed as a
+// level 0 row without an ID. It returns the planet's new resources, the
+// research's new state and, when that state records a finished level its job
+// hasn't, the shield upgrade the level grants. It returns an error to abort.
+type ResearchPlanner func(planet generated.Planet, research generated.PlanetResearch) (generated.UpdatePlanetResourcesParams, generated.UpsertPlanetResearchParams, *generated.UpgradePlanetShieldsParams, error)
+
+type researchRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewResearchRepository(q *generated.Queries, db DB, logger *zap.Logger) ResearchRepository {
+	return &researchRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *researchRepository) ListByPlanetID(ctx context.Context, planetID uuid.UUID) ([]generated.PlanetResearch, error) {
+	research, err := r.q.ListPlanetResearch(ctx, planetID)
+	if err != nil {
+		r.logger.Error("failed to list research",
+			zap.String("planet_id", planetID.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve research", err)
+	}
+
+	return research, nil
+}
+
+// Queue locks the planet and the research row, lets plan price the order
+// and, in one transaction, writes the payment, the research and any shield
+// upgrade and schedules the job that records the finished level.
+func (r *researchRepository) Queue(ctx context.Context, planetID uuid.UUID, tech string, plan ResearchPlanner) (generated.PlanetResearch, []byte, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	planet, err := qtx.GetPlanetForUpdate(ctx, planetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.PlanetResearch{}, nil, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+		r.logger.Error("failed to lock planet", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	research, err := qtx.GetPlanetResearchForUpdate(ctx, generated.GetPlanetResearchForUpdateParams{
+		PlanetID: planetID,
+		Tech:     tech,
+	})
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			r.logger.Error("failed to lock research",
+				zap.String("planet_id", planetID.String()),
+				zap.String("tech", tech),
+				zap.Error(err))
+			return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to retrieve research", err)
+		}
+		research = generated.PlanetResearch{PlanetID: planetID, Tech: tech}
+	}
+
+	resources, params, shields, err := plan(planet, research)
+	if err != nil {
+		return generated.PlanetResearch{}, nil, err
+	}
+
+	resources.ID = planetID
+	err = qtx.UpdatePlanetResources(ctx, resources)
+	if err != nil {
+		r.logger.Error("failed to deduct research cost", zap.String("planet_id", planetID.String()), zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to update planet resources", err)
+	}
+
+	params.PlanetID = planetID
+	params.Tech = tech
+	queued, err := qtx.UpsertPlanetResearch(ctx, params)
+	if err != nil {
+		r.logger.Error("failed to queue research",
+			zap.String("planet_id", planetID.String()),
+			zap.String("tech", tech),
+			zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to queue research", err)
+	}
+
+	// The finished level's job will find it already recorded and do
+	// nothing, so its upgrade has to be applied here.
+	if shields != nil {
+		shields.ID = planetID
+		err = qtx.UpgradePlanetShields(ctx, *shields)
+		if err != nil {
+			r.logger.Error("failed to upgrade shields",
+				zap.String("planet_id", planetID.String()),
+				zap.String("tech", tech),
+				zap.Error(err))
+			return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to upgrade planet shields", err)
+		}
+	}
+
+	_, err = qtx.CreateJob(ctx, generated.CreateJobParams{
+		PlanetID:    planetID,
+		Kind:        JobResearchTech,
+		TargetID:    queued.ID,
+		TargetLevel: queued.Level + 1,
+		RunAt:       queued.ResearchCompletesAt,
+	})
+	if err != nil {
+		r.logger.Error("failed to schedule research",
+			zap.String("research_id", queued.ID.String()),
+			zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to schedule research", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.PlanetResearch{}, nil, apperrors.NewInternalError("failed to save research", err)
+	}
+
+	r.logger.Info("successfully queued research",
+		zap.String("planet_id", planetID.String()),
+		zap.String("tech", tech),
+		zap.Int32("level", queued.Level+1))
+
+	return queued, resources.Resources, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// DefaultRotationGrace is how long a rotated key keeps working so its
+// callers can switch to the new one.
+const DefaultRotationGrace = time.Hour
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, name string, scopes []string, ttl *time.Duration) (CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) ([]APIKeyResponse, error)
+	RotateAPIKey(ctx context.Context, id uuid.UUID, ttl *time.Duration, grace *time.Duration) (CreateAPIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) (APIKeyResponse, error)
+}
+
+type APIKeyResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	RotatedFrom *uuid.UUID `json:"rotated_from,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResponse carries the key itself, which is not stored and
+// can't be retrieved again.
+type CreateAPIKeyResponse struct {
+	APIKey APIKeyResponse `json:"api_key"`
+	Key    string         `json:"key"`
+}
+
+type apiKeyService struct {
+	repo   repository.APIKeyRepository
+	logger *zap.Logger
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, logger *zap.Logger) APIKeyService {
+	return &apiKeyService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey issues a key with the given scopes, expiring after ttl or
+// never.
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, name string, scopes []string, ttl *time.Duration) (CreateAPIKeyResponse, error) {
+	s.logger.Debug("creating API key", zap.String("name", name))
+
+	if err := requireAdmin(ctx); err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return CreateAPIKeyResponse{}, apperrors.NewInvalidInputError("name is required", nil)
+	}
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+	expiresAt, err := keyExpiry(ttl)
+	if err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return CreateAPIKeyResponse{}, apperrors.NewInternalError("failed to generate API key", err)
+	}
+
+	params := generated.CreateAPIKeyParams{Name: name, KeyHash: hash, Scopes: scopes}
+	if expiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *expiresAt, Valid: true}
+	}
+	created, err := s.repo.Create(ctx, params)
+	if err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+
+	return CreateAPIKeyResponse{APIKey: convertAPIKey(created), Key: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]APIKeyResponse, error) {
+	s.logger.Debug("listing API keys")
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]APIKeyResponse, len(keys))
+	for i, k := range keys {
+		result[i] = convertAPIKey(k)
+	}
+	return result, nil
+}
+
+// RotateAPIKey replaces a key with a new one with the same name and
+// scopes, expiring after ttl or never. The old key keeps working for grace,
+// DefaultRotationGrace if nil.
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID, ttl *time.Duration, grace *time.Duration) (CreateAPIKeyResponse, error) {
+	s.logger.Debug("rotating API key", zap.String("api_key_id", id.String()))
+
+	if err := requireAdmin(ctx); err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+
+	expiresAt, err := keyExpiry(ttl)
+	if err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+	overlap := DefaultRotationGrace
+	if grace != nil {
+		if *grace < 0 {
+			return CreateAPIKeyResponse{}, apperrors.NewInvalidInputError("grace period can't be negative", nil)
+		}
+		overlap = *grace
+	}
+
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return CreateAPIKeyResponse{}, apperrors.NewInternalError("failed to generate API key", err)
+	}
+
+	rotated, err := s.repo.Rotate(ctx, id, hash, expiresAt, time.Now().Add(overlap))
+	if err != nil {
+		return CreateAPIKeyResponse{}, err
+	}
+
+	return CreateAPIKeyResponse{APIKey: convertAPIKey(rotated), Key: key}, nil
+}
+
+// RevokeAPIKey stops a key from working immediately.
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) (APIKeyResponse, error) {
+	s.logger.Debug("revoking API key", zap.String("api_key_id", id.String()))
+
+	if err := requireAdmin(ctx); err != nil {
+		return APIKeyResponse{}, err
+	}
+
+	key, err := s.repo.Revoke(ctx, id)
+	if err != nil {
+		return APIKeyResponse{}, err
+	}
+	return convertAPIKey(key), nil
+}
+
+// requireAdmin lets only admin players through. API keys can't manage API
+// keys.
+func requireAdmin(ctx context.Context) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return apperrors.NewUnauthorizedError("authentication required")
+	}
+	if !caller.IsAdmin() {
+		return apperrors.NewForbiddenError("only admins can manage API keys")
+	}
+	return nil
+}
+
+// normalizeScopes checks that every scope is known and drops duplicates.
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, apperrors.NewInvalidInputError("at least one scope is required", nil)
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return nil, apperrors.NewInvalidInputError(
+				fmt.Sprintf("unknown scope %q, expected one of %s", scope, strings.Join(auth.Scopes, ", ")), nil)
+		}
+	}
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
+
+// keyExpiry returns when a key issued now with ttl expires, or nil if it
+// never does.
+func keyExpiry(ttl *time.Duration) (*time.Time, error) {
+	if ttl == nil {
+		return nil, nil
+	}
+	if *ttl <= 0 {
+		return nil, apperrors.NewInvalidInputError("expiry must be in the future", nil)
+	}
+	t := time.Now().Add(*ttl)
+	return &t, nil
+}
+
+func convertAPIKey(k generated.ApiKey) APIKeyResponse {
+	result := APIKeyResponse{
+		ID:        k.ID,
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		CreatedAt: k.CreatedAt.Time,
+	}
+	if k.ExpiresAt.Valid {
+		result.ExpiresAt = &k.ExpiresAt.Time
+	}
+	if k.RevokedAt.Valid {
+		result.RevokedAt = &k.RevokedAt.Time
+	}
+	if k.RotatedFrom.Valid {
+		id := uuid.UUID(k.RotatedFrom.Bytes)
+		result.RotatedFrom = &id
+	}
+	return result
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// Token lifetimes. Access tokens are short lived; a refresh token gets the
+// player a new pair and is then spent.
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Password length limits. bcrypt ignores anything past 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// dummyPasswordHash is compared against when the username is unknown, so
+// a failed login takes as long whether or not the player exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+
+type AuthService interface {
+	Login(ctx context.Context, username, password string) (TokenResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (TokenResponse, error)
+}
+
+type TokenResponse struct {
+	PlayerID         uuid.UUID `json:"player_id"`
+	Role             string    `json:"role"`
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type authService struct {
+	repo       repository.AuthRepository
+	playerRepo repository.PlayerRepository
+	signer     *auth.Signer
+	logger     *zap.Logger
+}
+
+func NewAuthService(repo repository.AuthRepository, playerRepo repository.PlayerRepository, signer *auth.Signer, logger *zap.Logger) AuthService {
+	return &authService{
+		repo:       repo,
+		playerRepo: playerRepo,
+		signer:     signer,
+		logger:     logger,
+	}
+}
+
+// Login checks the player's password and starts a session.
+func (s *authService) Login(ctx context.Context, username, password string) (TokenResponse, error) {
+	username = normalizeUsername(username)
+	s.logger.Debug("logging in", zap.String("username", username))
+
+	player, err := s.playerRepo.GetByUsername(ctx, username)
+	if err != nil && !errors.Is(err, apperrors.ErrNotFound) {
+		return TokenResponse{}, err
+	}
+
+	known := err == nil && player.PasswordHash.Valid
+	hash := dummyPasswordHash
+	if known {
+		hash = []byte(player.PasswordHash.String)
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !known {
+		s.logger.Debug("login failed", zap.String("username", username))
+		return TokenResponse{}, apperrors.NewUnauthorizedError("invalid username or password")
+	}
+
+	now := time.Now()
+	refreshToken, refreshHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return TokenResponse{}, apperrors.NewInternalError("failed to create refresh token", err)
+	}
+	if err := s.repo.CreateRefreshToken(ctx, player.ID, refreshHash, now.Add(RefreshTokenTTL)); err != nil {
+		return TokenResponse{}, err
+	}
+
+	s.logger.Info("player logged in", zap.String("player_id", player.ID.String()))
+	return s.tokens(player, refreshToken, now)
+}
+
+// Refresh spends a refresh token on a new access and refresh token pair.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (TokenResponse, error) {
+	s.logger.Debug("refreshing session")
+
+	now := time.Now()
+	next, nextHash, err := auth.NewRefreshToken()
+	if err != nil {
+		return TokenResponse{}, apperrors.NewInternalError("failed to create refresh token", err)
+	}
+
+	playerID, err := s.repo.RotateRefreshToken(ctx, auth.HashRefreshToken(refreshToken), nextHash, now.Add(RefreshTokenTTL))
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	// Reload the player so a changed role takes effect on the next refresh.
+	player, err := s.playerRepo.GetByID(ctx, playerID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+
+	return s.tokens(player, next, now)
+}
+
+func (s *authService) tokens(player generated.Player, refreshToken string, now time.Time) (TokenResponse, error) {
+	access, claims, err := s.signer.Issue(player.ID, player.Role, now)
+	if err != nil {
+		s.logger.Error("failed to sign access token", zap.String("player_id", player.ID.String()), zap.Error(err))
+		return TokenResponse{}, apperrors.NewInternalError("failed to create access token", err)
+	}
+
+	return TokenResponse{
+		PlayerID:         player.ID,
+		Role:             player.Role,
+		AccessToken:      access,
+		TokenType:        "Bearer",
+		ExpiresAt:        time.Unix(claims.ExpiresAt, 0).UTC(),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(RefreshTokenTTL).UTC(),
+	}, nil
+}
+
+// hashPassword returns the bcrypt hash stored for password.
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
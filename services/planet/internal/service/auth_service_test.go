@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// fakeAuthRepo keeps unspent refresh tokens by hash, like the
+// refresh_tokens table does.
+type fakeAuthRepo struct {
+	tokens map[string]uuid.UUID
+}
+
+func (r *fakeAuthRepo) CreateRefreshToken(_ context.Context, playerID uuid.UUID, tokenHash string, _ time.Time) error {
+	r.tokens[tokenHash] = playerID
+	return nil
+}
+
+func (r *fakeAuthRepo) RotateRefreshToken(_ context.Context, oldHash, newHash string, _ time.Time) (uuid.UUID, error) {
+	playerID, ok := r.tokens[oldHash]
+	if !ok {
+		return uuid.Nil, apperrors.NewUnauthorizedError("invalid or expired refresh token")
+	}
+	delete(r.tokens, oldHash)
+	r.tokens[newHash] = playerID
+	return playerID, nil
+}
+
+type fakePlayerRepo struct {
+	player generated.Player
+}
+
+func (r *fakePlayerRepo) List(context.Context, generated.ListPlayersParams, bool) ([]generated.Player, error) {
+	return []generated.Player{r.player}, nil
+}
+
+func (r *fakePlayerRepo) GetByID(_ context.Context, id uuid.UUID) (generated.Player, error) {
+	if id != r.player.ID {
+		return generated.Player{}, apperrors.NewNotFoundError("player")
+	}
+	return r.player, nil
+}
+
+func (r *fakePlayerRepo) GetByUsername(_ context.Context, username string) (generated.Player, error) {
+	if username != r.player.Username {
+		return generated.Player{}, apperrors.NewNotFoundError("player")
+	}
+	return r.player, nil
+}
+
+func (r *fakePlayerRepo) CreatePlayerWithPlanet(context.Context, string, string, string) (generated.Player, generated.Planet, error) {
+	return generated.Player{}, generated.Planet{}, errors.New("not supported")
+}
+
+func newTestAuthService(t *testing.T) (AuthService, *fakeAuthRepo, *fakePlayerRepo, *auth.Signer) {
+	t.Helper()
+
+	signer, err := auth.NewSigner([]byte(strings.Repeat("k", auth.MinSecretLength)), AccessTokenTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := hashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &fakeAuthRepo{tokens: map[string]uuid.UUID{}}
+	players := &fakePlayerRepo{player: generated.Player{
+		ID:           uuid.New(),
+		Username:     "nova",
+		PasswordHash: pgtype.Text{String: hash, Valid: true},
+		Role:         auth.RolePlayer,
+	}}
+	return NewAuthService(repo, players, signer, zap.NewNop()), repo, players, signer
+}
+
+func TestLogin(t *testing.T) {
+	svc, repo, players, signer := newTestAuthService(t)
+
+	tokens, err := svc.Login(context.Background(), " Nova ", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := signer.Verify(tokens.AccessToken, time.Now())
+	if err != nil {
+		t.Fatalf("access token doesn't verify: %v", err)
+	}
+	if claims.PlayerID != players.player.ID || claims.Role != auth.RolePlayer {
+		t.Errorf("claims = %+v, want player %s", claims, players.player.ID)
+	}
+	if repo.tokens[auth.HashRefreshToken(tokens.RefreshToken)] != players.player.ID {
+		t.Error("the refresh token wasn't stored by its hash")
+	}
+
+	for _, bad := range []struct{ username, password string }{
+		{"nova", "wrong password"},
+		{"nobody", "correct horse"},
+	} {
+		if _, err := svc.Login(context.Background(), bad.username, bad.password); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("Login(%q, %q) err = %v, want UNAUTHORIZED", bad.username, bad.password, err)
+		}
+	}
+}
+
+func TestRefreshRotatesTheToken(t *testing.T) {
+	svc, repo, players, signer := newTestAuthService(t)
+	ctx := context.Background()
+
+	first, err := svc.Login(ctx, "nova", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An admin promotes the player between refreshes.
+	players.player.Role = auth.RoleAdmin
+
+	second, err := svc.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("Refresh returned the same refresh token")
+	}
+	claims, err := signer.Verify(second.AccessToken, time.Now())
+	if err != nil {
+		t.Fatalf("access token doesn't verify: %v", err)
+	}
+	if claims.Role != auth.RoleAdmin || second.Role != auth.RoleAdmin {
+		t.Errorf("role = %s, want the reloaded %s", claims.Role, auth.RoleAdmin)
+	}
+	if len(repo.tokens) != 1 {
+		t.Errorf("%d refresh tokens stored, want only the new one", len(repo.tokens))
+	}
+
+	// The spent token can't be used again, the new one can.
+	if _, err := svc.Refresh(ctx, first.RefreshToken); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("reusing a spent refresh token: err = %v, want UNAUTHORIZED", err)
+	}
+	if _, err := svc.Refresh(ctx, second.RefreshToken); err != nil {
+		t.Errorf("refreshing with the new token: %v", err)
+	}
+	if _, err := svc.Refresh(ctx, "made-up"); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("unknown refresh token: err = %v, want UNAUTHORIZED", err)
+	}
+}
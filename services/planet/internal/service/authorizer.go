@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+)
+
+// Authorizer decides whether the caller carried by a request context may
+// change a planet or see its battles and ledger.
+type Authorizer interface {
+	AuthorizePlanet(ctx context.Context, planetID uuid.UUID) error
+}
+
+type ownerAuthorizer struct {
+	planetRepo repository.PlanetRepository
+	logger     *zap.Logger
+}
+
+// NewAuthorizer returns an Authorizer that lets players change their own
+// planets and admins change any planet. Service callers are trusted with
+// any planet; their API key scopes are checked by the routes.
+func NewAuthorizer(planetRepo repository.PlanetRepository, logger *zap.Logger) Authorizer {
+	return &ownerAuthorizer{
+		planetRepo: planetRepo,
+		logger:     logger,
+	}
+}
+
+// AuthorizePlanet returns UNAUTHORIZED without a caller and FORBIDDEN when
+// the planet belongs to another player. A planet's owner never changes, so
+// the check doesn't need the planet lock.
+func (a *ownerAuthorizer) AuthorizePlanet(ctx context.Context, planetID uuid.UUID) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return apperrors.NewUnauthorizedError("authentication required")
+	}
+	if caller.IsAdmin() || caller.IsService() {
+		return nil
+	}
+
+	planet, err := a.planetRepo.GetByID(ctx, planetID)
+	if err != nil {
+		return err
+	}
+	if planet.PlayerID != caller.PlayerID {
+		a.logger.Info("denied change to another player's planet",
+			zap.String("planet_id", planetID.String()),
+			zap.String("player_id", caller.PlayerID.String()))
+		return apperrors.NewForbiddenError("planet belongs to another player")
+	}
+	return nil
+}
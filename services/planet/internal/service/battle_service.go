@@ -0,0 +1,591 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+	"github.com/novaru/scallopticon/shared/wavegen"
+)
+
+// Page sizes for battle history.
+const (
+	DefaultBattlePageSize = 20
+	MaxBattlePageSize     = 100
+)
+
+type BattleService interface {
+	FightBattle(ctx context.Context, planetID uuid.UUID, req FightBattleRequest) (types.Battle, bool, error)
+	GetBattle(ctx context.Context, id uuid.UUID) (types.Battle, error)
+	ListBattles(ctx context.Context, planetID uuid.UUID, filter BattleFilter) (BattlePage, error)
+	ReplayBattle(ctx context.Context, id uuid.UUID) (ReplayBattleResponse, error)
+	StreamBattle(ctx context.Context, id uuid.UUID) (*simulation.Battle, error)
+}
+
+// FightBattleRequest picks what the planet fights. Without a wave the planet
+// fights its next generated wave; without a seed a random one is used.
+// IdempotencyKey identifies the request so retries don't fight twice.
+type FightBattleRequest struct {
+	WaveID         *uuid.UUID
+	Seed           *int64
+	IdempotencyKey string
+}
+
+// BattleFilter narrows a planet's battle history. Since and Until bound when
+// the battle was fought, Until exclusive. Cursor is the NextCursor of the
+// previous page.
+type BattleFilter struct {
+	Outcome string
+	Since   *time.Time
+	Until   *time.Time
+	Cursor  string
+	Limit   int
+}
+
+type BattlePage struct {
+	Battles    []types.Battle
+	Limit      int
+	NextCursor string
+}
+
+type ReplayBattleResponse struct {
+	Original types.SimulationResult `json:"original"`
+	Replay   types.SimulationResult `json:"replay"`
+	Matches  bool                   `json:"matches"`
+}
+
+// battleSnapshot is everything a battle was fought with, stored so it can be
+// replayed after the planet, wave or templates have changed.
+type battleSnapshot struct {
+	Planet types.Planet          `json:"planet"`
+	Wave   types.Wave            `json:"wave"`
+	Aliens []types.AlienTemplate `json:"aliens"`
+}
+
+type battleService struct {
+	repo         repository.BattleRepository
+	buildingRepo repository.BuildingRepository
+	researchRepo repository.ResearchRepository
+	ledgerRepo   repository.LedgerRepository
+	authz        Authorizer
+	logger       *zap.Logger
+}
+
+func NewBattleService(repo repository.BattleRepository, buildingRepo repository.BuildingRepository, researchRepo repository.ResearchRepository, ledgerRepo repository.LedgerRepository, authz Authorizer, logger *zap.Logger) BattleService {
+	return &battleService{
+		repo:         repo,
+		buildingRepo: buildingRepo,
+		researchRepo: researchRepo,
+		ledgerRepo:   ledgerRepo,
+		authz:        authz,
+		logger:       logger,
+	}
+}
+
+// FightBattle runs a battle against the planet as it stands, applies the
+// damage and, on victory, moves the planet on to its next wave. The battle is
+// fought as the planet's next wave number whether the wave is given or
+// generated. Loot from destroyed aliens, raised by the planet's salvage
+// research, is credited to the planet with the battle. A request repeating
+// an earlier IdempotencyKey returns the battle it stored, with false,
+// instead of fighting again, which is what keeps loot from being credited
+// twice.
+func (s *battleService) FightBattle(ctx context.Context, planetID uuid.UUID, req FightBattleRequest) (types.Battle, bool, error) {
+	s.logger.Debug("fighting battle", zap.String("planet_id", planetID.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return types.Battle{}, false, err
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return types.Battle{}, false, err
+	}
+	// Like buildings, a research level counts from its completion time, so
+	// the loot bonus is the same whether or not this list is stale.
+	research, err := s.researchRepo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return types.Battle{}, false, err
+	}
+
+	var wave *types.Wave
+	var aliens []types.AlienTemplate
+	if req.WaveID != nil {
+		w, err := s.loadWave(ctx, *req.WaveID)
+		if err != nil {
+			return types.Battle{}, false, err
+		}
+		wave = &w
+		if aliens, err = s.waveTemplates(ctx, w); err != nil {
+			return types.Battle{}, false, err
+		}
+	} else {
+		rows, err := s.repo.ListActiveAlienTemplates(ctx)
+		if err != nil {
+			return types.Battle{}, false, err
+		}
+		if aliens, err = s.convertTemplates(rows); err != nil {
+			return types.Battle{}, false, err
+		}
+	}
+
+	seed := rand.Int64()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	now := time.Now().UTC()
+
+	battle, created, err := s.repo.Record(ctx, planetID, req.IdempotencyKey, func(planet generated.Planet, defenseRows []generated.DefenseSystem) (state generated.UpdatePlanetStateParams, record generated.CreateBattleParams, loot generated.CreateLedgerEntryParams, err error) {
+		planet, err = accrue(planet, buildings, now)
+		if err != nil {
+			return state, record, loot, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+		if planet.Status == types.PlanetDestroyed {
+			return state, record, loot, apperrors.NewInvalidInputError("planet is destroyed and must be rebuilt first", nil)
+		}
+
+		snapshot := battleSnapshot{Aliens: aliens}
+		if snapshot.Planet, err = convertPlanet(planet); err != nil {
+			return state, record, loot, apperrors.NewInternalError("failed to decode planet", err)
+		}
+		snapshot.Planet.Defenses = make([]types.DefenseSystem, len(defenseRows))
+		for i, d := range defenseRows {
+			if snapshot.Planet.Defenses[i], err = convertDefense(d); err != nil {
+				s.logger.Error("failed to decode defense", zap.String("defense_id", d.ID.String()), zap.Error(err))
+				return state, record, loot, apperrors.NewInternalError("failed to decode defense", err)
+			}
+		}
+
+		number := int(planet.CurrentWave) + 1
+		if wave != nil {
+			snapshot.Wave = *wave
+		} else if snapshot.Wave, err = generateWave(planetID, number, aliens); err != nil {
+			return state, record, loot, err
+		}
+
+		result, err := s.simulate(snapshot, seed, now)
+		if err != nil {
+			return state, record, loot, err
+		}
+
+		state = generated.UpdatePlanetStateParams{
+			Name:         planet.Name,
+			Resources:    planet.Resources,
+			DefenseLevel: planet.DefenseLevel,
+			CurrentWave:  planet.CurrentWave,
+			Health:       int32(result.HPRemaining),
+			Shields:      int32(result.ShieldsRemaining),
+			UpdatedAt:    planet.UpdatedAt,
+		}
+		if result.Outcome == types.OutcomeVictory {
+			state.CurrentWave = int32(number)
+		}
+		if err := settlePlanetStatus(planet, &state); err != nil {
+			return state, record, loot, err
+		}
+
+		if record, err = battleRecord(snapshot, number, result); err != nil {
+			return state, record, loot, err
+		}
+		if loot, err = lootCredit(result.Loot, lootBonusPercent(research, now)); err != nil {
+			return state, record, loot, apperrors.NewInternalError("failed to encode loot", err)
+		}
+		return state, record, loot, nil
+	})
+	if err != nil {
+		return types.Battle{}, false, err
+	}
+
+	converted, err := convertBattle(battle)
+	if err != nil {
+		s.logger.Error("failed to decode battle", zap.String("battle_id", battle.ID.String()), zap.Error(err))
+		return types.Battle{}, false, apperrors.NewInternalError("failed to decode battle", err)
+	}
+	if converted.LootCredited, err = s.creditedLoot(ctx, battle.ID); err != nil {
+		return types.Battle{}, false, err
+	}
+	return converted, created, nil
+}
+
+// GetBattle returns a battle fought by one of the caller's planets.
+func (s *battleService) GetBattle(ctx context.Context, id uuid.UUID) (types.Battle, error) {
+	s.logger.Debug("retrieving battle", zap.String("battle_id", id.String()))
+
+	battle, err := s.authorizedBattle(ctx, id)
+	if err != nil {
+		return types.Battle{}, err
+	}
+
+	converted, err := convertBattle(battle)
+	if err != nil {
+		s.logger.Error("failed to decode battle", zap.String("battle_id", id.String()), zap.Error(err))
+		return types.Battle{}, apperrors.NewInternalError("failed to decode battle", err)
+	}
+	if converted.LootCredited, err = s.creditedLoot(ctx, id); err != nil {
+		return types.Battle{}, err
+	}
+	return converted, nil
+}
+
+// creditedLoot returns the loot the battle credited to its planet, or nil
+// if it dropped none.
+func (s *battleService) creditedLoot(ctx context.Context, battleID uuid.UUID) (*types.Resources, error) {
+	entry, err := s.ledgerRepo.FindEntry(ctx, repository.LedgerBattleLoot, battleID)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var amount types.Resources
+	if err := json.Unmarshal(entry.Amount, &amount); err != nil {
+		s.logger.Error("failed to decode ledger entry", zap.String("entry_id", entry.ID.String()), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to decode ledger entry", err)
+	}
+	return &amount, nil
+}
+
+// ListBattles returns a page of the planet's battles, newest first, without
+// their event logs.
+func (s *battleService) ListBattles(ctx context.Context, planetID uuid.UUID, filter BattleFilter) (BattlePage, error) {
+	s.logger.Debug("listing battles", zap.String("planet_id", planetID.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return BattlePage{}, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultBattlePageSize
+	}
+	limit = min(limit, MaxBattlePageSize)
+
+	params := generated.ListPlanetBattlesParams{
+		PlanetID: planetID,
+		PageSize: int32(limit + 1), // one extra row tells whether there is a next page
+	}
+	if filter.Outcome != "" {
+		if !slices.Contains([]string{types.OutcomeVictory, types.OutcomeDefeat, types.OutcomeStalemate}, filter.Outcome) {
+			return BattlePage{}, apperrors.NewInvalidInputError(fmt.Sprintf("unknown outcome %q", filter.Outcome), nil)
+		}
+		params.Outcome = pgtype.Text{String: filter.Outcome, Valid: true}
+	}
+	if filter.Since != nil {
+		params.Since = pgtype.Timestamptz{Time: *filter.Since, Valid: true}
+	}
+	if filter.Until != nil {
+		params.Until = pgtype.Timestamptz{Time: *filter.Until, Valid: true}
+	}
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return BattlePage{}, apperrors.NewInvalidInputError("invalid cursor", err)
+		}
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	rows, err := s.repo.List(ctx, params)
+	if err != nil {
+		return BattlePage{}, err
+	}
+
+	page := BattlePage{Limit: limit, Battles: make([]types.Battle, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	for _, row := range rows {
+		battle, err := convertBattleRow(row)
+		if err != nil {
+			s.logger.Error("failed to decode battle", zap.String("battle_id", row.ID.String()), zap.Error(err))
+			return BattlePage{}, apperrors.NewInternalError("failed to decode battle", err)
+		}
+		page.Battles = append(page.Battles, battle)
+	}
+	return page, nil
+}
+
+// ReplayBattle re-runs a stored battle from its snapshot and seed and
+// reports whether it played out the same way.
+func (s *battleService) ReplayBattle(ctx context.Context, id uuid.UUID) (ReplayBattleResponse, error) {
+	s.logger.Debug("replaying battle", zap.String("battle_id", id.String()))
+
+	battle, err := s.authorizedBattle(ctx, id)
+	if err != nil {
+		return ReplayBattleResponse{}, err
+	}
+
+	original, err := convertBattle(battle)
+	if err != nil {
+		s.logger.Error("failed to decode battle", zap.String("battle_id", id.String()), zap.Error(err))
+		return ReplayBattleResponse{}, apperrors.NewInternalError("failed to decode battle", err)
+	}
+
+	replay, err := s.rerun(battle)
+	if err != nil {
+		return ReplayBattleResponse{}, err
+	}
+
+	for !replay.Done() {
+		replay.Step()
+	}
+	result := replay.Result()
+
+	return ReplayBattleResponse{
+		Original: original.SimulationResult,
+		Replay:   result,
+		Matches:  sameResult(original.SimulationResult, result),
+	}, nil
+}
+
+// StreamBattle sets a stored battle up to be played again tick by tick.
+// Battles are deterministic, so it plays out exactly as it was fought.
+func (s *battleService) StreamBattle(ctx context.Context, id uuid.UUID) (*simulation.Battle, error) {
+	s.logger.Debug("streaming battle", zap.String("battle_id", id.String()))
+
+	battle, err := s.authorizedBattle(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.rerun(battle)
+}
+
+// authorizedBattle loads a battle the caller may see: one fought by a
+// planet they own, or any battle for admins and service callers.
+func (s *battleService) authorizedBattle(ctx context.Context, id uuid.UUID) (generated.Battle, error) {
+	battle, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return generated.Battle{}, err
+	}
+	if err := s.authz.AuthorizePlanet(ctx, battle.PlanetID); err != nil {
+		return generated.Battle{}, err
+	}
+	return battle, nil
+}
+
+// rerun sets up a stored battle again from its snapshot and seed.
+func (s *battleService) rerun(battle generated.Battle) (*simulation.Battle, error) {
+	var snapshot battleSnapshot
+	if err := json.Unmarshal(battle.Snapshot, &snapshot); err != nil {
+		s.logger.Error("failed to decode battle snapshot", zap.String("battle_id", battle.ID.String()), zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to decode battle snapshot", err)
+	}
+	return s.newBattle(snapshot, battle.Seed, battle.FoughtAt.Time.UTC())
+}
+
+func (s *battleService) simulate(snapshot battleSnapshot, seed int64, at time.Time) (types.SimulationResult, error) {
+	b, err := s.newBattle(snapshot, seed, at)
+	if err != nil {
+		return types.SimulationResult{}, err
+	}
+
+	for !b.Done() {
+		b.Step()
+	}
+	return b.Result(), nil
+}
+
+func (s *battleService) newBattle(snapshot battleSnapshot, seed int64, at time.Time) (*simulation.Battle, error) {
+	templates := make(map[string]types.AlienTemplate, len(snapshot.Aliens))
+	for _, a := range snapshot.Aliens {
+		templates[a.ID] = a
+	}
+
+	b, err := simulation.NewBattle(simulation.Input{
+		Planet:    snapshot.Planet,
+		Wave:      snapshot.Wave,
+		Templates: templates,
+		Seed:      seed,
+		Timestamp: at,
+	})
+	if err != nil {
+		if simulation.IsInvalidInput(err) {
+			return nil, apperrors.NewInvalidInputError(err.Error(), err)
+		}
+		s.logger.Error("simulation failed", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to run simulation", err)
+	}
+	return b, nil
+}
+
+func (s *battleService) loadWave(ctx context.Context, id uuid.UUID) (types.Wave, error) {
+	wave, spawns, err := s.repo.GetWave(ctx, id)
+	if err != nil {
+		return types.Wave{}, err
+	}
+
+	aliens := make([]types.WaveSpawn, len(spawns))
+	for i, sp := range spawns {
+		aliens[i] = types.WaveSpawn{AlienID: sp.AlienID.String(), Count: int(sp.Count)}
+	}
+	return types.Wave{
+		ID:         wave.ID.String(),
+		Difficulty: int(wave.Difficulty),
+		Aliens:     aliens,
+		CreatedAt:  wave.CreatedAt.Time,
+	}, nil
+}
+
+// waveTemplates loads the templates a stored wave spawns, retired or not.
+func (s *battleService) waveTemplates(ctx context.Context, wave types.Wave) ([]types.AlienTemplate, error) {
+	ids := make([]uuid.UUID, 0, len(wave.Aliens))
+	for _, sp := range wave.Aliens {
+		id, err := uuid.Parse(sp.AlienID)
+		if err != nil {
+			return nil, apperrors.NewInternalError("wave has an invalid alien ID", err)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	rows, err := s.repo.FindAlienTemplates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return s.convertTemplates(rows)
+}
+
+func (s *battleService) convertTemplates(rows []generated.AlienTemplate) ([]types.AlienTemplate, error) {
+	templates := make([]types.AlienTemplate, len(rows))
+	for i, row := range rows {
+		t := types.AlienTemplate{
+			ID:           row.ID.String(),
+			Name:         row.Name,
+			HP:           int(row.Hp),
+			Damage:       int(row.Damage),
+			DamageType:   row.DamageType,
+			Speed:        row.Speed,
+			BehaviorType: row.BehaviorType,
+			Version:      int(row.Version),
+		}
+		if row.RetiredAt.Valid {
+			retiredAt := row.RetiredAt.Time
+			t.RetiredAt = &retiredAt
+		}
+		if err := json.Unmarshal(row.Resistances, &t.Resistances); err != nil {
+			s.logger.Error("failed to decode alien template", zap.String("template_id", row.ID.String()), zap.Error(err))
+			return nil, apperrors.NewInternalError("failed to decode alien template", err)
+		}
+		if err := json.Unmarshal(row.LootDrop, &t.LootDrop); err != nil {
+			s.logger.Error("failed to decode alien template", zap.String("template_id", row.ID.String()), zap.Error(err))
+			return nil, apperrors.NewInternalError("failed to decode alien template", err)
+		}
+		templates[i] = t
+	}
+	return templates, nil
+}
+
+// generateWave builds the planet's wave number n the same way the wave
+// service's next-wave endpoint does.
+func generateWave(planetID uuid.UUID, n int, templates []types.AlienTemplate) (types.Wave, error) {
+	wave, err := wavegen.Generate(wavegen.ClampDifficulty(n), wavegen.SeedFor(planetID.String(), n), templates)
+	if err != nil {
+		if errors.Is(err, wavegen.ErrNoTemplates) {
+			return types.Wave{}, apperrors.NewInvalidInputError(err.Error(), err)
+		}
+		return types.Wave{}, apperrors.NewInternalError("failed to generate wave", err)
+	}
+	return wave, nil
+}
+
+func battleRecord(snapshot battleSnapshot, number int, result types.SimulationResult) (generated.CreateBattleParams, error) {
+	record := generated.CreateBattleParams{
+		WaveNumber:       int32(number),
+		Seed:             result.Seed,
+		Outcome:          result.Outcome,
+		DamageTaken:      int32(result.DamageTaken),
+		ShieldsRemaining: int32(result.ShieldsRemaining),
+		HpRemaining:      int32(result.HPRemaining),
+		AliensDestroyed:  int32(result.AliensDestroyed),
+		FoughtAt:         pgtype.Timestamptz{Time: result.Timestamp, Valid: true},
+	}
+	if id, err := uuid.Parse(snapshot.Wave.ID); err == nil {
+		record.WaveID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	var err error
+	if record.Loot, err = json.Marshal(result.Loot); err != nil {
+		return record, apperrors.NewInternalError("failed to encode loot", err)
+	}
+	if record.Events, err = json.Marshal(result.Events); err != nil {
+		return record, apperrors.NewInternalError("failed to encode battle events", err)
+	}
+	if record.Snapshot, err = json.Marshal(snapshot); err != nil {
+		return record, apperrors.NewInternalError("failed to encode battle snapshot", err)
+	}
+	return record, nil
+}
+
+func sameResult(a, b types.SimulationResult) bool {
+	return a.Outcome == b.Outcome &&
+		a.DamageTaken == b.DamageTaken &&
+		a.ShieldsRemaining == b.ShieldsRemaining &&
+		a.HPRemaining == b.HPRemaining &&
+		a.AliensDestroyed == b.AliensDestroyed &&
+		a.Loot == b.Loot &&
+		reflect.DeepEqual(a.Events, b.Events) // Loot events hold pointers
+}
+
+func convertBattle(b generated.Battle) (types.Battle, error) {
+	result := types.Battle{
+		ID:       b.ID.String(),
+		PlanetID: b.PlanetID.String(),
+		SimulationResult: types.SimulationResult{
+			Outcome:          b.Outcome,
+			DamageTaken:      int(b.DamageTaken),
+			ShieldsRemaining: int(b.ShieldsRemaining),
+			HPRemaining:      int(b.HpRemaining),
+			AliensDestroyed:  int(b.AliensDestroyed),
+			Seed:             b.Seed,
+			Timestamp:        b.FoughtAt.Time,
+		},
+	}
+	if b.WaveID.Valid {
+		result.WaveID = uuid.UUID(b.WaveID.Bytes).String()
+	}
+	if err := json.Unmarshal(b.Loot, &result.Loot); err != nil {
+		return types.Battle{}, err
+	}
+	if err := json.Unmarshal(b.Events, &result.Events); err != nil {
+		return types.Battle{}, err
+	}
+	return result, nil
+}
+
+func convertBattleRow(b generated.ListPlanetBattlesRow) (types.Battle, error) {
+	result := types.Battle{
+		ID:       b.ID.String(),
+		PlanetID: b.PlanetID.String(),
+		SimulationResult: types.SimulationResult{
+			Outcome:          b.Outcome,
+			DamageTaken:      int(b.DamageTaken),
+			ShieldsRemaining: int(b.ShieldsRemaining),
+			HPRemaining:      int(b.HpRemaining),
+			AliensDestroyed:  int(b.AliensDestroyed),
+			Seed:             b.Seed,
+			Timestamp:        b.FoughtAt.Time,
+		},
+	}
+	if b.WaveID.Valid {
+		result.WaveID = uuid.UUID(b.WaveID.Bytes).String()
+	}
+	if err := json.Unmarshal(b.Loot, &result.Loot); err != nil {
+		return types.Battle{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// MaxBuildingLevel is the highest level a building can be constructed to.
+const MaxBuildingLevel = 10
+
+// Each level multiplies a building's production, build cost and build time
+// by these factors.
+const (
+	buildingProductionGrowth = 1.4
+	buildingCostGrowth       = 1.6
+	buildingTimeGrowth       = 1.5
+)
+
+// BuildingKind describes a resource building at level 1.
+type BuildingKind struct {
+	Name       string          `json:"name"`
+	Production types.Resources `json:"production"` // per production tick
+	BuildCost  types.Resources `json:"build_cost"`
+	BuildTime  time.Duration   `json:"build_time"`
+}
+
+var buildingKinds = map[string]BuildingKind{
+	"mineral_mine": {
+		Name: "Mineral Mine", Production: types.Resources{Minerals: 8},
+		BuildCost: types.Resources{Minerals: 60, Energy: 20}, BuildTime: 2 * time.Minute,
+	},
+	"energy_reactor": {
+		Name: "Energy Reactor", Production: types.Resources{Energy: 6},
+		BuildCost: types.Resources{Minerals: 80, Energy: 10}, BuildTime: 3 * time.Minute,
+	},
+	"tech_fabricator": {
+		Name: "Tech Fabricator", Production: types.Resources{TechParts: 1},
+		BuildCost: types.Resources{Minerals: 150, Energy: 100}, BuildTime: 10 * time.Minute,
+	},
+}
+
+// productionAt returns what the building makes per tick at level. A level 0
+// building is still under construction and makes nothing.
+func (k BuildingKind) productionAt(level int) types.Resources {
+	if level <= 0 {
+		return types.Resources{}
+	}
+	return scaleResources(k.Production, math.Pow(buildingProductionGrowth, float64(level-1)))
+}
+
+// costAt returns the price of constructing level.
+func (k BuildingKind) costAt(level int) types.Resources {
+	return scaleResources(k.BuildCost, math.Pow(buildingCostGrowth, float64(level-1)))
+}
+
+// buildTimeAt returns how long constructing level takes.
+func (k BuildingKind) buildTimeAt(level int) time.Duration {
+	return time.Duration(float64(k.BuildTime) * math.Pow(buildingTimeGrowth, float64(level-1))).Round(time.Second)
+}
+
+func scaleResources(r types.Resources, f float64) types.Resources {
+	return types.Resources{
+		Minerals:  int(math.Round(float64(r.Minerals) * f)),
+		Energy:    int(math.Round(float64(r.Energy) * f)),
+		TechParts: int(math.Round(float64(r.TechParts) * f)),
+	}
+}
+
+// BuildingKinds returns the names of all buildable structures.
+func BuildingKinds() []string {
+	kinds := make([]string, 0, len(buildingKinds))
+	for k := range buildingKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type BuildingService interface {
+	ListBuildings(ctx context.Context, planetID uuid.UUID) ([]types.Building, error)
+	QueueBuilding(ctx context.Context, planetID uuid.UUID, kind string) (QueueBuildingResponse, error)
+}
+
+type QueueBuildingResponse struct {
+	Building  types.Building  `json:"building"`
+	Resources types.Resources `json:"resources"` // planet resources left after paying
+}
+
+type buildingService struct {
+	repo       repository.BuildingRepository
+	planetRepo repository.PlanetRepository
+	authz      Authorizer
+	logger     *zap.Logger
+}
+
+func NewBuildingService(repo repository.BuildingRepository, planetRepo repository.PlanetRepository, authz Authorizer, logger *zap.Logger) BuildingService {
+	return &buildingService{
+		repo:       repo,
+		planetRepo: planetRepo,
+		authz:      authz,
+		logger:     logger,
+	}
+}
+
+func (s *buildingService) ListBuildings(ctx context.Context, planetID uuid.UUID) ([]types.Building, error) {
+	s.logger.Debug("retrieving buildings", zap.String("planet_id", planetID.String()))
+
+	if _, err := s.planetRepo.GetByID(ctx, planetID); err != nil {
+		return nil, err
+	}
+
+	buildings, err := s.repo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]types.Building, len(buildings))
+	for i, b := range buildings {
+		result[i] = convertBuilding(b, now)
+	}
+	return result, nil
+}
+
+// QueueBuilding pays for the next level of a building and starts its
+// construction. A planet without the building starts it at level 1.
+func (s *buildingService) QueueBuilding(ctx context.Context, planetID uuid.UUID, kind string) (QueueBuildingResponse, error) {
+	s.logger.Debug("queueing building",
+		zap.String("planet_id", planetID.String()),
+		zap.String("kind", kind))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return QueueBuildingResponse{}, err
+	}
+
+	spec, ok := buildingKinds[kind]
+	if !ok {
+		return QueueBuildingResponse{}, apperrors.NewInvalidInputError(
+			fmt.Sprintf("unknown building kind %q, expected one of %s", kind, strings.Join(BuildingKinds(), ", ")), nil)
+	}
+
+	buildings, err := s.repo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return QueueBuildingResponse{}, err
+	}
+
+	now := time.Now()
+	queued, resources, err := s.repo.Queue(ctx, planetID, kind, func(planet generated.Planet, b generated.PlanetBuilding) (pay generated.UpdatePlanetResourcesParams, build generated.UpsertPlanetBuildingParams, err error) {
+		level := buildingLevel(b, now)
+		if b.BuildCompletesAt.Valid && b.BuildCompletesAt.Time.After(now) {
+			return pay, build, apperrors.NewInvalidInputError(
+				fmt.Sprintf("%s is already under construction", spec.Name), nil)
+		}
+		if level >= MaxBuildingLevel {
+			return pay, build, apperrors.NewInvalidInputError(
+				fmt.Sprintf("%s is already at max level %d", spec.Name, MaxBuildingLevel), nil)
+		}
+
+		planet, err = accrue(planet, buildings, now)
+		if err != nil {
+			return pay, build, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+
+		var stock types.Resources
+		if err := json.Unmarshal(planet.Resources, &stock); err != nil {
+			return pay, build, apperrors.NewInternalError("failed to decode planet resources", err)
+		}
+
+		cost := spec.costAt(level + 1)
+		if !stock.Covers(cost) {
+			return pay, build, apperrors.NewInvalidInputError(
+				fmt.Sprintf("insufficient resources: level %d costs %d minerals, %d energy and %d tech parts",
+					level+1, cost.Minerals, cost.Energy, cost.TechParts), nil)
+		}
+
+		remaining, err := json.Marshal(stock.Sub(cost))
+		if err != nil {
+			return pay, build, apperrors.NewInternalError("failed to encode planet resources", err)
+		}
+
+		pay = generated.UpdatePlanetResourcesParams{
+			Resources: remaining,
+			Health:    planet.Health,
+			UpdatedAt: planet.UpdatedAt,
+		}
+		build = generated.UpsertPlanetBuildingParams{
+			Level:            int32(level),
+			BuildStartedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			BuildCompletesAt: pgtype.Timestamptz{Time: now.Add(spec.buildTimeAt(level + 1)), Valid: true},
+		}
+		return pay, build, nil
+	})
+	if err != nil {
+		return QueueBuildingResponse{}, err
+	}
+
+	var remaining types.Resources
+	if err := json.Unmarshal(resources, &remaining); err != nil {
+		return QueueBuildingResponse{}, apperrors.NewInternalError("failed to decode planet resources", err)
+	}
+
+	return QueueBuildingResponse{
+		Building:  convertBuilding(queued, now),
+		Resources: remaining,
+	}, nil
+}
+
+// finishBuilding records the level job constructed. A level already recorded
+// is a no-op.
+func finishBuilding(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	_, err := q.FinishPlanetBuilding(ctx, generated.FinishPlanetBuildingParams{
+		ID:    job.TargetID,
+		Level: job.TargetLevel,
+	})
+	return err
+}
+
+// convertBuilding maps a planet_buildings row to its state at now.
+func convertBuilding(b generated.PlanetBuilding, now time.Time) types.Building {
+	level := buildingLevel(b, now)
+	result := types.Building{
+		ID:       b.ID.String(),
+		PlanetID: b.PlanetID.String(),
+		Kind:     b.Kind,
+		Level:    level,
+	}
+	if kind, ok := buildingKinds[b.Kind]; ok {
+		result.Production = kind.productionAt(level)
+	}
+
+	if b.BuildCompletesAt.Valid && b.BuildCompletesAt.Time.After(now) {
+		started, completes := b.BuildStartedAt.Time, b.BuildCompletesAt.Time
+		result.BuildStartedAt = &started
+		result.BuildCompletesAt = &completes
+		if total := completes.Sub(started); total > 0 {
+			result.BuildProgress = float64(now.Sub(started)) / float64(total)
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// MaxDefenseSlots is the number of positions a planet has for defenses.
+const MaxDefenseSlots = 8
+
+// DefenseKind describes a buildable defense at level 1.
+type DefenseKind struct {
+	Name        string          `json:"name"`
+	Damage      int             `json:"damage"`
+	Range       int             `json:"range"`
+	FireRate    float64         `json:"fire_rate"`
+	DamageType  string          `json:"damage_type"`
+	UpgradeCost types.Resources `json:"upgrade_cost"`
+}
+
+var defenseKinds = map[string]DefenseKind{
+	"autocannon": {
+		Name: "Autocannon", Damage: 6, Range: 40, FireRate: 2, DamageType: types.DamageKinetic,
+		UpgradeCost: types.Resources{Minerals: 100, Energy: 20},
+	},
+	"laser_turret": {
+		Name: "Laser Turret", Damage: 10, Range: 60, FireRate: 1, DamageType: types.DamageLaser,
+		UpgradeCost: types.Resources{Minerals: 80, Energy: 80, TechParts: 5},
+	},
+	"plasma_cannon": {
+		Name: "Plasma Cannon", Damage: 25, Range: 35, FireRate: 0.5, DamageType: types.DamagePlasma,
+		UpgradeCost: types.Resources{Minerals: 150, Energy: 100, TechParts: 15},
+	},
+	"emp_tower": {
+		Name: "EMP Tower", Damage: 8, Range: 50, FireRate: 1.5, DamageType: types.DamageEMP,
+		UpgradeCost: types.Resources{Minerals: 60, Energy: 150, TechParts: 10},
+	},
+	"missile_battery": {
+		Name: "Missile Battery", Damage: 30, Range: 90, FireRate: 0.3, DamageType: types.DamageExplosive,
+		UpgradeCost: types.Resources{Minerals: 200, Energy: 50, TechParts: 20},
+	},
+}
+
+// defenseLevelScaling multiplies a kind's level 1 stats. Entry i applies to
+// level i+1; Cost scales the price of upgrading from that level to the next.
+var defenseLevelScaling = []struct {
+	Damage, Range, FireRate, Cost float64
+}{
+	{Damage: 1.0, Range: 1.0, FireRate: 1.0, Cost: 1.0},
+	{Damage: 1.3, Range: 1.1, FireRate: 1.1, Cost: 1.6},
+	{Damage: 1.7, Range: 1.2, FireRate: 1.2, Cost: 2.5},
+	{Damage: 2.2, Range: 1.3, FireRate: 1.3, Cost: 4.0},
+	{Damage: 2.8, Range: 1.4, FireRate: 1.45, Cost: 6.5},
+	{Damage: 3.5, Range: 1.5, FireRate: 1.6, Cost: 10.0},
+	{Damage: 4.3, Range: 1.6, FireRate: 1.8, Cost: 15.0},
+	{Damage: 5.2, Range: 1.75, FireRate: 2.0, Cost: 0},
+}
+
+// MaxDefenseLevel is the highest level a defense can be upgraded to.
+var MaxDefenseLevel = len(defenseLevelScaling)
+
+// Upgrading a defense to level 2 takes defenseUpgradeTime; each further
+// level takes defenseUpgradeTimeGrowth times longer than the one before.
+const (
+	defenseUpgradeTime       = time.Minute
+	defenseUpgradeTimeGrowth = 1.5
+)
+
+// upgradeTimeAt returns how long upgrading a defense to level takes.
+func upgradeTimeAt(level int) time.Duration {
+	return time.Duration(float64(defenseUpgradeTime) * math.Pow(defenseUpgradeTimeGrowth, float64(level-2))).Round(time.Second)
+}
+
+// DefenseStats are the stats of a defense kind at a given level.
+type DefenseStats struct {
+	Damage      int
+	Range       int
+	FireRate    float64
+	UpgradeCost types.Resources // zero at MaxDefenseLevel
+}
+
+// statsAt scales the kind's base stats to level, which must be between 1
+// and MaxDefenseLevel.
+func (k DefenseKind) statsAt(level int) DefenseStats {
+	s := defenseLevelScaling[level-1]
+	stats := DefenseStats{
+		Damage:   int(math.Round(float64(k.Damage) * s.Damage)),
+		Range:    int(math.Round(float64(k.Range) * s.Range)),
+		FireRate: math.Round(k.FireRate*s.FireRate*100) / 100,
+	}
+	if level < MaxDefenseLevel {
+		stats.UpgradeCost = types.Resources{
+			Minerals:  int(math.Round(float64(k.UpgradeCost.Minerals) * s.Cost)),
+			Energy:    int(math.Round(float64(k.UpgradeCost.Energy) * s.Cost)),
+			TechParts: int(math.Round(float64(k.UpgradeCost.TechParts) * s.Cost)),
+		}
+	}
+	return stats
+}
+
+// DefenseKinds returns the names of all buildable defense kinds, sorted.
+func DefenseKinds() []string {
+	kinds := make([]string, 0, len(defenseKinds))
+	for k := range defenseKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type DefenseService interface {
+	AddDefense(ctx context.Context, planetID uuid.UUID, kind string, position *int) (types.DefenseSystem, error)
+	ListDefenses(ctx context.Context, planetID uuid.UUID) ([]types.DefenseSystem, error)
+	RemoveDefense(ctx context.Context, planetID, defenseID uuid.UUID) error
+	MoveDefense(ctx context.Context, planetID, defenseID uuid.UUID, position int) ([]types.DefenseSystem, error)
+	UpgradeDefense(ctx context.Context, planetID, defenseID uuid.UUID) (UpgradeDefenseResponse, error)
+}
+
+type UpgradeDefenseResponse struct {
+	Defense   types.DefenseSystem `json:"defense"`
+	Resources types.Resources     `json:"resources"` // planet resources left after paying
+}
+
+type defenseService struct {
+	repo         repository.DefenseRepository
+	planetRepo   repository.PlanetRepository
+	buildingRepo repository.BuildingRepository
+	authz        Authorizer
+	logger       *zap.Logger
+}
+
+func NewDefenseService(repo repository.DefenseRepository, planetRepo repository.PlanetRepository, buildingRepo repository.BuildingRepository, authz Authorizer, logger *zap.Logger) DefenseService {
+	return &defenseService{
+		repo:         repo,
+		planetRepo:   planetRepo,
+		buildingRepo: buildingRepo,
+		authz:        authz,
+		logger:       logger,
+	}
+}
+
+// AddDefense builds a level 1 defense of the given kind. Without an explicit
+// position it takes the first free slot.
+func (s *defenseService) AddDefense(ctx context.Context, planetID uuid.UUID, kind string, position *int) (types.DefenseSystem, error) {
+	s.logger.Debug("adding defense",
+		zap.String("planet_id", planetID.String()),
+		zap.String("kind", kind))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return types.DefenseSystem{}, err
+	}
+
+	spec, ok := defenseKinds[kind]
+	if !ok {
+		return types.DefenseSystem{}, apperrors.NewInvalidInputError(
+			fmt.Sprintf("unknown defense kind %q, expected one of %s", kind, strings.Join(DefenseKinds(), ", ")), nil)
+	}
+
+	if _, err := s.planetRepo.GetByID(ctx, planetID); err != nil {
+		return types.DefenseSystem{}, err
+	}
+
+	existing, err := s.repo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return types.DefenseSystem{}, err
+	}
+
+	slot, err := pickSlot(existing, position)
+	if err != nil {
+		return types.DefenseSystem{}, err
+	}
+
+	upgradeCost, err := json.Marshal(spec.UpgradeCost)
+	if err != nil {
+		return types.DefenseSystem{}, apperrors.NewInternalError("failed to encode upgrade cost", err)
+	}
+
+	defense, err := s.repo.Create(ctx, generated.CreateDefenseSystemParams{
+		PlanetID:    planetID,
+		Kind:        kind,
+		Name:        spec.Name,
+		Damage:      int32(spec.Damage),
+		Range:       int32(spec.Range),
+		FireRate:    spec.FireRate,
+		DamageType:  spec.DamageType,
+		UpgradeCost: upgradeCost,
+		Position:    int32(slot),
+	})
+	if err != nil {
+		return types.DefenseSystem{}, err
+	}
+
+	return s.convertDefense(defense)
+}
+
+// pickSlot validates the requested slot, or finds the first free one.
+func pickSlot(existing []generated.DefenseSystem, requested *int) (int, error) {
+	taken := make(map[int]bool, len(existing))
+	for _, d := range existing {
+		taken[int(d.Position)] = true
+	}
+
+	if requested != nil {
+		if *requested < 0 || *requested >= MaxDefenseSlots {
+			return 0, apperrors.NewInvalidInputError(
+				fmt.Sprintf("position must be between 0 and %d", MaxDefenseSlots-1), nil)
+		}
+		if taken[*requested] {
+			return 0, apperrors.NewAlreadyExistsError("defense", "a defense already occupies this position")
+		}
+		return *requested, nil
+	}
+
+	for slot := range MaxDefenseSlots {
+		if !taken[slot] {
+			return slot, nil
+		}
+	}
+	return 0, apperrors.NewInvalidInputError("all defense slots on this planet are taken", nil)
+}
+
+func (s *defenseService) ListDefenses(ctx context.Context, planetID uuid.UUID) ([]types.DefenseSystem, error) {
+	s.logger.Debug("retrieving defenses", zap.String("planet_id", planetID.String()))
+
+	if _, err := s.planetRepo.GetByID(ctx, planetID); err != nil {
+		return nil, err
+	}
+
+	defenses, err := s.repo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.convertDefenses(defenses)
+}
+
+func (s *defenseService) RemoveDefense(ctx context.Context, planetID, defenseID uuid.UUID) error {
+	s.logger.Debug("removing defense",
+		zap.String("planet_id", planetID.String()),
+		zap.String("defense_id", defenseID.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, planetID, defenseID)
+}
+
+func (s *defenseService) MoveDefense(ctx context.Context, planetID, defenseID uuid.UUID, position int) ([]types.DefenseSystem, error) {
+	s.logger.Debug("moving defense",
+		zap.String("planet_id", planetID.String()),
+		zap.String("defense_id", defenseID.String()),
+		zap.Int("position", position))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return nil, err
+	}
+
+	if position < 0 || position >= MaxDefenseSlots {
+		return nil, apperrors.NewInvalidInputError(
+			fmt.Sprintf("position must be between 0 and %d", MaxDefenseSlots-1), nil)
+	}
+
+	defenses, err := s.repo.Move(ctx, planetID, defenseID, int32(position))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.convertDefenses(defenses)
+}
+
+// UpgradeDefense spends the defense's upgrade cost from the planet's
+// resources and starts installing the next level. The new stats apply when
+// the scheduler finishes the upgrade.
+func (s *defenseService) UpgradeDefense(ctx context.Context, planetID, defenseID uuid.UUID) (UpgradeDefenseResponse, error) {
+	s.logger.Debug("upgrading defense",
+		zap.String("planet_id", planetID.String()),
+		zap.String("defense_id", defenseID.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return UpgradeDefenseResponse{}, err
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return UpgradeDefenseResponse{}, err
+	}
+
+	upgraded, resources, err := s.repo.Upgrade(ctx, planetID, defenseID,
+		func(planet generated.Planet, d generated.DefenseSystem) (generated.UpdatePlanetResourcesParams, generated.StartDefenseUpgradeParams, error) {
+			return planUpgrade(planet, buildings, d)
+		})
+	if err != nil {
+		return UpgradeDefenseResponse{}, err
+	}
+
+	defense, err := s.convertDefense(upgraded)
+	if err != nil {
+		return UpgradeDefenseResponse{}, err
+	}
+
+	var remaining types.Resources
+	if err := json.Unmarshal(resources, &remaining); err != nil {
+		return UpgradeDefenseResponse{}, apperrors.NewInternalError("failed to decode planet resources", err)
+	}
+
+	return UpgradeDefenseResponse{Defense: defense, Resources: remaining}, nil
+}
+
+// planUpgrade credits the planet's offline production, then prices the next
+// level of d against it.
+func planUpgrade(planet generated.Planet, buildings []generated.PlanetBuilding, d generated.DefenseSystem) (pay generated.UpdatePlanetResourcesParams, upgrade generated.StartDefenseUpgradeParams, err error) {
+	kind, ok := defenseKinds[d.Kind]
+	if !ok {
+		return pay, upgrade, apperrors.NewInternalError(fmt.Sprintf("defense has unknown kind %q", d.Kind), nil)
+	}
+	if planet.Status == types.PlanetDestroyed {
+		return pay, upgrade, apperrors.NewInvalidInputError("planet is destroyed and must be rebuilt first", nil)
+	}
+	if d.UpgradeCompletesAt.Valid {
+		return pay, upgrade, apperrors.NewInvalidInputError("defense is already being upgraded", nil)
+	}
+	level := int(d.Level)
+	if level >= MaxDefenseLevel {
+		return pay, upgrade, apperrors.NewInvalidInputError(
+			fmt.Sprintf("defense is already at max level %d", MaxDefenseLevel), nil)
+	}
+
+	now := time.Now()
+	planet, err = accrue(planet, buildings, now)
+	if err != nil {
+		return pay, upgrade, apperrors.NewInternalError("failed to accrue planet resources", err)
+	}
+
+	var stock types.Resources
+	if err := json.Unmarshal(planet.Resources, &stock); err != nil {
+		return pay, upgrade, apperrors.NewInternalError("failed to decode planet resources", err)
+	}
+
+	cost := kind.statsAt(level).UpgradeCost
+	if !stock.Covers(cost) {
+		return pay, upgrade, apperrors.NewInvalidInputError(
+			fmt.Sprintf("insufficient resources: upgrade costs %d minerals, %d energy and %d tech parts",
+				cost.Minerals, cost.Energy, cost.TechParts), nil)
+	}
+
+	resources, err := json.Marshal(stock.Sub(cost))
+	if err != nil {
+		return pay, upgrade, apperrors.NewInternalError("failed to encode planet resources", err)
+	}
+
+	pay = generated.UpdatePlanetResourcesParams{
+		Resources: resources,
+		Health:    planet.Health,
+		UpdatedAt: planet.UpdatedAt,
+	}
+	upgrade = generated.StartDefenseUpgradeParams{
+		UpgradeStartedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+		UpgradeCompletesAt: pgtype.Timestamptz{Time: now.Add(upgradeTimeAt(level + 1)), Valid: true},
+	}
+	return pay, upgrade, nil
+}
+
+// finishDefenseUpgrade applies the stats of the level job upgrades to. An
+// upgrade already applied, or a defense removed meanwhile, is a no-op.
+func finishDefenseUpgrade(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	d, err := q.GetDefenseSystem(ctx, generated.GetDefenseSystemParams{
+		ID:       job.TargetID,
+		PlanetID: job.PlanetID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	kind, ok := defenseKinds[d.Kind]
+	if !ok {
+		return fmt.Errorf("defense has unknown kind %q", d.Kind)
+	}
+	level := int(job.TargetLevel)
+	if level < 2 || level > MaxDefenseLevel {
+		return fmt.Errorf("invalid defense level %d", level)
+	}
+
+	stats := kind.statsAt(level)
+	upgradeCost, err := json.Marshal(stats.UpgradeCost)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.FinishDefenseUpgrade(ctx, generated.FinishDefenseUpgradeParams{
+		ID:          d.ID,
+		Level:       int32(level),
+		Damage:      int32(stats.Damage),
+		Range:       int32(stats.Range),
+		FireRate:    stats.FireRate,
+		UpgradeCost: upgradeCost,
+	})
+	return err
+}
+
+// cancelDefenseUpgrade undoes an upgrade the scheduler gave up on: it clears
+// the defense's upgrading state, so it can be upgraded again, and refunds
+// the price to the planet through the ledger. A defense removed meanwhile,
+// or no longer upgrading to job's level, gets no refund.
+func cancelDefenseUpgrade(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	d, err := q.GetDefenseSystemForUpdate(ctx, generated.GetDefenseSystemForUpdateParams{
+		ID:       job.TargetID,
+		PlanetID: job.PlanetID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	kind, ok := defenseKinds[d.Kind]
+	if !ok {
+		return fmt.Errorf("defense has unknown kind %q", d.Kind)
+	}
+	level := int(job.TargetLevel)
+	if level < 2 || level > MaxDefenseLevel {
+		return fmt.Errorf("invalid defense level %d", level)
+	}
+
+	cancelled, err := q.CancelDefenseUpgrade(ctx, generated.CancelDefenseUpgradeParams{
+		ID:    d.ID,
+		Level: job.TargetLevel,
+	})
+	if err != nil || cancelled == 0 {
+		return err
+	}
+
+	// planUpgrade charged the upgrade cost of the level below the target.
+	refund, err := json.Marshal(kind.statsAt(level - 1).UpgradeCost)
+	if err != nil {
+		return err
+	}
+	if _, err := q.CreateLedgerEntry(ctx, generated.CreateLedgerEntryParams{
+		PlanetID:    job.PlanetID,
+		Source:      repository.LedgerUpgradeRefund,
+		ReferenceID: job.ID,
+		Base:        refund,
+		Amount:      refund,
+	}); err != nil {
+		return err
+	}
+	return q.CreditPlanetResources(ctx, generated.CreditPlanetResourcesParams{
+		Amount: refund,
+		ID:     job.PlanetID,
+	})
+}
+
+func (s *defenseService) convertDefenses(defenses []generated.DefenseSystem) ([]types.DefenseSystem, error) {
+	result := make([]types.DefenseSystem, len(defenses))
+	for i, d := range defenses {
+		var err error
+		if result[i], err = s.convertDefense(d); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Convert generated models to the shared defense type
+func (s *defenseService) convertDefense(d generated.DefenseSystem) (types.DefenseSystem, error) {
+	result, err := convertDefense(d)
+	if err != nil {
+		s.logger.Error("failed to decode defense", zap.String("defense_id", d.ID.String()), zap.Error(err))
+		return types.DefenseSystem{}, apperrors.NewInternalError("failed to decode defense", err)
+	}
+	return result, nil
+}
+
+func convertDefense(d generated.DefenseSystem) (types.DefenseSystem, error) {
+	result := types.DefenseSystem{
+		ID:         d.ID.String(),
+		PlanetID:   d.PlanetID.String(),
+		Kind:       d.Kind,
+		Name:       d.Name,
+		Damage:     int(d.Damage),
+		Range:      int(d.Range),
+		FireRate:   d.FireRate,
+		DamageType: d.DamageType,
+		Level:      int(d.Level),
+		Position:   int(d.Position),
+	}
+	if d.UpgradeCompletesAt.Valid {
+		started, completes := d.UpgradeStartedAt.Time, d.UpgradeCompletesAt.Time
+		result.UpgradeStartedAt = &started
+		result.UpgradeCompletesAt = &completes
+	}
+	if err := json.Unmarshal(d.UpgradeCost, &result.UpgradeCost); err != nil {
+		return types.DefenseSystem{}, err
+	}
+	return result, nil
+}
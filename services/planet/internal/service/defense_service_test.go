@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	stock, err := json.Marshal(types.Resources{Minerals: 500, Energy: 500, TechParts: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	planet := generated.Planet{Resources: stock, Health: 100, MaxHealth: 100, Status: types.PlanetActive, UpdatedAt: now}
+	defense := generated.DefenseSystem{Kind: "autocannon", Level: 1}
+
+	destroyed := planet
+	destroyed.Status = types.PlanetDestroyed
+	poor := planet
+	poor.Resources = []byte(`{"minerals":10}`)
+	upgrading := defense
+	upgrading.UpgradeCompletesAt = now
+	maxed := defense
+	maxed.Level = int32(MaxDefenseLevel)
+
+	tests := []struct {
+		name    string
+		planet  generated.Planet
+		defense generated.DefenseSystem
+		message string // empty when the upgrade is allowed
+	}{
+		{"ok", planet, defense, ""},
+		{"destroyed planet", destroyed, defense, "planet is destroyed"},
+		{"insufficient resources", poor, defense, "insufficient resources"},
+		{"already upgrading", planet, upgrading, "already being upgraded"},
+		{"max level", planet, maxed, "already at max level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pay, upgrade, err := planUpgrade(tt.planet, nil, tt.defense)
+			if tt.message != "" {
+				var appErr *apperrors.AppError
+				if !errors.As(err, &appErr) || appErr.Code != "INVALID_INPUT" || !strings.Contains(appErr.Message, tt.message) {
+					t.Fatalf("err = %v, want invalid input mentioning %q", err, tt.message)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var left types.Resources
+			if err := json.Unmarshal(pay.Resources, &left); err != nil {
+				t.Fatal(err)
+			}
+			cost := defenseKinds["autocannon"].statsAt(1).UpgradeCost
+			if want := (types.Resources{Minerals: 500, Energy: 500, TechParts: 50}).Sub(cost); left != want {
+				t.Errorf("resources left = %+v, want %+v", left, want)
+			}
+			if !upgrade.UpgradeCompletesAt.Valid || !upgrade.UpgradeCompletesAt.Time.After(upgrade.UpgradeStartedAt.Time) {
+				t.Errorf("upgrade = %+v, want it to complete after it starts", upgrade)
+			}
+		})
+	}
+}
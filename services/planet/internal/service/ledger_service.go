@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// Page sizes for the resource ledger.
+const (
+	DefaultLedgerPageSize = 50
+	MaxLedgerPageSize     = 200
+)
+
+// LedgerFilter pages through a planet's ledger. Cursor is the NextCursor of
+// the previous page.
+type LedgerFilter struct {
+	Cursor string
+	Limit  int
+}
+
+type LedgerPage struct {
+	Entries    []types.LedgerEntry
+	Limit      int
+	NextCursor string
+}
+
+type LedgerService interface {
+	ListLedger(ctx context.Context, planetID uuid.UUID, filter LedgerFilter) (LedgerPage, error)
+}
+
+type ledgerService struct {
+	repo       repository.LedgerRepository
+	planetRepo repository.PlanetRepository
+	authz      Authorizer
+	logger     *zap.Logger
+}
+
+func NewLedgerService(repo repository.LedgerRepository, planetRepo repository.PlanetRepository, authz Authorizer, logger *zap.Logger) LedgerService {
+	return &ledgerService{
+		repo:       repo,
+		planetRepo: planetRepo,
+		authz:      authz,
+		logger:     logger,
+	}
+}
+
+// ListLedger returns a page of the ledger entries of one of the caller's
+// planets, newest first.
+func (s *ledgerService) ListLedger(ctx context.Context, planetID uuid.UUID, filter LedgerFilter) (LedgerPage, error) {
+	s.logger.Debug("retrieving ledger", zap.String("planet_id", planetID.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return LedgerPage{}, err
+	}
+	if _, err := s.planetRepo.GetByID(ctx, planetID); err != nil {
+		return LedgerPage{}, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLedgerPageSize
+	}
+	limit = min(limit, MaxLedgerPageSize)
+
+	params := generated.ListPlanetLedgerParams{
+		PlanetID: planetID,
+		PageSize: int32(limit + 1), // one extra row tells whether there is a next page
+	}
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return LedgerPage{}, apperrors.NewInvalidInputError("invalid cursor", err)
+		}
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	rows, err := s.repo.List(ctx, params)
+	if err != nil {
+		return LedgerPage{}, err
+	}
+
+	page := LedgerPage{Limit: limit}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	page.Entries = make([]types.LedgerEntry, len(rows))
+	for i, row := range rows {
+		if page.Entries[i], err = convertLedgerEntry(row); err != nil {
+			s.logger.Error("failed to decode ledger entry", zap.String("entry_id", row.ID.String()), zap.Error(err))
+			return LedgerPage{}, apperrors.NewInternalError("failed to decode ledger entry", err)
+		}
+	}
+	return page, nil
+}
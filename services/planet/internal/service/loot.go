@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// lootBonusPercent is how much extra battle loot the planet's research
+// recovers at t, in percent.
+func lootBonusPercent(research []generated.PlanetResearch, t time.Time) int {
+	bonus := 0
+	for _, r := range research {
+		bonus += researchTechs[r.Tech].lootBonus * researchLevel(r, t)
+	}
+	return bonus
+}
+
+// lootCredit prices the loot dropped in a battle with the planet's bonus.
+// No loot leaves the credit without an amount, so nothing is credited.
+func lootCredit(base types.Resources, bonusPercent int) (generated.CreateLedgerEntryParams, error) {
+	if base == (types.Resources{}) {
+		return generated.CreateLedgerEntryParams{}, nil
+	}
+
+	baseJSON, err := json.Marshal(base)
+	if err != nil {
+		return generated.CreateLedgerEntryParams{}, err
+	}
+	amountJSON, err := json.Marshal(scaleResources(base, 1+float64(bonusPercent)/100))
+	if err != nil {
+		return generated.CreateLedgerEntryParams{}, err
+	}
+
+	return generated.CreateLedgerEntryParams{
+		Base:         baseJSON,
+		BonusPercent: int32(bonusPercent),
+		Amount:       amountJSON,
+	}, nil
+}
+
+func convertLedgerEntry(e generated.ResourceLedger) (types.LedgerEntry, error) {
+	entry := types.LedgerEntry{
+		ID:           e.ID.String(),
+		PlanetID:     e.PlanetID.String(),
+		Source:       e.Source,
+		ReferenceID:  e.ReferenceID.String(),
+		BonusPercent: int(e.BonusPercent),
+		CreatedAt:    e.CreatedAt.Time,
+	}
+	if err := json.Unmarshal(e.Base, &entry.Base); err != nil {
+		return types.LedgerEntry{}, err
+	}
+	if err := json.Unmarshal(e.Amount, &entry.Amount); err != nil {
+		return types.LedgerEntry{}, err
+	}
+	return entry, nil
+}
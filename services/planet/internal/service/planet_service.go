@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/auth"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type PlanetService interface {
+	GetPlanet(ctx context.Context, id uuid.UUID, includeDefenses bool) (types.Planet, error)
+	GetPlayerPlanet(ctx context.Context, playerID uuid.UUID, includeDefenses bool) (types.Planet, error)
+	UpdatePlanet(ctx context.Context, id uuid.UUID, update PlanetUpdate) (types.Planet, error)
+	DeletePlanet(ctx context.Context, id uuid.UUID) error
+	RepairPlanet(ctx context.Context, id uuid.UUID, hp *int) (types.Planet, error)
+	RebuildPlanet(ctx context.Context, id uuid.UUID) (types.Planet, error)
+}
+
+// PlanetUpdate is a partial change to a planet's state; nil fields are left
+// as they are. Owners may only rename their planet; the other fields are
+// game state that only admins and service callers may set.
+type PlanetUpdate struct {
+	Name        *string
+	HP          *int
+	Shields     *int
+	CurrentWave *int
+	Resources   *types.Resources
+}
+
+// changesState reports whether u sets anything besides the name.
+func (u PlanetUpdate) changesState() bool {
+	return u.HP != nil || u.Shields != nil || u.CurrentWave != nil || u.Resources != nil
+}
+
+type planetService struct {
+	repo         repository.PlanetRepository
+	defenseRepo  repository.DefenseRepository
+	buildingRepo repository.BuildingRepository
+	authz        Authorizer
+	logger       *zap.Logger
+}
+
+func NewPlanetService(repo repository.PlanetRepository, defenseRepo repository.DefenseRepository, buildingRepo repository.BuildingRepository, authz Authorizer, logger *zap.Logger) PlanetService {
+	return &planetService{
+		repo:         repo,
+		defenseRepo:  defenseRepo,
+		buildingRepo: buildingRepo,
+		authz:        authz,
+		logger:       logger,
+	}
+}
+
+func (s *planetService) GetPlanet(ctx context.Context, id uuid.UUID, includeDefenses bool) (types.Planet, error) {
+	s.logger.Debug("retrieving planet by ID",
+		zap.String("planet_id", id.String()),
+		zap.Bool("include_defenses", includeDefenses))
+
+	planet, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	return s.buildPlanet(ctx, planet, includeDefenses)
+}
+
+func (s *planetService) GetPlayerPlanet(ctx context.Context, playerID uuid.UUID, includeDefenses bool) (types.Planet, error) {
+	s.logger.Debug("retrieving planet by player ID",
+		zap.String("player_id", playerID.String()),
+		zap.Bool("include_defenses", includeDefenses))
+
+	planet, err := s.repo.GetByPlayerID(ctx, playerID)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	return s.buildPlanet(ctx, planet, includeDefenses)
+}
+
+func (s *planetService) UpdatePlanet(ctx context.Context, id uuid.UUID, update PlanetUpdate) (types.Planet, error) {
+	s.logger.Debug("updating planet", zap.String("planet_id", id.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, id); err != nil {
+		return types.Planet{}, err
+	}
+	if caller, _ := auth.CallerFrom(ctx); update.changesState() && !caller.IsAdmin() && !caller.IsService() {
+		return types.Planet{}, apperrors.NewForbiddenError("only admins can change a planet's hp, shields, current wave or resources")
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, id)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	planet, err := s.repo.Update(ctx, id, func(current generated.Planet) (generated.UpdatePlanetStateParams, error) {
+		current, err := accrue(current, buildings, time.Now())
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+		return applyPlanetUpdate(current, update)
+	})
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	return s.buildPlanet(ctx, planet, false)
+}
+
+func (s *planetService) DeletePlanet(ctx context.Context, id uuid.UUID) error {
+	s.logger.Debug("deleting planet", zap.String("planet_id", id.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+// RepairPlanet spends resources to restore hp HP, or all missing HP when hp
+// is nil. Destroyed planets have to be rebuilt instead.
+func (s *planetService) RepairPlanet(ctx context.Context, id uuid.UUID, hp *int) (types.Planet, error) {
+	s.logger.Debug("repairing planet", zap.String("planet_id", id.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, id); err != nil {
+		return types.Planet{}, err
+	}
+
+	if hp != nil && *hp <= 0 {
+		return types.Planet{}, apperrors.NewInvalidInputError("hp to repair must be positive", nil)
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, id)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	planet, err := s.repo.Update(ctx, id, func(current generated.Planet) (generated.UpdatePlanetStateParams, error) {
+		current, err := accrue(current, buildings, time.Now())
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+		if current.Status == types.PlanetDestroyed {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInvalidInputError("planet is destroyed and must be rebuilt first", nil)
+		}
+
+		missing := int(current.MaxHealth - current.Health)
+		if missing == 0 {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInvalidInputError("planet is already at full health", nil)
+		}
+		amount := missing
+		if hp != nil {
+			amount = min(*hp, missing)
+		}
+
+		cost := scaleResources(repairCostPerHP, float64(amount))
+		resources, err := charge(current, cost, fmt.Sprintf("repairing %d hp", amount))
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, err
+		}
+
+		repaired := int(current.Health) + amount
+		return applyPlanetUpdate(current, PlanetUpdate{HP: &repaired, Resources: &resources})
+	})
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	return s.buildPlanet(ctx, planet, false)
+}
+
+// RebuildPlanet spends rebuildCost to bring a destroyed planet back.
+func (s *planetService) RebuildPlanet(ctx context.Context, id uuid.UUID) (types.Planet, error) {
+	s.logger.Debug("rebuilding planet", zap.String("planet_id", id.String()))
+
+	if err := s.authz.AuthorizePlanet(ctx, id); err != nil {
+		return types.Planet{}, err
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, id)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	planet, err := s.repo.Rebuild(ctx, id, func(current generated.Planet) (generated.UpdatePlanetStateParams, error) {
+		current, err := accrue(current, buildings, time.Now())
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+		if current.Status != types.PlanetDestroyed {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInvalidInputError("only destroyed planets can be rebuilt", nil)
+		}
+
+		resources, err := charge(current, rebuildCost, "rebuilding")
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, err
+		}
+		encoded, err := json.Marshal(resources)
+		if err != nil {
+			return generated.UpdatePlanetStateParams{}, apperrors.NewInternalError("failed to encode planet resources", err)
+		}
+
+		return generated.UpdatePlanetStateParams{
+			Resources: encoded,
+			Health:    max(int32(math.Round(float64(current.MaxHealth)*rebuildHealthRatio)), 1),
+			UpdatedAt: current.UpdatedAt,
+		}, nil
+	})
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	s.logger.Info("planet rebuilt", zap.String("planet_id", id.String()))
+	return s.buildPlanet(ctx, planet, false)
+}
+
+// charge returns planet's resources minus cost, or an error naming what the
+// payment was for if the planet can't afford it.
+func charge(planet generated.Planet, cost types.Resources, what string) (types.Resources, error) {
+	var stock types.Resources
+	if err := json.Unmarshal(planet.Resources, &stock); err != nil {
+		return types.Resources{}, apperrors.NewInternalError("failed to decode planet resources", err)
+	}
+	if !stock.Covers(cost) {
+		return types.Resources{}, apperrors.NewInvalidInputError(
+			fmt.Sprintf("insufficient resources: %s costs %d minerals, %d energy and %d tech parts",
+				what, cost.Minerals, cost.Energy, cost.TechParts), nil)
+	}
+	return stock.Sub(cost), nil
+}
+
+// applyPlanetUpdate merges update into the current row and checks the result
+// against the planet's limits. HP 0 destroys the planet.
+func applyPlanetUpdate(current generated.Planet, update PlanetUpdate) (generated.UpdatePlanetStateParams, error) {
+	params := generated.UpdatePlanetStateParams{
+		Name:         current.Name,
+		Resources:    current.Resources,
+		DefenseLevel: current.DefenseLevel,
+		CurrentWave:  current.CurrentWave,
+		Health:       current.Health,
+		Shields:      current.Shields,
+		UpdatedAt:    current.UpdatedAt,
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return params, apperrors.NewInvalidInputError("planet name cannot be empty", nil)
+		}
+		params.Name = name
+	}
+	if update.HP != nil {
+		if *update.HP < 0 || *update.HP > int(current.MaxHealth) {
+			return params, apperrors.NewInvalidInputError(
+				fmt.Sprintf("hp must be between 0 and %d", current.MaxHealth), nil)
+		}
+		params.Health = int32(*update.HP)
+	}
+	if update.Shields != nil {
+		if *update.Shields < 0 || *update.Shields > int(current.MaxShields) {
+			return params, apperrors.NewInvalidInputError(
+				fmt.Sprintf("shields must be between 0 and %d", current.MaxShields), nil)
+		}
+		params.Shields = int32(*update.Shields)
+	}
+	if update.CurrentWave != nil {
+		if *update.CurrentWave < 0 {
+			return params, apperrors.NewInvalidInputError("current wave cannot be negative", nil)
+		}
+		params.CurrentWave = int32(*update.CurrentWave)
+	}
+	if update.Resources != nil {
+		if !update.Resources.Covers(types.Resources{}) {
+			return params, apperrors.NewInvalidInputError("resources cannot be negative", nil)
+		}
+		resources, err := json.Marshal(update.Resources)
+		if err != nil {
+			return params, apperrors.NewInternalError("failed to encode planet resources", err)
+		}
+		params.Resources = resources
+	}
+
+	if err := settlePlanetStatus(current, &params); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// buildPlanet converts a planets row, with production accrued up to now, and
+// optionally attaches its defenses. The accrual isn't stored; the next write
+// to the planet credits the same ticks.
+func (s *planetService) buildPlanet(ctx context.Context, planet generated.Planet, includeDefenses bool) (types.Planet, error) {
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, planet.ID)
+	if err != nil {
+		return types.Planet{}, err
+	}
+
+	planet, err = accrue(planet, buildings, time.Now())
+	if err != nil {
+		s.logger.Error("failed to accrue planet resources", zap.String("planet_id", planet.ID.String()), zap.Error(err))
+		return types.Planet{}, apperrors.NewInternalError("failed to accrue planet resources", err)
+	}
+
+	response, err := convertPlanet(planet)
+	if err != nil {
+		s.logger.Error("failed to decode planet", zap.String("planet_id", planet.ID.String()), zap.Error(err))
+		return types.Planet{}, apperrors.NewInternalError("failed to decode planet", err)
+	}
+
+	if includeDefenses {
+		defenses, err := s.defenseRepo.ListByPlanetID(ctx, planet.ID)
+		if err != nil {
+			return types.Planet{}, err
+		}
+		for _, d := range defenses {
+			converted, err := convertDefense(d)
+			if err != nil {
+				s.logger.Error("failed to decode defense", zap.String("defense_id", d.ID.String()), zap.Error(err))
+				return types.Planet{}, apperrors.NewInternalError("failed to decode defense", err)
+			}
+			response.Defenses = append(response.Defenses, converted)
+		}
+	}
+
+	return response, nil
+}
+
+// convertPlanet maps a planets row onto the shape clients use. Defenses are
+// stored separately and left for the caller to attach.
+func convertPlanet(p generated.Planet) (types.Planet, error) {
+	result := types.Planet{
+		ID:               p.ID.String(),
+		PlayerID:         p.PlayerID.String(),
+		Name:             p.Name,
+		HP:               int(p.Health),
+		MaxHP:            int(p.MaxHealth),
+		Shields:          int(p.Shields),
+		Status:           p.Status,
+		MaxShields:       int(p.MaxShields),
+		ShieldRegen:      p.ShieldRegen,
+		ShieldRegenDelay: p.ShieldRegenDelay,
+		CurrentWave:      int(p.CurrentWave),
+		LastUpdated:      p.UpdatedAt.Time,
+	}
+	if p.DestroyedAt.Valid {
+		destroyedAt := p.DestroyedAt.Time
+		result.DestroyedAt = &destroyedAt
+	}
+	if err := json.Unmarshal(p.Resources, &result.Resources); err != nil {
+		return types.Planet{}, err
+	}
+	return result, nil
+}
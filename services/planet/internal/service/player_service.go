@@ -2,14 +2,18 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"go.uber.org/zap"
 
 	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
 	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
 )
 
 type PlayerResponse struct {
@@ -18,21 +22,42 @@ type PlayerResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-type PlanetResponse struct {
-	ID       uuid.UUID `json:"id"`
-	PlayerID uuid.UUID `json:"player_id"`
-	Name     string    `json:"name"`
-}
-
 type CreatePlayerResponse struct {
 	Player PlayerResponse `json:"player"`
-	Planet PlanetResponse `json:"planet"`
+	Planet types.Planet   `json:"planet"`
+}
+
+// Page sizes for the player list.
+const (
+	DefaultPlayerPageSize = 20
+	MaxPlayerPageSize     = 100
+)
+
+// Player list orders.
+const (
+	PlayerSortNewest = "newest"
+	PlayerSortOldest = "oldest"
+)
+
+// PlayerFilter narrows and orders the player list. Cursor is the
+// NextCursor of the previous page, listed in the same order.
+type PlayerFilter struct {
+	UsernamePrefix string
+	Sort           string
+	Cursor         string
+	Limit          int
+}
+
+type PlayerPage struct {
+	Players    []PlayerResponse
+	Limit      int
+	NextCursor string
 }
 
 type PlayerService interface {
-	GetAllPlayers(ctx context.Context) ([]PlayerResponse, error)
+	ListPlayers(ctx context.Context, filter PlayerFilter) (PlayerPage, error)
 	GetPlayerByID(ctx context.Context, id uuid.UUID) (PlayerResponse, error)
-	CreatePlayerWithPlanet(ctx context.Context, username, planetName string) (CreatePlayerResponse, error)
+	CreatePlayerWithPlanet(ctx context.Context, username, password, planetName string) (CreatePlayerResponse, error)
 }
 
 type playerService struct {
@@ -47,24 +72,36 @@ func NewPlayerService(repo repository.PlayerRepository, logger *zap.Logger) Play
 	}
 }
 
-func (s *playerService) CreatePlayerWithPlanet(ctx context.Context, username, planetName string) (CreatePlayerResponse, error) {
+func (s *playerService) CreatePlayerWithPlanet(ctx context.Context, username, password, planetName string) (CreatePlayerResponse, error) {
 	s.logger.Debug("creating player with planet",
 		zap.String("username", username),
 		zap.String("planet_name", planetName))
 
 	// TODO: validate and sanitize inputs
 
-	normalizedUsername := s.normalizeUsername(username)
+	normalizedUsername := normalizeUsername(username)
 	normalizedPlanetName := strings.TrimSpace(planetName)
 
-	player, planet, err := s.repo.CreatePlayerWithPlanet(ctx, normalizedUsername, normalizedPlanetName)
+	passwordHash, err := hashPassword(password)
+	if err != nil {
+		s.logger.Error("failed to hash password", zap.Error(err))
+		return CreatePlayerResponse{}, apperrors.NewInternalError("failed to hash password", err)
+	}
+
+	player, planet, err := s.repo.CreatePlayerWithPlanet(ctx, normalizedUsername, passwordHash, normalizedPlanetName)
 	if err != nil {
 		return CreatePlayerResponse{}, err
 	}
 
+	converted, err := convertPlanet(planet)
+	if err != nil {
+		s.logger.Error("failed to decode planet", zap.String("planet_id", planet.ID.String()), zap.Error(err))
+		return CreatePlayerResponse{}, apperrors.NewInternalError("failed to decode planet", err)
+	}
+
 	response := CreatePlayerResponse{
 		Player: s.convertPlayerToResponse(player),
-		Planet: s.convertPlanetToResponse(planet),
+		Planet: converted,
 	}
 
 	s.logger.Info("successfully created player with planet",
@@ -75,23 +112,65 @@ func (s *playerService) CreatePlayerWithPlanet(ctx context.Context, username, pl
 	return response, nil
 }
 
-func (s *playerService) GetAllPlayers(ctx context.Context) ([]PlayerResponse, error) {
-	s.logger.Debug("retrieving all players")
+// ListPlayers returns a page of players, newest first unless the filter
+// asks for oldest first.
+func (s *playerService) ListPlayers(ctx context.Context, filter PlayerFilter) (PlayerPage, error) {
+	s.logger.Debug("listing players")
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultPlayerPageSize
+	}
+	limit = min(limit, MaxPlayerPageSize)
+
+	var oldestFirst bool
+	switch filter.Sort {
+	case "", PlayerSortNewest:
+	case PlayerSortOldest:
+		oldestFirst = true
+	default:
+		return PlayerPage{}, apperrors.NewInvalidInputError(
+			fmt.Sprintf("unknown sort %q, expected %s or %s", filter.Sort, PlayerSortNewest, PlayerSortOldest), nil)
+	}
+
+	params := generated.ListPlayersParams{
+		PageSize: int32(limit + 1), // one extra row tells whether there is a next page
+	}
+	if prefix := normalizeUsername(filter.UsernamePrefix); prefix != "" {
+		params.UsernamePrefix = pgtype.Text{String: likeEscaper.Replace(prefix), Valid: true}
+	}
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return PlayerPage{}, apperrors.NewInvalidInputError("invalid cursor", err)
+		}
+		params.AfterCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: id, Valid: true}
+	}
 
-	players, err := s.repo.GetPlayers(ctx)
+	players, err := s.repo.List(ctx, params, oldestFirst)
 	if err != nil {
-		return nil, err
+		return PlayerPage{}, err
 	}
 
-	responses := make([]PlayerResponse, len(players))
+	page := PlayerPage{Limit: limit}
+	if len(players) > limit {
+		players = players[:limit]
+		last := players[limit-1]
+		page.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+	page.Players = make([]PlayerResponse, len(players))
 	for i, player := range players {
-		responses[i] = s.convertPlayerToResponse(player)
+		page.Players[i] = s.convertPlayerToResponse(player)
 	}
 
-	s.logger.Debug("successfully retrieved all players", zap.Int("count", len(responses)))
-	return responses, nil
+	s.logger.Debug("successfully listed players", zap.Int("count", len(page.Players)))
+	return page, nil
 }
 
+// likeEscaper escapes LIKE wildcards so a search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 func (s *playerService) GetPlayerByID(ctx context.Context, id uuid.UUID) (PlayerResponse, error) {
 	s.logger.Debug("retrieving player by ID", zap.String("player_id", id.String()))
 
@@ -106,7 +185,7 @@ func (s *playerService) GetPlayerByID(ctx context.Context, id uuid.UUID) (Player
 	return response, nil
 }
 
-func (s *playerService) normalizeUsername(username string) string {
+func normalizeUsername(username string) string {
 	return strings.ToLower(strings.TrimSpace(username))
 }
 
@@ -118,11 +197,3 @@ func (s *playerService) convertPlayerToResponse(player generated.Player) PlayerR
 		CreatedAt: player.CreatedAt.Time, // Assuming CreatedAt is sql.NullTime
 	}
 }
-
-func (s *playerService) convertPlanetToResponse(planet generated.Planet) PlanetResponse {
-	return PlanetResponse{
-		ID:       planet.ID,
-		PlayerID: planet.PlayerID,
-		Name:     planet.Name,
-	}
-}
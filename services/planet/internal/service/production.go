@@ -0,0 +1,129 @@
+package service
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// ProductionTick is the interval planets produce resources in. Only whole
+// ticks are credited; the remainder carries over to the next touch.
+const ProductionTick = time.Minute
+
+// Production is what a planet makes per ProductionTick and how much of each
+// resource it can store. Production stops at the cap; resources gained
+// another way (loot, refunds) may exceed it.
+type Production struct {
+	Rate     types.Resources `json:"rate"`
+	Capacity types.Resources `json:"capacity"`
+}
+
+// hpRegenPerTick is the HP a planet repairs on its own every
+// ProductionTick. Destroyed planets don't regenerate; they must be rebuilt.
+const hpRegenPerTick = 1
+
+// baseProduction is what a planet makes with no buildings.
+var baseProduction = Production{
+	Rate:     types.Resources{Minerals: 2, Energy: 1},
+	Capacity: types.Resources{Minerals: 5000, Energy: 3000, TechParts: 500},
+}
+
+// planetProduction returns the production of a planet with buildings as
+// they stand at t.
+func planetProduction(buildings []generated.PlanetBuilding, t time.Time) Production {
+	prod := baseProduction
+	for _, b := range buildings {
+		if kind, ok := buildingKinds[b.Kind]; ok {
+			prod.Rate = prod.Rate.Add(kind.productionAt(buildingLevel(b, t)))
+		}
+	}
+	return prod
+}
+
+// buildingLevel is b's level at t, counting a construction finished by then
+// even if it hasn't been recorded yet.
+func buildingLevel(b generated.PlanetBuilding, t time.Time) int {
+	level := int(b.Level)
+	if b.BuildCompletesAt.Valid && !b.BuildCompletesAt.Time.After(t) {
+		level++
+	}
+	return level
+}
+
+// accrue credits the resources planet produced, and the HP it regenerated,
+// between its updated_at and now, and moves updated_at forward by the ticks
+// credited. Accruing the result again at the same instant credits nothing,
+// so the returned row can be shown on reads and written back on updates
+// without double counting.
+//
+// Buildings finishing construction inside the interval raise the rate from
+// their completion time on, so the interval is integrated piece by piece.
+func accrue(planet generated.Planet, buildings []generated.PlanetBuilding, now time.Time) (generated.Planet, error) {
+	if !planet.UpdatedAt.Valid {
+		planet.UpdatedAt = pgtype.Timestamptz{Time: now, Valid: true}
+		return planet, nil
+	}
+
+	start := planet.UpdatedAt.Time
+	ticks := int(now.Sub(start) / ProductionTick)
+	if ticks <= 0 {
+		return planet, nil
+	}
+	end := start.Add(time.Duration(ticks) * ProductionTick)
+
+	bounds := []time.Time{start, end}
+	for _, b := range buildings {
+		if at := b.BuildCompletesAt.Time; b.BuildCompletesAt.Valid && at.After(start) && at.Before(end) {
+			bounds = append(bounds, at)
+		}
+	}
+	slices.SortFunc(bounds, func(a, b time.Time) int { return a.Compare(b) })
+
+	var minerals, energy, techParts float64
+	for i := 1; i < len(bounds); i++ {
+		n := float64(bounds[i].Sub(bounds[i-1])) / float64(ProductionTick)
+		rate := planetProduction(buildings, bounds[i-1]).Rate
+		minerals += float64(rate.Minerals) * n
+		energy += float64(rate.Energy) * n
+		techParts += float64(rate.TechParts) * n
+	}
+
+	var stock types.Resources
+	if err := json.Unmarshal(planet.Resources, &stock); err != nil {
+		return generated.Planet{}, err
+	}
+
+	capacity := planetProduction(buildings, end).Capacity
+	stock = types.Resources{
+		Minerals:  produce(stock.Minerals, minerals, capacity.Minerals),
+		Energy:    produce(stock.Energy, energy, capacity.Energy),
+		TechParts: produce(stock.TechParts, techParts, capacity.TechParts),
+	}
+
+	resources, err := json.Marshal(stock)
+	if err != nil {
+		return generated.Planet{}, err
+	}
+
+	planet.Resources = resources
+	if planet.Status != types.PlanetDestroyed && planet.Health < planet.MaxHealth {
+		planet.Health = min(planet.Health+int32(ticks*hpRegenPerTick), planet.MaxHealth)
+	}
+	planet.UpdatedAt.Time = end
+	return planet, nil
+}
+
+// produce adds earned to have without pushing it past capacity. A stock
+// already above capacity is left alone.
+func produce(have int, earned float64, capacity int) int {
+	if have >= capacity {
+		return have
+	}
+	return min(have+int(math.Floor(earned)), capacity)
+}
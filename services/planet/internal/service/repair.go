@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// repairCostPerHP is the price of restoring one HP on an active planet.
+var repairCostPerHP = types.Resources{Minerals: 2, Energy: 1}
+
+// rebuildCost is the price of bringing a destroyed planet back. Production
+// keeps running while a planet is destroyed, so it can always be saved up.
+// A rebuilt planet comes back with rebuildHealthRatio of its max HP and no
+// shields.
+var rebuildCost = types.Resources{Minerals: 500, Energy: 300, TechParts: 20}
+
+const rebuildHealthRatio = 0.25
+
+// settlePlanetStatus sets next.Status from its HP and checks that current
+// may move to it: a planet at 0 HP is destroyed, and a destroyed planet
+// stays destroyed until it is rebuilt.
+func settlePlanetStatus(current generated.Planet, next *generated.UpdatePlanetStateParams) error {
+	if current.Status == types.PlanetDestroyed {
+		if next.Health > 0 {
+			return apperrors.NewInvalidInputError("planet is destroyed and must be rebuilt first", nil)
+		}
+		next.Status = types.PlanetDestroyed
+		return nil
+	}
+
+	next.Status = types.PlanetActive
+	if next.Health <= 0 {
+		next.Health = 0
+		next.Status = types.PlanetDestroyed
+	}
+	return nil
+}
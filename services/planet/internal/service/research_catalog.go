@@ -0,0 +1,78 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// MaxResearchLevel is the highest level a tech can be researched to.
+const MaxResearchLevel = 5
+
+// Each level multiplies a tech's cost and research time by these factors.
+const (
+	researchCostGrowth = 2.0
+	researchTimeGrowth = 1.8
+)
+
+// minShieldRegenDelay is as short as research can make a planet's shield
+// regeneration delay, in seconds.
+const minShieldRegenDelay = 0.5
+
+// ResearchTech describes a tech at level 1.
+type ResearchTech struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Cost         types.Resources `json:"cost"`
+	ResearchTime time.Duration   `json:"research_time"`
+
+	shields   shieldUpgrade // applied to the planet for every level finished
+	lootBonus int           // extra battle loot per level, in percent
+}
+
+// shieldUpgrade raises a planet's shield parameters.
+type shieldUpgrade struct {
+	maxShields     int
+	regen          float64 // shield points per second
+	delayReduction float64 // seconds
+}
+
+var researchTechs = map[string]ResearchTech{
+	"shield_capacity": {
+		Name: "Shield Capacity", Description: "raises the planet's maximum shields",
+		Cost: types.Resources{Minerals: 100, Energy: 200, TechParts: 20}, ResearchTime: 5 * time.Minute,
+		shields: shieldUpgrade{maxShields: 25},
+	},
+	"shield_regen": {
+		Name: "Shield Regeneration", Description: "speeds up shield recharge between hits",
+		Cost: types.Resources{Minerals: 80, Energy: 250, TechParts: 25}, ResearchTime: 5 * time.Minute,
+		shields: shieldUpgrade{regen: 0.5, delayReduction: 0.5},
+	},
+	"salvage": {
+		Name: "Salvage", Description: "recovers more loot from destroyed aliens",
+		Cost: types.Resources{Minerals: 150, Energy: 100, TechParts: 30}, ResearchTime: 8 * time.Minute,
+		lootBonus: 10,
+	},
+}
+
+// costAt returns the price of researching level.
+func (t ResearchTech) costAt(level int) types.Resources {
+	return scaleResources(t.Cost, math.Pow(researchCostGrowth, float64(level-1)))
+}
+
+// researchTimeAt returns how long researching level takes.
+func (t ResearchTech) researchTimeAt(level int) time.Duration {
+	return time.Duration(float64(t.ResearchTime) * math.Pow(researchTimeGrowth, float64(level-1))).Round(time.Second)
+}
+
+// ResearchTechs returns the names of all researchable techs.
+func ResearchTechs() []string {
+	techs := make([]string, 0, len(researchTechs))
+	for t := range researchTechs {
+		techs = append(techs, t)
+	}
+	sort.Strings(techs)
+	return techs
+}
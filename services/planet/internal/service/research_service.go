@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type ResearchService interface {
+	ListResearch(ctx context.Context, planetID uuid.UUID) ([]types.Research, error)
+	QueueResearch(ctx context.Context, planetID uuid.UUID, tech string) (QueueResearchResponse, error)
+}
+
+type QueueResearchResponse struct {
+	Research  types.Research  `json:"research"`
+	Resources types.Resources `json:"resources"` // planet resources left after paying
+}
+
+type researchService struct {
+	repo         repository.ResearchRepository
+	planetRepo   repository.PlanetRepository
+	buildingRepo repository.BuildingRepository
+	authz        Authorizer
+	logger       *zap.Logger
+}
+
+func NewResearchService(repo repository.ResearchRepository, planetRepo repository.PlanetRepository, buildingRepo repository.BuildingRepository, authz Authorizer, logger *zap.Logger) ResearchService {
+	return &researchService{
+		repo:         repo,
+		planetRepo:   planetRepo,
+		buildingRepo: buildingRepo,
+		authz:        authz,
+		logger:       logger,
+	}
+}
+
+func (s *researchService) ListResearch(ctx context.Context, planetID uuid.UUID) ([]types.Research, error) {
+	s.logger.Debug("retrieving research", zap.String("planet_id", planetID.String()))
+
+	if _, err := s.planetRepo.GetByID(ctx, planetID); err != nil {
+		return nil, err
+	}
+
+	research, err := s.repo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := make([]types.Research, len(research))
+	for i, r := range research {
+		result[i] = convertResearch(r, now)
+	}
+	return result, nil
+}
+
+// QueueResearch pays for the next level of a tech and starts researching it.
+func (s *researchService) QueueResearch(ctx context.Context, planetID uuid.UUID, tech string) (QueueResearchResponse, error) {
+	s.logger.Debug("queueing research",
+		zap.String("planet_id", planetID.String()),
+		zap.String("tech", tech))
+
+	if err := s.authz.AuthorizePlanet(ctx, planetID); err != nil {
+		return QueueResearchResponse{}, err
+	}
+
+	spec, ok := researchTechs[tech]
+	if !ok {
+		return QueueResearchResponse{}, apperrors.NewInvalidInputError(
+			fmt.Sprintf("unknown tech %q, expected one of %s", tech, strings.Join(ResearchTechs(), ", ")), nil)
+	}
+
+	buildings, err := s.buildingRepo.ListByPlanetID(ctx, planetID)
+	if err != nil {
+		return QueueResearchResponse{}, err
+	}
+
+	now := time.Now()
+	queued, resources, err := s.repo.Queue(ctx, planetID, tech, func(planet generated.Planet, r generated.PlanetResearch) (pay generated.UpdatePlanetResourcesParams, research generated.UpsertPlanetResearchParams, shields *generated.UpgradePlanetShieldsParams, err error) {
+		level := researchLevel(r, now)
+		if r.ResearchCompletesAt.Valid && r.ResearchCompletesAt.Time.After(now) {
+			return pay, research, shields, apperrors.NewInvalidInputError(
+				fmt.Sprintf("%s is already being researched", spec.Name), nil)
+		}
+		if level >= MaxResearchLevel {
+			return pay, research, shields, apperrors.NewInvalidInputError(
+				fmt.Sprintf("%s is already at max level %d", spec.Name, MaxResearchLevel), nil)
+		}
+
+		planet, err = accrue(planet, buildings, now)
+		if err != nil {
+			return pay, research, shields, apperrors.NewInternalError("failed to accrue planet resources", err)
+		}
+
+		var stock types.Resources
+		if err := json.Unmarshal(planet.Resources, &stock); err != nil {
+			return pay, research, shields, apperrors.NewInternalError("failed to decode planet resources", err)
+		}
+
+		cost := spec.costAt(level + 1)
+		if !stock.Covers(cost) {
+			return pay, research, shields, apperrors.NewInvalidInputError(
+				fmt.Sprintf("insufficient resources: level %d costs %d minerals, %d energy and %d tech parts",
+					level+1, cost.Minerals, cost.Energy, cost.TechParts), nil)
+		}
+
+		remaining, err := json.Marshal(stock.Sub(cost))
+		if err != nil {
+			return pay, research, shields, apperrors.NewInternalError("failed to encode planet resources", err)
+		}
+
+		pay = generated.UpdatePlanetResourcesParams{
+			Resources: remaining,
+			Health:    planet.Health,
+			UpdatedAt: planet.UpdatedAt,
+		}
+		if level > int(r.Level) {
+			// The last level finished but its job hasn't run; recording it
+			// here is what grants its upgrade.
+			if upgrade, ok := shieldUpgradeFor(planet.ID, tech); ok {
+				shields = &upgrade
+			}
+		}
+		research = generated.UpsertPlanetResearchParams{
+			Level:               int32(level),
+			ResearchStartedAt:   pgtype.Timestamptz{Time: now, Valid: true},
+			ResearchCompletesAt: pgtype.Timestamptz{Time: now.Add(spec.researchTimeAt(level + 1)), Valid: true},
+		}
+		return pay, research, shields, nil
+	})
+	if err != nil {
+		return QueueResearchResponse{}, err
+	}
+
+	var remaining types.Resources
+	if err := json.Unmarshal(resources, &remaining); err != nil {
+		return QueueResearchResponse{}, apperrors.NewInternalError("failed to decode planet resources", err)
+	}
+
+	return QueueResearchResponse{
+		Research:  convertResearch(queued, now),
+		Resources: remaining,
+	}, nil
+}
+
+// researchLevel is r's level at t, counting research finished by then even
+// if it hasn't been recorded yet.
+func researchLevel(r generated.PlanetResearch, t time.Time) int {
+	level := int(r.Level)
+	if r.ResearchCompletesAt.Valid && !r.ResearchCompletesAt.Time.After(t) {
+		level++
+	}
+	return level
+}
+
+// finishResearch records the level job researched and applies the tech's
+// upgrade to the planet. A level already recorded, by QueueResearch ordering
+// the next one, is a no-op: its upgrade was applied then.
+func finishResearch(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	research, err := q.FinishPlanetResearch(ctx, generated.FinishPlanetResearchParams{
+		ID:    job.TargetID,
+		Level: job.TargetLevel,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	upgrade, ok := shieldUpgradeFor(research.PlanetID, research.Tech)
+	if !ok {
+		return nil
+	}
+	return q.UpgradePlanetShields(ctx, upgrade)
+}
+
+// shieldUpgradeFor returns the upgrade one finished level of tech applies to
+// the planet, and false if the tech doesn't touch shields. Whichever of
+// QueueResearch and finishResearch records a level applies its upgrade.
+func shieldUpgradeFor(planetID uuid.UUID, tech string) (generated.UpgradePlanetShieldsParams, bool) {
+	upgrade := researchTechs[tech].shields
+	if upgrade == (shieldUpgrade{}) {
+		return generated.UpgradePlanetShieldsParams{}, false
+	}
+	return generated.UpgradePlanetShieldsParams{
+		ID:              planetID,
+		MaxShieldsBonus: int32(upgrade.maxShields),
+		RegenBonus:      upgrade.regen,
+		DelayReduction:  upgrade.delayReduction,
+		MinRegenDelay:   minShieldRegenDelay,
+	}, true
+}
+
+// convertResearch maps a planet_research row to its state at now.
+func convertResearch(r generated.PlanetResearch, now time.Time) types.Research {
+	result := types.Research{
+		ID:       r.ID.String(),
+		PlanetID: r.PlanetID.String(),
+		Tech:     r.Tech,
+		Level:    researchLevel(r, now),
+	}
+
+	if r.ResearchCompletesAt.Valid && r.ResearchCompletesAt.Time.After(now) {
+		started, completes := r.ResearchStartedAt.Time, r.ResearchCompletesAt.Time
+		result.ResearchStartedAt = &started
+		result.ResearchCompletesAt = &completes
+		if total := completes.Sub(started); total > 0 {
+			result.ResearchProgress = float64(now.Sub(started)) / float64(total)
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/planet/internal/repository"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// DefaultSchedulerInterval is how often the scheduler looks for due jobs
+// when the queue is empty.
+const DefaultSchedulerInterval = time.Second
+
+// Scheduler finishes timed jobs (defense upgrades, construction, research)
+// once they come due. Jobs live in Postgres, so they survive restarts, and
+// any number of replicas can run a Scheduler side by side.
+//
+// A job that keeps failing is abandoned. Only defense upgrades need undoing
+// then: a building or research level counts from its completion time
+// whether or not its job recorded it, but a defense keeps its old stats and
+// stays locked in its upgrade until the job clears it.
+type Scheduler struct {
+	repo       repository.JobRepository
+	handlers   map[string]repository.JobHandler
+	abandoners map[string]repository.JobHandler
+	interval   time.Duration
+	logger     *zap.Logger
+}
+
+func NewScheduler(repo repository.JobRepository, interval time.Duration, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		repo: repo,
+		handlers: map[string]repository.JobHandler{
+			repository.JobUpgradeDefense: finishDefenseUpgrade,
+			repository.JobBuildStructure: finishBuilding,
+			repository.JobResearchTech:   finishResearch,
+		},
+		abandoners: map[string]repository.JobHandler{
+			repository.JobUpgradeDefense: cancelDefenseUpgrade,
+		},
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run polls for due jobs until ctx is cancelled. Each poll drains every job
+// that is due before waiting again.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.drain(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := s.repo.RunNext(ctx, s.handle, s.abandon)
+		if err != nil {
+			s.logger.Error("failed to run job", zap.Error(err))
+			return
+		}
+		if !ran {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) handle(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	handler, ok := s.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+
+	if err := handler(ctx, q, job); err != nil {
+		return err
+	}
+
+	s.logger.Info("finished job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", job.Kind),
+		zap.String("planet_id", job.PlanetID.String()),
+		zap.Int32("level", job.TargetLevel))
+	return nil
+}
+
+func (s *Scheduler) abandon(ctx context.Context, q *generated.Queries, job generated.Job) error {
+	abandoner, ok := s.abandoners[job.Kind]
+	if !ok {
+		return nil
+	}
+
+	if err := abandoner(ctx, q, job); err != nil {
+		return err
+	}
+
+	s.logger.Warn("abandoned job",
+		zap.String("job_id", job.ID.String()),
+		zap.String("kind", job.Kind),
+		zap.String("planet_id", job.PlanetID.String()),
+		zap.Int32("level", job.TargetLevel))
+	return nil
+}
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE defense_systems (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind            TEXT NOT NULL,
+    name            TEXT NOT NULL,
+    damage          INT NOT NULL,
+    range           INT NOT NULL,
+    fire_rate       DOUBLE PRECISION NOT NULL,
+    damage_type     TEXT NOT NULL DEFAULT 'kinetic',
+    level           INT NOT NULL DEFAULT 1,
+    upgrade_cost    JSONB NOT NULL DEFAULT '{}',
+    position        INT NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    -- deferred so two defenses can swap positions inside a transaction
+    CONSTRAINT defense_systems_planet_position_key UNIQUE (planet_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS defense_systems;
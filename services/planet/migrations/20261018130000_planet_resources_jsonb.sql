@@ -0,0 +1,17 @@
+-- +goose Up
+-- split the single resource counter into minerals, energy and tech parts
+ALTER TABLE planets
+    ALTER COLUMN resources DROP DEFAULT,
+    ALTER COLUMN resources TYPE JSONB
+        USING jsonb_build_object('minerals', COALESCE(resources, 0), 'energy', 0, 'tech_parts', 0),
+    ALTER COLUMN resources SET DEFAULT '{"minerals": 0, "energy": 0, "tech_parts": 0}',
+    ALTER COLUMN resources SET NOT NULL;
+
+
+-- +goose Down
+ALTER TABLE planets
+    ALTER COLUMN resources DROP NOT NULL,
+    ALTER COLUMN resources DROP DEFAULT,
+    ALTER COLUMN resources TYPE INT
+        USING (resources->>'minerals')::int,
+    ALTER COLUMN resources SET DEFAULT 0;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- bring planets in line with types.Planet: shields, a max HP and no nullable stats
+UPDATE planets SET defense_level = 1 WHERE defense_level IS NULL;
+UPDATE planets SET current_wave = 0 WHERE current_wave IS NULL;
+UPDATE planets SET health = 100 WHERE health IS NULL;
+
+ALTER TABLE planets
+    ALTER COLUMN defense_level SET NOT NULL,
+    ALTER COLUMN current_wave SET NOT NULL,
+    ALTER COLUMN health SET NOT NULL,
+    ADD COLUMN max_health INT NOT NULL DEFAULT 100,
+    ADD COLUMN shields    INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT planets_health_range CHECK (health >= 0 AND health <= max_health),
+    ADD CONSTRAINT planets_shields_non_negative CHECK (shields >= 0);
+
+
+-- +goose Down
+ALTER TABLE planets
+    DROP CONSTRAINT planets_shields_non_negative,
+    DROP CONSTRAINT planets_health_range,
+    DROP COLUMN shields,
+    DROP COLUMN max_health,
+    ALTER COLUMN health DROP NOT NULL,
+    ALTER COLUMN current_wave DROP NOT NULL,
+    ALTER COLUMN defense_level DROP NOT NULL;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE planet_buildings (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id           UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind                TEXT NOT NULL,
+    -- level in operation; while build_completes_at is set, level + 1 is under construction
+    level               INT NOT NULL DEFAULT 0,
+    build_started_at    TIMESTAMP WITH TIME ZONE,
+    build_completes_at  TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT planet_buildings_planet_kind_key UNIQUE (planet_id, kind),
+    CONSTRAINT planet_buildings_level_non_negative CHECK (level >= 0)
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS planet_buildings;
@@ -0,0 +1,47 @@
+-- +goose Up
+ALTER TABLE defense_systems
+    ADD COLUMN upgrade_started_at   TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN upgrade_completes_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE planet_research (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id               UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    tech                    TEXT NOT NULL,
+    -- level researched; while research_completes_at is set, level + 1 is in progress
+    level                   INT NOT NULL DEFAULT 0,
+    research_started_at     TIMESTAMP WITH TIME ZONE,
+    research_completes_at   TIMESTAMP WITH TIME ZONE,
+    created_at              TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at              TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT planet_research_planet_tech_key UNIQUE (planet_id, tech),
+    CONSTRAINT planet_research_level_non_negative CHECK (level >= 0)
+);
+
+-- timed work finished by the planet-service scheduler
+CREATE TABLE jobs (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind            TEXT NOT NULL,
+    -- the defense, building or research row the job finishes, and the level it reaches
+    target_id       UUID NOT NULL,
+    target_level    INT NOT NULL,
+    run_at          TIMESTAMP WITH TIME ZONE NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'done', 'failed'))
+);
+
+CREATE INDEX jobs_due_idx ON jobs (run_at) WHERE status = 'pending';
+
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS planet_research;
+
+ALTER TABLE defense_systems
+    DROP COLUMN upgrade_completes_at,
+    DROP COLUMN upgrade_started_at;
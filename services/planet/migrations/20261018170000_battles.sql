@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE battles (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id           UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    -- not a foreign key: generated waves aren't stored and stored ones may be deleted
+    wave_id             UUID,
+    wave_number         INT NOT NULL,
+    seed                BIGINT NOT NULL,
+    outcome             TEXT NOT NULL,
+    damage_taken        INT NOT NULL,
+    shields_remaining   INT NOT NULL,
+    hp_remaining        INT NOT NULL,
+    aliens_destroyed    INT NOT NULL,
+    loot                JSONB NOT NULL,
+    events              JSONB NOT NULL,
+    -- planet, wave and alien templates as fought, enough to replay the battle
+    snapshot            JSONB NOT NULL,
+    idempotency_key     TEXT,
+    fought_at           TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CONSTRAINT battles_outcome_check CHECK (outcome IN ('victory', 'defeat', 'stalemate')),
+    CONSTRAINT battles_planet_idempotency_key UNIQUE (planet_id, idempotency_key)
+);
+
+CREATE INDEX battles_planet_created_idx ON battles (planet_id, created_at DESC, id DESC);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS battles;
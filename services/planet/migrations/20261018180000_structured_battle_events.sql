@@ -0,0 +1,24 @@
+-- +goose Up
+-- Battles recorded before events were structured stored them as text lines
+-- like "[tick 12] ...". Keep them as message events.
+UPDATE battles
+SET events = (
+    SELECT COALESCE(jsonb_agg(
+        jsonb_build_object(
+            'tick', COALESCE(substring(line FROM '^\[tick (\d+)\]')::INT, 0),
+            'kind', 'message',
+            'text', regexp_replace(line, '^\[tick \d+\] ', '')
+        ) ORDER BY ord), '[]'::jsonb)
+    FROM jsonb_array_elements_text(events) WITH ORDINALITY AS e(line, ord)
+)
+WHERE jsonb_typeof(events -> 0) = 'string';
+
+
+-- +goose Down
+UPDATE battles
+SET events = (
+    SELECT COALESCE(jsonb_agg(
+        to_jsonb(format('[tick %s] %s', e ->> 'tick', COALESCE(e ->> 'text', e ->> 'kind'))) ORDER BY ord), '[]'::jsonb)
+    FROM jsonb_array_elements(events) WITH ORDINALITY AS x(e, ord)
+)
+WHERE jsonb_typeof(events -> 0) = 'object';
@@ -0,0 +1,26 @@
+-- +goose Up
+ALTER TABLE planets
+    ADD COLUMN max_shields          INT NOT NULL DEFAULT 50,
+    -- shield points regained per second once no hit has landed for shield_regen_delay seconds
+    ADD COLUMN shield_regen         DOUBLE PRECISION NOT NULL DEFAULT 1,
+    ADD COLUMN shield_regen_delay   DOUBLE PRECISION NOT NULL DEFAULT 3;
+
+-- planets patched above the new default keep their shields
+UPDATE planets SET max_shields = shields WHERE shields > max_shields;
+
+ALTER TABLE planets
+    DROP CONSTRAINT planets_shields_non_negative,
+    ADD CONSTRAINT planets_shields_range CHECK (shields >= 0 AND shields <= max_shields),
+    ADD CONSTRAINT planets_shield_regen_non_negative CHECK (shield_regen >= 0 AND shield_regen_delay >= 0);
+
+
+-- +goose Down
+ALTER TABLE planets
+    DROP CONSTRAINT planets_shield_regen_non_negative,
+    DROP CONSTRAINT planets_shields_range,
+    ADD CONSTRAINT planets_shields_non_negative CHECK (shields >= 0);
+
+ALTER TABLE planets
+    DROP COLUMN shield_regen_delay,
+    DROP COLUMN shield_regen,
+    DROP COLUMN max_shields;
@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE planets
+    ADD COLUMN status       TEXT NOT NULL DEFAULT 'active',
+    ADD COLUMN destroyed_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE planets SET status = 'destroyed', destroyed_at = now() WHERE health = 0;
+
+ALTER TABLE planets
+    ADD CONSTRAINT planets_status_check CHECK (status IN ('active', 'destroyed')),
+    -- a planet is destroyed exactly when it has no HP left
+    ADD CONSTRAINT planets_destroyed_health CHECK ((status = 'destroyed') = (health = 0));
+
+
+-- +goose Down
+ALTER TABLE planets
+    DROP CONSTRAINT planets_destroyed_health,
+    DROP CONSTRAINT planets_status_check;
+
+ALTER TABLE planets
+    DROP COLUMN destroyed_at,
+    DROP COLUMN status;
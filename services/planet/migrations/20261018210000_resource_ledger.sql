@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE resource_ledger (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    source          TEXT NOT NULL,
+    -- what was credited, e.g. the battle whose loot this is
+    reference_id    UUID NOT NULL,
+    base            JSONB NOT NULL,
+    bonus_percent   INT NOT NULL DEFAULT 0,
+    amount          JSONB NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    -- each source event is credited at most once
+    CONSTRAINT resource_ledger_source_reference UNIQUE (source, reference_id)
+);
+
+CREATE INDEX resource_ledger_planet_created_idx ON resource_ledger (planet_id, created_at DESC, id DESC);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS resource_ledger;
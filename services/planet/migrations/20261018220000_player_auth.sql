@@ -0,0 +1,23 @@
+-- +goose Up
+-- players created before passwords existed cannot log in until one is set
+ALTER TABLE players
+    ADD COLUMN password_hash TEXT;
+
+CREATE TABLE refresh_tokens (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id   UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    -- SHA-256 of the token, the token itself is only ever sent to the client
+    token_hash  TEXT NOT NULL UNIQUE,
+    expires_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at  TIMESTAMP WITH TIME ZONE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX refresh_tokens_player_idx ON refresh_tokens (player_id);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE players
+    DROP COLUMN password_hash;
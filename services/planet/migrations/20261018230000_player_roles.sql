@@ -0,0 +1,11 @@
+-- +goose Up
+-- admins are promoted by hand, there is no endpoint for it
+ALTER TABLE players
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'player',
+    ADD CONSTRAINT players_role_check CHECK (role IN ('player', 'admin'));
+
+
+-- +goose Down
+ALTER TABLE players
+    DROP CONSTRAINT players_role_check,
+    DROP COLUMN role;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            TEXT NOT NULL,
+    -- SHA-256 of the key, the key itself is only shown when it is created
+    key_hash        TEXT NOT NULL UNIQUE,
+    scopes          TEXT[] NOT NULL DEFAULT '{}',
+    expires_at      TIMESTAMP WITH TIME ZONE,
+    revoked_at      TIMESTAMP WITH TIME ZONE,
+    -- the key this one replaced when it was rotated
+    rotated_from    UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS api_keys;
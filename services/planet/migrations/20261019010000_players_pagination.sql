@@ -0,0 +1,17 @@
+-- +goose Up
+UPDATE players SET created_at = now() WHERE created_at IS NULL;
+
+ALTER TABLE players
+    ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX players_created_idx ON players (created_at DESC, id DESC);
+-- lets username prefix searches use an index whatever the collation
+CREATE INDEX players_username_pattern_idx ON players (username text_pattern_ops);
+
+
+-- +goose Down
+DROP INDEX IF EXISTS players_username_pattern_idx;
+DROP INDEX IF EXISTS players_created_idx;
+
+ALTER TABLE players
+    ALTER COLUMN created_at DROP NOT NULL;
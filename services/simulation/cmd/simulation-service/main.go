@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/simulation/internal/handlers"
+	"github.com/novaru/scallopticon/services/simulation/internal/service"
+)
+
+func main() {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	svc := service.NewSimulationService(logger)
+	handler := handlers.NewSimulationHandler(svc)
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.Logger)
+
+	r.Route("/simulations", func(r chi.Router) {
+		r.Post("/", handler.RunSimulation)
+		r.Post("/replay", handler.ReplaySimulation)
+	})
+
+	logger.Info("Simulation service running on :5002")
+	if err := http.ListenAndServe(":5002", r); err != nil {
+		logger.Fatal("HTTP server error", zap.Error(err))
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/novaru/scallopticon/services/simulation/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// fakeService records the spec it is given and returns testResult, along
+// with the recorder's error.
+type fakeService struct {
+	handlertest.Recorder
+}
+
+func newFakeService(err error) *fakeService {
+	return &fakeService{Recorder: handlertest.Recorder{Err: err}}
+}
+
+var testResult = types.SimulationResult{
+	Outcome: types.OutcomeVictory, DamageTaken: 12, HPRemaining: 88, AliensDestroyed: 2, Seed: 7,
+	Events: []types.BattleEvent{{Tick: 3, Kind: types.EventAlienDestroyed, Target: "drone-1"}},
+}
+
+func (f *fakeService) Run(_ context.Context, spec service.BattleSpec) (types.SimulationResult, error) {
+	return testResult, f.Record("Run", spec)
+}
+
+func (f *fakeService) Replay(_ context.Context, spec service.BattleSpec) (types.SimulationResult, error) {
+	return testResult, f.Record("Replay", spec)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/novaru/scallopticon/services/simulation/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type SimulationHandler struct {
+	service service.SimulationService
+}
+
+func NewSimulationHandler(s service.SimulationService) *SimulationHandler {
+	return &SimulationHandler{service: s}
+}
+
+// RunSimulationRequest describes a battle in full. To replay a battle, send
+// the original request again together with the seed and timestamp from its
+// result.
+type RunSimulationRequest struct {
+	Planet    types.Planet          `json:"planet"`
+	Wave      types.Wave            `json:"wave"`
+	Aliens    []types.AlienTemplate `json:"aliens"`
+	Seed      *int64                `json:"seed,omitempty"`
+	Timestamp time.Time             `json:"timestamp,omitempty"`
+}
+
+func (r *RunSimulationRequest) Validate() error {
+	if len(r.Wave.Aliens) == 0 {
+		return apperrors.NewInvalidInputError("wave must contain at least one alien", nil)
+	}
+	if r.Planet.HP <= 0 {
+		return apperrors.NewInvalidInputError("planet hp must be positive", nil)
+	}
+	for _, d := range r.Planet.Defenses {
+		if err := simulation.ValidateDamageType(d.DamageType); err != nil {
+			return apperrors.NewInvalidInputError(err.Error(), err)
+		}
+	}
+	for _, a := range r.Aliens {
+		if err := simulation.ValidateTemplate(a); err != nil {
+			return apperrors.NewInvalidInputError(err.Error(), err)
+		}
+	}
+	return nil
+}
+
+func (r *RunSimulationRequest) spec() service.BattleSpec {
+	return service.BattleSpec{
+		Planet:    r.Planet,
+		Wave:      r.Wave,
+		Aliens:    r.Aliens,
+		Seed:      r.Seed,
+		Timestamp: r.Timestamp,
+	}
+}
+
+// RunSimulation fights the battle. Pass ?events=text to add the text event
+// log alongside the structured events.
+func (h *SimulationHandler) RunSimulation(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRunSimulationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Run(r.Context(), req.spec())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if wantsEventLog(r) {
+		result.RenderEventLog()
+	}
+
+	response.WriteSuccess(w, result)
+}
+
+func (h *SimulationHandler) ReplaySimulation(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeRunSimulationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Replay(r.Context(), req.spec())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if wantsEventLog(r) {
+		result.RenderEventLog()
+	}
+
+	response.WriteSuccess(w, result)
+}
+
+func decodeRunSimulationRequest(w http.ResponseWriter, r *http.Request) (RunSimulationRequest, bool) {
+	var req RunSimulationRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return req, false
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return req, false
+	}
+
+	return req, true
+}
+
+func wantsEventLog(r *http.Request) bool {
+	return r.URL.Query().Get("events") == "text"
+}
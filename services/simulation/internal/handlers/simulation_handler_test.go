@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/novaru/scallopticon/services/simulation/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestSimulationHandlerRoutes(t *testing.T) {
+	valid := `{"planet":{"hp":100,"defenses":[{"damage":5,"range":3,"fire_rate":1,"damage_type":"laser"}]},` +
+		`"wave":{"difficulty":1,"aliens":[{"alien_id":"drone","count":2}]},` +
+		`"aliens":[{"id":"drone","hp":10,"damage":1,"speed":1,"behavior_type":"basic","damage_type":"kinetic"}],"seed":7}`
+	seed := int64(7)
+	spec := service.BattleSpec{
+		Planet: types.Planet{HP: 100, Defenses: []types.DefenseSystem{{Damage: 5, Range: 3, FireRate: 1, DamageType: types.DamageLaser}}},
+		Wave:   types.Wave{Difficulty: 1, Aliens: []types.WaveSpawn{{AlienID: "drone", Count: 2}}},
+		Aliens: []types.AlienTemplate{{ID: "drone", HP: 10, Damage: 1, Speed: 1, BehaviorType: "basic", DamageType: types.DamageKinetic}},
+		Seed:   &seed,
+	}
+	rendered := testResult
+	rendered.RenderEventLog()
+	invalid := []handlertest.InvalidRequest{
+		{Name: "no aliens in wave", Body: `{"planet":{"hp":100},"wave":{"aliens":[]}}`},
+		{Name: "no planet hp", Body: `{"planet":{"hp":0},"wave":{"aliens":[{"alien_id":"drone","count":1}]}}`},
+		{Name: "unknown defense damage type", Body: `{"planet":{"hp":100,"defenses":[{"damage_type":"sonic"}]},"wave":{"aliens":[{"alien_id":"drone","count":1}]}}`},
+		{Name: "unknown behavior", Body: `{"planet":{"hp":100},"wave":{"aliens":[{"alien_id":"drone","count":1}]},"aliens":[{"id":"drone","hp":10,"behavior_type":"teleport"}]}`},
+		{Name: "wrong type", Body: `{"planet":{"hp":"full"}}`},
+	}
+	h := func(s *fakeService) *SimulationHandler { return NewSimulationHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "run", Method: http.MethodPost, Pattern: "/simulations", Target: "/simulations?events=text", Body: valid,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).RunSimulation },
+			Status:  http.StatusOK,
+			Call:    handlertest.Call{Method: "Run", Args: []any{spec}},
+			Data:    rendered,
+			Invalid: invalid,
+		},
+		{
+			Name: "replay", Method: http.MethodPost, Pattern: "/simulations/replay", Target: "/simulations/replay", Body: valid,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).ReplaySimulation },
+			Status:  http.StatusOK,
+			Call:    handlertest.Call{Method: "Replay", Args: []any{spec}},
+			Data:    testResult,
+			Invalid: invalid,
+		},
+	})
+}
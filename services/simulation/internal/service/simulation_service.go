@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// BattleSpec is a self-contained battle: the planet as it stood, the wave and
+// the alien templates the wave refers to.
+type BattleSpec struct {
+	Planet    types.Planet
+	Wave      types.Wave
+	Aliens    []types.AlienTemplate
+	Seed      *int64
+	Timestamp time.Time
+}
+
+type SimulationService interface {
+	Run(ctx context.Context, spec BattleSpec) (types.SimulationResult, error)
+	Replay(ctx context.Context, spec BattleSpec) (types.SimulationResult, error)
+}
+
+type simulationService struct {
+	logger *zap.Logger
+}
+
+func NewSimulationService(logger *zap.Logger) SimulationService {
+	return &simulationService{logger: logger}
+}
+
+// Run simulates the battle, picking a fresh seed when the spec has none.
+func (s *simulationService) Run(ctx context.Context, spec BattleSpec) (types.SimulationResult, error) {
+	if spec.Seed == nil {
+		seed := rand.Int64()
+		spec.Seed = &seed
+	}
+	if spec.Timestamp.IsZero() {
+		spec.Timestamp = time.Now().UTC()
+	}
+	return s.simulate(spec)
+}
+
+// Replay re-runs a previous battle. The seed and timestamp of the original
+// result are required so the replay is identical to it.
+func (s *simulationService) Replay(ctx context.Context, spec BattleSpec) (types.SimulationResult, error) {
+	if spec.Seed == nil {
+		return types.SimulationResult{}, apperrors.NewInvalidInputError("seed is required to replay a battle", nil)
+	}
+	if spec.Timestamp.IsZero() {
+		return types.SimulationResult{}, apperrors.NewInvalidInputError("timestamp is required to replay a battle", nil)
+	}
+	return s.simulate(spec)
+}
+
+func (s *simulationService) simulate(spec BattleSpec) (types.SimulationResult, error) {
+	templates := make(map[string]types.AlienTemplate, len(spec.Aliens))
+	for _, a := range spec.Aliens {
+		templates[a.ID] = a
+	}
+
+	s.logger.Debug("running simulation",
+		zap.String("planet_id", spec.Planet.ID),
+		zap.String("wave_id", spec.Wave.ID),
+		zap.Int64("seed", *spec.Seed))
+
+	result, err := simulation.Run(simulation.Input{
+		Planet:    spec.Planet,
+		Wave:      spec.Wave,
+		Templates: templates,
+		Seed:      *spec.Seed,
+		Timestamp: spec.Timestamp,
+	})
+	if err != nil {
+		if simulation.IsInvalidInput(err) {
+			return types.SimulationResult{}, apperrors.NewInvalidInputError(err.Error(), err)
+		}
+		s.logger.Error("simulation failed", zap.Error(err))
+		return types.SimulationResult{}, apperrors.NewInternalError("failed to run simulation", err)
+	}
+
+	s.logger.Debug("simulation finished",
+		zap.Int64("seed", result.Seed),
+		zap.Int("aliens_destroyed", result.AliensDestroyed),
+		zap.Int("hp_remaining", result.HPRemaining))
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/wave/internal/handlers"
+	"github.com/novaru/scallopticon/services/wave/internal/repository"
+	"github.com/novaru/scallopticon/services/wave/internal/service"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+func main() {
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+
+	if err := godotenv.Load(); err != nil {
+		logger.Fatal("Error loading .env file", zap.Error(err))
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		logger.Fatal("DATABASE_URL not set")
+	}
+
+	pool, err := pgxpool.New(context.Background(), dbURL)
+	if err != nil {
+		logger.Fatal("DB connect error", zap.Error(err))
+	}
+	defer pool.Close()
+
+	q := generated.New(pool)
+	repo := repository.NewWaveRepository(q, pool, logger)
+	svc := service.NewWaveService(repo, logger)
+	handler := handlers.NewWaveHandler(svc)
+
+	alienRepo := repository.NewAlienRepository(q, pool, logger)
+	alienSvc := service.NewAlienService(alienRepo, logger)
+	alienHandler := handlers.NewAlienHandler(alienSvc)
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.Logger)
+
+	r.Route("/waves", func(r chi.Router) {
+		r.Get("/", handler.GetWaves)
+		r.Get("/{id}", handler.GetWaveByID)
+		r.Post("/", handler.CreateWave)
+		r.Post("/generate", handler.GenerateWave)
+		r.Delete("/{id}", handler.DeleteWave)
+	})
+
+	r.Get("/planets/{id}/next-wave", handler.GetNextWave)
+
+	r.Route("/aliens", func(r chi.Router) {
+		r.Get("/", alienHandler.GetTemplates)
+		r.Get("/{id}", alienHandler.GetTemplateByID)
+		r.Get("/{id}/versions", alienHandler.GetTemplateVersions)
+		r.Post("/", alienHandler.CreateTemplate)
+		r.Put("/{id}", alienHandler.UpdateTemplate)
+		r.Post("/{id}/retire", alienHandler.RetireTemplate)
+	})
+
+	logger.Info("Wave service running on :5001")
+	if err := http.ListenAndServe(":5001", r); err != nil {
+		logger.Fatal("HTTP server error", zap.Error(err))
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/wave/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type AlienHandler struct {
+	service service.AlienService
+}
+
+func NewAlienHandler(s service.AlienService) *AlienHandler {
+	return &AlienHandler{service: s}
+}
+
+type AlienTemplateRequest struct {
+	Name         string             `json:"name"`
+	HP           int                `json:"hp"`
+	Damage       int                `json:"damage"`
+	DamageType   string             `json:"damage_type"`
+	Speed        float64            `json:"speed"`
+	BehaviorType string             `json:"behavior_type"`
+	Resistances  map[string]float64 `json:"resistances"`
+	LootDrop     types.Resources    `json:"loot_drop"`
+}
+
+func (r *AlienTemplateRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return apperrors.NewInvalidInputError("name is required", nil)
+	}
+	if r.HP <= 0 {
+		return apperrors.NewInvalidInputError("hp must be positive", nil)
+	}
+	if r.LootDrop.Minerals < 0 || r.LootDrop.Energy < 0 || r.LootDrop.TechParts < 0 {
+		return apperrors.NewInvalidInputError("loot drop must not be negative", nil)
+	}
+	return nil
+}
+
+func (r *AlienTemplateRequest) template() types.AlienTemplate {
+	return types.AlienTemplate{
+		Name:         strings.TrimSpace(r.Name),
+		HP:           r.HP,
+		Damage:       r.Damage,
+		DamageType:   r.DamageType,
+		Speed:        r.Speed,
+		BehaviorType: r.BehaviorType,
+		Resistances:  r.Resistances,
+		LootDrop:     r.LootDrop,
+	}
+}
+
+func (h *AlienHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	includeRetired := r.URL.Query().Get("include_retired") == "true"
+
+	templates, err := h.service.ListTemplates(r.Context(), includeRetired)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, templates)
+}
+
+func (h *AlienHandler) GetTemplateByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.service.GetTemplate(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tmpl)
+}
+
+func (h *AlienHandler) GetTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.service.ListVersions(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, versions)
+}
+
+func (h *AlienHandler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req AlienTemplateRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	tmpl, err := h.service.CreateTemplate(r.Context(), req.template())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, tmpl)
+}
+
+func (h *AlienHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AlienTemplateRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	tmpl, err := h.service.UpdateTemplate(r.Context(), id, req.template())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tmpl)
+}
+
+func (h *AlienHandler) RetireTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseTemplateID(w, r)
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.service.RetireTemplate(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, tmpl)
+}
+
+func parseTemplateID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid alien template ID", err))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestAlienHandlerRoutes(t *testing.T) {
+	id := uuid.New()
+	alien := "/aliens/" + id.String()
+	valid := `{"name":" Drone ","hp":10,"damage":1,"damage_type":"kinetic","speed":1.5,"behavior_type":"basic",` +
+		`"resistances":{"laser":0.25},"loot_drop":{"minerals":1}}`
+	// The template the handler passes on for valid, with the name trimmed.
+	requested := types.AlienTemplate{
+		Name: "Drone", HP: 10, Damage: 1, DamageType: types.DamageKinetic, Speed: 1.5, BehaviorType: "basic",
+		Resistances: map[string]float64{types.DamageLaser: 0.25}, LootDrop: types.Resources{Minerals: 1},
+	}
+	h := func(s *fakeService) *AlienHandler { return NewAlienHandler(s) }
+	invalidBodies := []handlertest.InvalidRequest{
+		{Name: "no name", Body: `{"name":" ","hp":10}`},
+		{Name: "no hp", Body: `{"name":"Drone","hp":0}`},
+		{Name: "negative loot", Body: `{"name":"Drone","hp":10,"loot_drop":{"energy":-1}}`},
+	}
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list templates", Method: http.MethodGet, Pattern: "/aliens", Target: "/aliens?include_retired=true",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetTemplates },
+			Status:  http.StatusOK,
+			Call:    call("ListTemplates", true),
+			Data:    []types.AlienTemplate{testTemplate},
+		},
+		{
+			Name: "list active templates", Method: http.MethodGet, Pattern: "/aliens", Target: "/aliens",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetTemplates },
+			Status:  http.StatusOK,
+			Call:    call("ListTemplates", false),
+			Data:    []types.AlienTemplate{testTemplate},
+		},
+		{
+			Name: "get template", Method: http.MethodGet, Pattern: "/aliens/{id}", Target: alien,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetTemplateByID },
+			Status:  http.StatusOK,
+			Call:    call("GetTemplate", id),
+			Data:    testTemplate,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad template ID", Target: "/aliens/nope"}},
+		},
+		{
+			Name: "list versions", Method: http.MethodGet, Pattern: "/aliens/{id}/versions", Target: alien + "/versions",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetTemplateVersions },
+			Status:  http.StatusOK,
+			Call:    call("ListVersions", id),
+			Data:    []types.AlienTemplate{testTemplate},
+			Invalid: []handlertest.InvalidRequest{{Name: "bad template ID", Target: "/aliens/nope/versions"}},
+		},
+		{
+			Name: "create template", Method: http.MethodPost, Pattern: "/aliens", Target: "/aliens", Body: valid,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).CreateTemplate },
+			Status:  http.StatusCreated,
+			Call:    call("CreateTemplate", requested),
+			Data:    testTemplate,
+			Invalid: invalidBodies,
+		},
+		{
+			Name: "update template", Method: http.MethodPut, Pattern: "/aliens/{id}", Target: alien, Body: valid,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).UpdateTemplate },
+			Status:  http.StatusOK,
+			Call:    call("UpdateTemplate", id, requested),
+			Data:    testTemplate,
+			Invalid: append([]handlertest.InvalidRequest{{Name: "bad template ID", Target: "/aliens/nope"}}, invalidBodies...),
+		},
+		{
+			Name: "retire template", Method: http.MethodPost, Pattern: "/aliens/{id}/retire", Target: alien + "/retire",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).RetireTemplate },
+			Status:  http.StatusOK,
+			Call:    call("RetireTemplate", id),
+			Data:    testTemplate,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad template ID", Target: "/aliens/nope/retire"}},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/wave/internal/service"
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// fakeService implements the alien and wave services. Each method records
+// its arguments and returns the matching test value below, along with the
+// recorder's error.
+type fakeService struct {
+	handlertest.Recorder
+}
+
+func newFakeService(err error) *fakeService {
+	return &fakeService{Recorder: handlertest.Recorder{Err: err}}
+}
+
+// Values the fake returns, distinct enough that a handler rendering the
+// wrong one fails the test.
+var (
+	testTemplate = types.AlienTemplate{
+		ID: uuid.NewString(), Name: "Drone", HP: 10, Damage: 1, DamageType: types.DamageKinetic,
+		Speed: 1, BehaviorType: "basic", LootDrop: types.Resources{Minerals: 1}, Version: 2,
+	}
+	testWave = types.Wave{
+		ID: uuid.NewString(), Difficulty: 2,
+		Aliens:    []types.WaveSpawn{{AlienID: testTemplate.ID, Count: 3}},
+		CreatedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+	testGenerated = service.GeneratedWave{Wave: testWave, Seed: 42, Budget: 12.5}
+)
+
+func (f *fakeService) CreateTemplate(_ context.Context, tmpl types.AlienTemplate) (types.AlienTemplate, error) {
+	return testTemplate, f.Record("CreateTemplate", tmpl)
+}
+
+func (f *fakeService) UpdateTemplate(_ context.Context, id uuid.UUID, tmpl types.AlienTemplate) (types.AlienTemplate, error) {
+	return testTemplate, f.Record("UpdateTemplate", id, tmpl)
+}
+
+func (f *fakeService) GetTemplate(_ context.Context, id uuid.UUID) (types.AlienTemplate, error) {
+	return testTemplate, f.Record("GetTemplate", id)
+}
+
+func (f *fakeService) ListTemplates(_ context.Context, includeRetired bool) ([]types.AlienTemplate, error) {
+	return []types.AlienTemplate{testTemplate}, f.Record("ListTemplates", includeRetired)
+}
+
+func (f *fakeService) RetireTemplate(_ context.Context, id uuid.UUID) (types.AlienTemplate, error) {
+	return testTemplate, f.Record("RetireTemplate", id)
+}
+
+func (f *fakeService) ListVersions(_ context.Context, id uuid.UUID) ([]types.AlienTemplate, error) {
+	return []types.AlienTemplate{testTemplate}, f.Record("ListVersions", id)
+}
+
+func (f *fakeService) CreateWave(_ context.Context, difficulty int, aliens []types.WaveSpawn) (types.Wave, error) {
+	return testWave, f.Record("CreateWave", difficulty, aliens)
+}
+
+func (f *fakeService) GetWave(_ context.Context, id uuid.UUID) (types.Wave, error) {
+	return testWave, f.Record("GetWave", id)
+}
+
+func (f *fakeService) ListWaves(context.Context) ([]types.Wave, error) {
+	return []types.Wave{testWave}, f.Record("ListWaves")
+}
+
+func (f *fakeService) DeleteWave(_ context.Context, id uuid.UUID) error {
+	return f.Record("DeleteWave", id)
+}
+
+func (f *fakeService) GenerateWave(_ context.Context, difficulty int, seed int64, save bool) (service.GeneratedWave, error) {
+	return testGenerated, f.Record("GenerateWave", difficulty, seed, save)
+}
+
+func (f *fakeService) NextWave(_ context.Context, planetID uuid.UUID) (service.GeneratedWave, error) {
+	return testGenerated, f.Record("NextWave", planetID)
+}
+
+// call builds the service call a route is expected to make.
+func call(method string, args ...any) handlertest.Call {
+	return handlertest.Call{Method: method, Args: args}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"math/rand/v2"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/services/wave/internal/service"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+	"github.com/novaru/scallopticon/shared/types"
+	"github.com/novaru/scallopticon/shared/wavegen"
+)
+
+type WaveHandler struct {
+	service service.WaveService
+}
+
+func NewWaveHandler(s service.WaveService) *WaveHandler {
+	return &WaveHandler{service: s}
+}
+
+type CreateWaveRequest struct {
+	Difficulty int               `json:"difficulty"`
+	Aliens     []types.WaveSpawn `json:"aliens"`
+}
+
+func (r *CreateWaveRequest) Validate() error {
+	if r.Difficulty < 1 {
+		return apperrors.NewInvalidInputError("difficulty must be at least 1", nil)
+	}
+	if r.Difficulty > wavegen.MaxDifficulty {
+		return apperrors.NewInvalidInputError(wavegen.ErrDifficultyTooHigh.Error(), nil)
+	}
+	if len(r.Aliens) == 0 {
+		return apperrors.NewInvalidInputError("wave must contain at least one alien", nil)
+	}
+	for _, a := range r.Aliens {
+		if a.AlienID == "" {
+			return apperrors.NewInvalidInputError("alien_id is required", nil)
+		}
+		if a.Count <= 0 {
+			return apperrors.NewInvalidInputError("count must be positive", nil)
+		}
+	}
+	return nil
+}
+
+type GenerateWaveRequest struct {
+	Difficulty int    `json:"difficulty"`
+	Seed       *int64 `json:"seed,omitempty"` // random when omitted
+	Save       bool   `json:"save"`
+}
+
+func (r *GenerateWaveRequest) Validate() error {
+	if r.Difficulty < 1 {
+		return apperrors.NewInvalidInputError("difficulty must be at least 1", nil)
+	}
+	if r.Difficulty > wavegen.MaxDifficulty {
+		return apperrors.NewInvalidInputError(wavegen.ErrDifficultyTooHigh.Error(), nil)
+	}
+	return nil
+}
+
+func (h *WaveHandler) GetWaves(w http.ResponseWriter, r *http.Request) {
+	waves, err := h.service.ListWaves(r.Context())
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, waves)
+}
+
+func (h *WaveHandler) GetWaveByID(w http.ResponseWriter, r *http.Request) {
+	waveID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid wave ID", err))
+		return
+	}
+
+	wave, err := h.service.GetWave(r.Context(), waveID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, wave)
+}
+
+func (h *WaveHandler) CreateWave(w http.ResponseWriter, r *http.Request) {
+	var req CreateWaveRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	wave, err := h.service.CreateWave(r.Context(), req.Difficulty, req.Aliens)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteCreated(w, wave)
+}
+
+func (h *WaveHandler) DeleteWave(w http.ResponseWriter, r *http.Request) {
+	waveID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid wave ID", err))
+		return
+	}
+
+	if err := h.service.DeleteWave(r.Context(), waveID); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, nil)
+}
+
+func (h *WaveHandler) GenerateWave(w http.ResponseWriter, r *http.Request) {
+	var req GenerateWaveRequest
+
+	if err := request.DecodeJSON(w, r, &req); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	seed := rand.Int64()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	wave, err := h.service.GenerateWave(r.Context(), req.Difficulty, seed, req.Save)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	if req.Save {
+		response.WriteCreated(w, wave)
+		return
+	}
+	response.WriteSuccess(w, wave)
+}
+
+func (h *WaveHandler) GetNextWave(w http.ResponseWriter, r *http.Request) {
+	planetID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, apperrors.NewInvalidInputError("invalid planet ID", err))
+		return
+	}
+
+	wave, err := h.service.NextWave(r.Context(), planetID)
+	if err != nil {
+		response.WriteError(w, err)
+		return
+	}
+
+	response.WriteSuccess(w, wave)
+}
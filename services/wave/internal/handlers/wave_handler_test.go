@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/shared/handlertest"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestWaveHandlerRoutes(t *testing.T) {
+	id := uuid.New()
+	alienID := uuid.NewString()
+	planetID := uuid.New()
+	wave := "/waves/" + id.String()
+	h := func(s *fakeService) *WaveHandler { return NewWaveHandler(s) }
+
+	handlertest.RunRoutes(t, newFakeService, []handlertest.Route[*fakeService]{
+		{
+			Name: "list waves", Method: http.MethodGet, Pattern: "/waves", Target: "/waves",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetWaves },
+			Status:  http.StatusOK,
+			Call:    call("ListWaves"),
+			Data:    []types.Wave{testWave},
+		},
+		{
+			Name: "get wave", Method: http.MethodGet, Pattern: "/waves/{id}", Target: wave,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetWaveByID },
+			Status:  http.StatusOK,
+			Call:    call("GetWave", id),
+			Data:    testWave,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad wave ID", Target: "/waves/nope"}},
+		},
+		{
+			Name: "create wave", Method: http.MethodPost, Pattern: "/waves", Target: "/waves",
+			Body:    `{"difficulty":2,"aliens":[{"alien_id":"` + alienID + `","count":3}]}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).CreateWave },
+			Status:  http.StatusCreated,
+			Call:    call("CreateWave", 2, []types.WaveSpawn{{AlienID: alienID, Count: 3}}),
+			Data:    testWave,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "no difficulty", Body: `{"aliens":[{"alien_id":"a","count":1}]}`},
+				{Name: "difficulty too high", Body: `{"difficulty":31,"aliens":[{"alien_id":"a","count":1}]}`},
+				{Name: "no aliens", Body: `{"difficulty":2,"aliens":[]}`},
+				{Name: "no alien ID", Body: `{"difficulty":2,"aliens":[{"count":1}]}`},
+				{Name: "no count", Body: `{"difficulty":2,"aliens":[{"alien_id":"a","count":0}]}`},
+			},
+		},
+		{
+			Name: "delete wave", Method: http.MethodDelete, Pattern: "/waves/{id}", Target: wave,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).DeleteWave },
+			Status:  http.StatusOK,
+			Call:    call("DeleteWave", id),
+			Invalid: []handlertest.InvalidRequest{{Name: "bad wave ID", Target: "/waves/nope"}},
+		},
+		{
+			Name: "generate wave", Method: http.MethodPost, Pattern: "/waves/generate", Target: "/waves/generate",
+			Body:    `{"difficulty":5,"seed":42}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GenerateWave },
+			Status:  http.StatusOK,
+			Call:    call("GenerateWave", 5, int64(42), false),
+			Data:    testGenerated,
+			Invalid: []handlertest.InvalidRequest{
+				{Name: "no difficulty", Body: `{"seed":42}`},
+				{Name: "difficulty too high", Body: `{"difficulty":31}`},
+			},
+		},
+		{
+			Name: "generate and save wave", Method: http.MethodPost, Pattern: "/waves/generate", Target: "/waves/generate",
+			Body:    `{"difficulty":5,"seed":7,"save":true}`,
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GenerateWave },
+			Status:  http.StatusCreated,
+			Call:    call("GenerateWave", 5, int64(7), true),
+			Data:    testGenerated,
+		},
+		{
+			Name: "next wave", Method: http.MethodGet, Pattern: "/planets/{id}/next-wave", Target: "/planets/" + planetID.String() + "/next-wave",
+			Handler: func(s *fakeService) http.HandlerFunc { return h(s).GetNextWave },
+			Status:  http.StatusOK,
+			Call:    call("NextWave", planetID),
+			Data:    testGenerated,
+			Invalid: []handlertest.InvalidRequest{{Name: "bad planet ID", Target: "/planets/nope/next-wave"}},
+		},
+	})
+}
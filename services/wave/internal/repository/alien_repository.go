@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type AlienRepository interface {
+	Create(ctx context.Context, arg generated.CreateAlienTemplateParams) (generated.AlienTemplate, error)
+	Update(ctx context.Context, arg generated.UpdateAlienTemplateParams) (generated.AlienTemplate, error)
+	GetByID(ctx context.Context, id uuid.UUID) (generated.AlienTemplate, error)
+	List(ctx context.Context, includeRetired bool) ([]generated.AlienTemplate, error)
+	Retire(ctx context.Context, id uuid.UUID) (generated.AlienTemplate, error)
+	ListVersions(ctx context.Context, id uuid.UUID) ([]generated.AlienTemplateVersion, error)
+}
+
+type alienRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewAlienRepository(q *generated.Queries, db DB, logger *zap.Logger) AlienRepository {
+	return &alienRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a new template together with its first version snapshot.
+func (r *alienRepository) Create(ctx context.Context, arg generated.CreateAlienTemplateParams) (generated.AlienTemplate, error) {
+	return r.withVersion(ctx, "create", func(qtx *generated.Queries) (generated.AlienTemplate, error) {
+		return qtx.CreateAlienTemplate(ctx, arg)
+	})
+}
+
+// Update changes a template in place, bumps its version and records a
+// snapshot of the new version. Retired templates can't be updated.
+func (r *alienRepository) Update(ctx context.Context, arg generated.UpdateAlienTemplateParams) (generated.AlienTemplate, error) {
+	tmpl, err := r.withVersion(ctx, "update", func(qtx *generated.Queries) (generated.AlienTemplate, error) {
+		return qtx.UpdateAlienTemplate(ctx, arg)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return generated.AlienTemplate{}, r.notUpdatable(ctx, arg.ID)
+	}
+	return tmpl, err
+}
+
+func (r *alienRepository) withVersion(ctx context.Context, op string, write func(*generated.Queries) (generated.AlienTemplate, error)) (generated.AlienTemplate, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	tmpl, err := write(qtx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.AlienTemplate{}, err
+		}
+		r.logger.Error("failed to "+op+" alien template", zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to "+op+" alien template", err)
+	}
+
+	err = qtx.CreateAlienTemplateVersion(ctx, generated.CreateAlienTemplateVersionParams{
+		TemplateID:   tmpl.ID,
+		Version:      tmpl.Version,
+		Name:         tmpl.Name,
+		Hp:           tmpl.Hp,
+		Damage:       tmpl.Damage,
+		Speed:        tmpl.Speed,
+		BehaviorType: tmpl.BehaviorType,
+		Resistances:  tmpl.Resistances,
+		LootDrop:     tmpl.LootDrop,
+		DamageType:   tmpl.DamageType,
+	})
+	if err != nil {
+		r.logger.Error("failed to record alien template version",
+			zap.String("template_id", tmpl.ID.String()),
+			zap.Int32("version", tmpl.Version),
+			zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to record alien template version", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to save alien template", err)
+	}
+
+	r.logger.Info("successfully saved alien template",
+		zap.String("template_id", tmpl.ID.String()),
+		zap.Int32("version", tmpl.Version))
+
+	return tmpl, nil
+}
+
+// notUpdatable explains why an update or retire matched no row.
+func (r *alienRepository) notUpdatable(ctx context.Context, id uuid.UUID) error {
+	tmpl, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tmpl.RetiredAt.Valid {
+		return apperrors.NewInvalidInputError("alien template is retired", nil)
+	}
+	return apperrors.NewInternalError("failed to modify alien template", nil)
+}
+
+func (r *alienRepository) GetByID(ctx context.Context, id uuid.UUID) (generated.AlienTemplate, error) {
+	tmpl, err := r.q.GetAlienTemplateByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("alien template not found", zap.String("template_id", id.String()))
+			return generated.AlienTemplate{}, apperrors.NewNotFoundError("alien template", "alien template with given ID does not exist")
+		}
+
+		r.logger.Error("failed to get alien template by ID",
+			zap.String("template_id", id.String()),
+			zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to retrieve alien template", err)
+	}
+
+	return tmpl, nil
+}
+
+func (r *alienRepository) List(ctx context.Context, includeRetired bool) ([]generated.AlienTemplate, error) {
+	templates, err := r.q.ListAlienTemplates(ctx, includeRetired)
+	if err != nil {
+		r.logger.Error("failed to list alien templates", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien templates", err)
+	}
+
+	r.logger.Debug("successfully retrieved alien templates", zap.Int("count", len(templates)))
+	return templates, nil
+}
+
+func (r *alienRepository) Retire(ctx context.Context, id uuid.UUID) (generated.AlienTemplate, error) {
+	tmpl, err := r.q.RetireAlienTemplate(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return generated.AlienTemplate{}, r.notUpdatable(ctx, id)
+		}
+
+		r.logger.Error("failed to retire alien template",
+			zap.String("template_id", id.String()),
+			zap.Error(err))
+		return generated.AlienTemplate{}, apperrors.NewInternalError("failed to retire alien template", err)
+	}
+
+	r.logger.Info("successfully retired alien template", zap.String("template_id", id.String()))
+	return tmpl, nil
+}
+
+func (r *alienRepository) ListVersions(ctx context.Context, id uuid.UUID) ([]generated.AlienTemplateVersion, error) {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	versions, err := r.q.ListAlienTemplateVersions(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to list alien template versions",
+			zap.String("template_id", id.String()),
+			zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien template versions", err)
+	}
+
+	return versions, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+type WaveRepository interface {
+	CreateWave(ctx context.Context, difficulty int32, spawns []generated.WaveSpawn) (generated.Wave, []generated.WaveSpawn, error)
+	GetByID(ctx context.Context, id uuid.UUID) (generated.Wave, []generated.WaveSpawn, error)
+	List(ctx context.Context) ([]generated.Wave, []generated.WaveSpawn, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	FindAlienTemplates(ctx context.Context, ids []uuid.UUID) ([]generated.AlienTemplate, error)
+	ListActiveAlienTemplates(ctx context.Context) ([]generated.AlienTemplate, error)
+	GetPlanetCurrentWave(ctx context.Context, planetID uuid.UUID) (int, error)
+}
+
+type DB interface {
+	generated.DBTX
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type waveRepository struct {
+	q      *generated.Queries
+	db     DB
+	logger *zap.Logger
+}
+
+func NewWaveRepository(q *generated.Queries, db DB, logger *zap.Logger) WaveRepository {
+	return &waveRepository{
+		q:      q,
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *waveRepository) CreateWave(ctx context.Context, difficulty int32, spawns []generated.WaveSpawn) (generated.Wave, []generated.WaveSpawn, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		r.logger.Error("failed to start transaction", zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to start database transaction", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(ctx); rollbackErr != nil {
+				r.logger.Error("failed to rollback transaction", zap.Error(rollbackErr))
+			}
+		}
+	}()
+
+	qtx := r.q.WithTx(tx)
+
+	wave, err := qtx.CreateWave(ctx, difficulty)
+	if err != nil {
+		r.logger.Error("failed to create wave", zap.Int32("difficulty", difficulty), zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to create wave", err)
+	}
+
+	created := make([]generated.WaveSpawn, len(spawns))
+	for i, s := range spawns {
+		created[i] = generated.WaveSpawn{
+			WaveID:   wave.ID,
+			Position: int32(i),
+			AlienID:  s.AlienID,
+			Count:    s.Count,
+		}
+		err = qtx.CreateWaveSpawn(ctx, generated.CreateWaveSpawnParams(created[i]))
+		if err != nil {
+			r.logger.Error("failed to create wave spawn",
+				zap.String("wave_id", wave.ID.String()),
+				zap.String("alien_id", s.AlienID.String()),
+				zap.Error(err))
+			return generated.Wave{}, nil, apperrors.NewInternalError("failed to create wave spawn", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		r.logger.Error("failed to commit transaction", zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to save wave", err)
+	}
+
+	r.logger.Info("successfully created wave",
+		zap.String("wave_id", wave.ID.String()),
+		zap.Int32("difficulty", difficulty),
+		zap.Int("spawns", len(created)))
+
+	return wave, created, nil
+}
+
+func (r *waveRepository) GetByID(ctx context.Context, id uuid.UUID) (generated.Wave, []generated.WaveSpawn, error) {
+	wave, err := r.q.GetWaveByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("wave not found", zap.String("wave_id", id.String()))
+			return generated.Wave{}, nil, apperrors.NewNotFoundError("wave", "wave with given ID does not exist")
+		}
+
+		r.logger.Error("failed to get wave by ID",
+			zap.String("wave_id", id.String()),
+			zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to retrieve wave", err)
+	}
+
+	spawns, err := r.q.ListWaveSpawns(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to list wave spawns",
+			zap.String("wave_id", id.String()),
+			zap.Error(err))
+		return generated.Wave{}, nil, apperrors.NewInternalError("failed to retrieve wave spawns", err)
+	}
+
+	return wave, spawns, nil
+}
+
+func (r *waveRepository) List(ctx context.Context) ([]generated.Wave, []generated.WaveSpawn, error) {
+	waves, err := r.q.ListWaves(ctx)
+	if err != nil {
+		r.logger.Error("failed to list waves", zap.Error(err))
+		return nil, nil, apperrors.NewInternalError("failed to retrieve waves", err)
+	}
+
+	ids := make([]uuid.UUID, len(waves))
+	for i, w := range waves {
+		ids[i] = w.ID
+	}
+
+	spawns, err := r.q.ListWaveSpawnsByWaveIDs(ctx, ids)
+	if err != nil {
+		r.logger.Error("failed to list wave spawns", zap.Error(err))
+		return nil, nil, apperrors.NewInternalError("failed to retrieve wave spawns", err)
+	}
+
+	r.logger.Debug("successfully retrieved waves", zap.Int("count", len(waves)))
+	return waves, spawns, nil
+}
+
+func (r *waveRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	rows, err := r.q.DeleteWave(ctx, id)
+	if err != nil {
+		r.logger.Error("failed to delete wave",
+			zap.String("wave_id", id.String()),
+			zap.Error(err))
+		return apperrors.NewInternalError("failed to delete wave", err)
+	}
+	if rows == 0 {
+		return apperrors.NewNotFoundError("wave", "wave with given ID does not exist")
+	}
+
+	r.logger.Info("successfully deleted wave", zap.String("wave_id", id.String()))
+	return nil
+}
+
+func (r *waveRepository) FindAlienTemplates(ctx context.Context, ids []uuid.UUID) ([]generated.AlienTemplate, error) {
+	templates, err := r.q.ListAlienTemplatesByIDs(ctx, ids)
+	if err != nil {
+		r.logger.Error("failed to look up alien templates", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien templates", err)
+	}
+
+	return templates, nil
+}
+
+func (r *waveRepository) ListActiveAlienTemplates(ctx context.Context) ([]generated.AlienTemplate, error) {
+	templates, err := r.q.ListAlienTemplates(ctx, false)
+	if err != nil {
+		r.logger.Error("failed to list alien templates", zap.Error(err))
+		return nil, apperrors.NewInternalError("failed to retrieve alien templates", err)
+	}
+
+	return templates, nil
+}
+
+func (r *waveRepository) GetPlanetCurrentWave(ctx context.Context, planetID uuid.UUID) (int, error) {
+	planet, err := r.q.GetPlanetByID(ctx, planetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			r.logger.Debug("planet not found", zap.String("planet_id", planetID.String()))
+			return 0, apperrors.NewNotFoundError("planet", "planet with given ID does not exist")
+		}
+
+		r.logger.Error("failed to get planet by ID",
+			zap.String("planet_id", planetID.String()),
+			zap.Error(err))
+		return 0, apperrors.NewInternalError("failed to retrieve planet", err)
+	}
+
+	return int(planet.CurrentWave), nil
+}
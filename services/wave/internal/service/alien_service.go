@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/wave/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+type AlienService interface {
+	CreateTemplate(ctx context.Context, t types.AlienTemplate) (types.AlienTemplate, error)
+	UpdateTemplate(ctx context.Context, id uuid.UUID, t types.AlienTemplate) (types.AlienTemplate, error)
+	GetTemplate(ctx context.Context, id uuid.UUID) (types.AlienTemplate, error)
+	ListTemplates(ctx context.Context, includeRetired bool) ([]types.AlienTemplate, error)
+	RetireTemplate(ctx context.Context, id uuid.UUID) (types.AlienTemplate, error)
+	ListVersions(ctx context.Context, id uuid.UUID) ([]types.AlienTemplate, error)
+}
+
+type alienService struct {
+	repo   repository.AlienRepository
+	logger *zap.Logger
+}
+
+func NewAlienService(repo repository.AlienRepository, logger *zap.Logger) AlienService {
+	return &alienService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *alienService) CreateTemplate(ctx context.Context, t types.AlienTemplate) (types.AlienTemplate, error) {
+	s.logger.Debug("creating alien template", zap.String("name", t.Name))
+
+	resistances, lootDrop, err := s.encodeTemplate(t)
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	created, err := s.repo.Create(ctx, generated.CreateAlienTemplateParams{
+		Name:         t.Name,
+		Hp:           int32(t.HP),
+		Damage:       int32(t.Damage),
+		Speed:        t.Speed,
+		BehaviorType: behaviorType(t),
+		Resistances:  resistances,
+		LootDrop:     lootDrop,
+		DamageType:   alienDamageType(t),
+	})
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	return s.convertTemplate(created)
+}
+
+func (s *alienService) UpdateTemplate(ctx context.Context, id uuid.UUID, t types.AlienTemplate) (types.AlienTemplate, error) {
+	s.logger.Debug("updating alien template", zap.String("template_id", id.String()))
+
+	resistances, lootDrop, err := s.encodeTemplate(t)
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	updated, err := s.repo.Update(ctx, generated.UpdateAlienTemplateParams{
+		ID:           id,
+		Name:         t.Name,
+		Hp:           int32(t.HP),
+		Damage:       int32(t.Damage),
+		Speed:        t.Speed,
+		BehaviorType: behaviorType(t),
+		Resistances:  resistances,
+		LootDrop:     lootDrop,
+		DamageType:   alienDamageType(t),
+	})
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	return s.convertTemplate(updated)
+}
+
+func (s *alienService) GetTemplate(ctx context.Context, id uuid.UUID) (types.AlienTemplate, error) {
+	s.logger.Debug("retrieving alien template by ID", zap.String("template_id", id.String()))
+
+	tmpl, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	return s.convertTemplate(tmpl)
+}
+
+func (s *alienService) ListTemplates(ctx context.Context, includeRetired bool) ([]types.AlienTemplate, error) {
+	s.logger.Debug("retrieving alien templates", zap.Bool("include_retired", includeRetired))
+
+	templates, err := s.repo.List(ctx, includeRetired)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.AlienTemplate, len(templates))
+	for i, t := range templates {
+		if result[i], err = s.convertTemplate(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (s *alienService) RetireTemplate(ctx context.Context, id uuid.UUID) (types.AlienTemplate, error) {
+	s.logger.Debug("retiring alien template", zap.String("template_id", id.String()))
+
+	tmpl, err := s.repo.Retire(ctx, id)
+	if err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	return s.convertTemplate(tmpl)
+}
+
+func (s *alienService) ListVersions(ctx context.Context, id uuid.UUID) ([]types.AlienTemplate, error) {
+	s.logger.Debug("retrieving alien template versions", zap.String("template_id", id.String()))
+
+	versions, err := s.repo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.AlienTemplate, len(versions))
+	for i, v := range versions {
+		result[i], err = s.convertTemplate(generated.AlienTemplate{
+			ID:           v.TemplateID,
+			Name:         v.Name,
+			Hp:           v.Hp,
+			Damage:       v.Damage,
+			Speed:        v.Speed,
+			BehaviorType: v.BehaviorType,
+			Resistances:  v.Resistances,
+			LootDrop:     v.LootDrop,
+			DamageType:   v.DamageType,
+			CreatedAt:    v.CreatedAt,
+			Version:      v.Version,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// encodeTemplate validates t against the simulation rules and encodes its
+// JSONB columns.
+func (s *alienService) encodeTemplate(t types.AlienTemplate) (resistances, lootDrop []byte, err error) {
+	if err := simulation.ValidateTemplate(t); err != nil {
+		return nil, nil, apperrors.NewInvalidInputError(err.Error(), err)
+	}
+
+	if t.Resistances == nil {
+		t.Resistances = map[string]float64{}
+	}
+	if resistances, err = json.Marshal(t.Resistances); err != nil {
+		return nil, nil, apperrors.NewInternalError("failed to encode resistances", err)
+	}
+	if lootDrop, err = json.Marshal(t.LootDrop); err != nil {
+		return nil, nil, apperrors.NewInternalError("failed to encode loot drop", err)
+	}
+	return resistances, lootDrop, nil
+}
+
+func behaviorType(t types.AlienTemplate) string {
+	if t.BehaviorType == "" {
+		return simulation.DefaultBehavior
+	}
+	return t.BehaviorType
+}
+
+func alienDamageType(t types.AlienTemplate) string {
+	if t.DamageType == "" {
+		return types.DamageKinetic
+	}
+	return t.DamageType
+}
+
+// Convert generated models to the shared alien template type
+func (s *alienService) convertTemplate(t generated.AlienTemplate) (types.AlienTemplate, error) {
+	result, err := convertAlienTemplate(t)
+	if err != nil {
+		s.logger.Error("failed to decode alien template", zap.String("template_id", t.ID.String()), zap.Error(err))
+		return types.AlienTemplate{}, apperrors.NewInternalError("failed to decode alien template", err)
+	}
+	return result, nil
+}
+
+func convertAlienTemplate(t generated.AlienTemplate) (types.AlienTemplate, error) {
+	result := types.AlienTemplate{
+		ID:           t.ID.String(),
+		Name:         t.Name,
+		HP:           int(t.Hp),
+		Damage:       int(t.Damage),
+		DamageType:   t.DamageType,
+		Speed:        t.Speed,
+		BehaviorType: t.BehaviorType,
+		Version:      int(t.Version),
+	}
+	if t.RetiredAt.Valid {
+		retiredAt := t.RetiredAt.Time
+		result.RetiredAt = &retiredAt
+	}
+
+	if err := json.Unmarshal(t.Resistances, &result.Resistances); err != nil {
+		return types.AlienTemplate{}, err
+	}
+	if err := json.Unmarshal(t.LootDrop, &result.LootDrop); err != nil {
+		return types.AlienTemplate{}, err
+	}
+
+	return result, nil
+}
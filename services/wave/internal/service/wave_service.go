@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/novaru/scallopticon/services/wave/internal/repository"
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/db/generated"
+	"github.com/novaru/scallopticon/shared/types"
+	"github.com/novaru/scallopticon/shared/wavegen"
+)
+
+type WaveService interface {
+	CreateWave(ctx context.Context, difficulty int, spawns []types.WaveSpawn) (types.Wave, error)
+	GetWave(ctx context.Context, id uuid.UUID) (types.Wave, error)
+	ListWaves(ctx context.Context) ([]types.Wave, error)
+	DeleteWave(ctx context.Context, id uuid.UUID) error
+	GenerateWave(ctx context.Context, difficulty int, seed int64, save bool) (GeneratedWave, error)
+	NextWave(ctx context.Context, planetID uuid.UUID) (GeneratedWave, error)
+}
+
+// GeneratedWave is a procedurally generated wave along with the inputs that
+// reproduce it.
+type GeneratedWave struct {
+	types.Wave
+	Seed   int64   `json:"seed"`
+	Budget float64 `json:"budget"`
+}
+
+type waveService struct {
+	repo   repository.WaveRepository
+	logger *zap.Logger
+}
+
+func NewWaveService(repo repository.WaveRepository, logger *zap.Logger) WaveService {
+	return &waveService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+func (s *waveService) CreateWave(ctx context.Context, difficulty int, spawns []types.WaveSpawn) (types.Wave, error) {
+	s.logger.Debug("creating wave",
+		zap.Int("difficulty", difficulty),
+		zap.Int("spawns", len(spawns)))
+
+	rows, err := s.resolveSpawns(ctx, spawns)
+	if err != nil {
+		return types.Wave{}, err
+	}
+
+	wave, created, err := s.repo.CreateWave(ctx, int32(difficulty), rows)
+	if err != nil {
+		return types.Wave{}, err
+	}
+
+	return s.convertWave(wave, created), nil
+}
+
+// resolveSpawns checks every spawn has a positive count and refers to an
+// existing, non-retired alien template.
+func (s *waveService) resolveSpawns(ctx context.Context, spawns []types.WaveSpawn) ([]generated.WaveSpawn, error) {
+	rows := make([]generated.WaveSpawn, len(spawns))
+	ids := make([]uuid.UUID, 0, len(spawns))
+	for i, sp := range spawns {
+		if sp.Count <= 0 {
+			return nil, apperrors.NewInvalidInputError(
+				fmt.Sprintf("count for alien %s must be positive", sp.AlienID), nil)
+		}
+		alienID, err := uuid.Parse(sp.AlienID)
+		if err != nil {
+			return nil, apperrors.NewInvalidInputError(
+				fmt.Sprintf("alien_id %q is not a valid UUID", sp.AlienID), err)
+		}
+		rows[i] = generated.WaveSpawn{AlienID: alienID, Count: int32(sp.Count)}
+		ids = append(ids, alienID)
+	}
+
+	templates, err := s.repo.FindAlienTemplates(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[uuid.UUID]generated.AlienTemplate, len(templates))
+	for _, t := range templates {
+		known[t.ID] = t
+	}
+	for _, id := range ids {
+		t, ok := known[id]
+		if !ok {
+			return nil, apperrors.NewInvalidInputError(
+				fmt.Sprintf("alien template %s does not exist", id), nil)
+		}
+		if t.RetiredAt.Valid {
+			return nil, apperrors.NewInvalidInputError(
+				fmt.Sprintf("alien template %s is retired", id), nil)
+		}
+	}
+
+	return rows, nil
+}
+
+func (s *waveService) GetWave(ctx context.Context, id uuid.UUID) (types.Wave, error) {
+	s.logger.Debug("retrieving wave by ID", zap.String("wave_id", id.String()))
+
+	wave, spawns, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return types.Wave{}, err
+	}
+
+	return s.convertWave(wave, spawns), nil
+}
+
+func (s *waveService) ListWaves(ctx context.Context) ([]types.Wave, error) {
+	s.logger.Debug("retrieving all waves")
+
+	waves, spawns, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byWave := make(map[uuid.UUID][]generated.WaveSpawn, len(waves))
+	for _, sp := range spawns {
+		byWave[sp.WaveID] = append(byWave[sp.WaveID], sp)
+	}
+
+	result := make([]types.Wave, len(waves))
+	for i, w := range waves {
+		result[i] = s.convertWave(w, byWave[w.ID])
+	}
+
+	s.logger.Debug("successfully retrieved all waves", zap.Int("count", len(result)))
+	return result, nil
+}
+
+func (s *waveService) DeleteWave(ctx context.Context, id uuid.UUID) error {
+	s.logger.Debug("deleting wave", zap.String("wave_id", id.String()))
+	return s.repo.Delete(ctx, id)
+}
+
+// GenerateWave builds a wave for the given difficulty and seed, optionally
+// storing it so it can be fought by ID.
+func (s *waveService) GenerateWave(ctx context.Context, difficulty int, seed int64, save bool) (GeneratedWave, error) {
+	s.logger.Debug("generating wave",
+		zap.Int("difficulty", difficulty),
+		zap.Int64("seed", seed),
+		zap.Bool("save", save))
+
+	wave, err := s.generate(ctx, difficulty, seed)
+	if err != nil {
+		return GeneratedWave{}, err
+	}
+
+	if save {
+		wave, err = s.CreateWave(ctx, wave.Difficulty, wave.Aliens)
+		if err != nil {
+			return GeneratedWave{}, err
+		}
+	}
+
+	return GeneratedWave{Wave: wave, Seed: seed, Budget: wavegen.Budget(difficulty)}, nil
+}
+
+// NextWave generates the wave that follows the planet's current one. The
+// seed is derived from the planet and wave number, so it is stable until the
+// planet advances.
+func (s *waveService) NextWave(ctx context.Context, planetID uuid.UUID) (GeneratedWave, error) {
+	current, err := s.repo.GetPlanetCurrentWave(ctx, planetID)
+	if err != nil {
+		return GeneratedWave{}, err
+	}
+
+	seed := wavegen.SeedFor(planetID.String(), current+1)
+	difficulty := wavegen.ClampDifficulty(current + 1)
+
+	s.logger.Debug("generating next wave for planet",
+		zap.String("planet_id", planetID.String()),
+		zap.Int("difficulty", difficulty))
+
+	wave, err := s.generate(ctx, difficulty, seed)
+	if err != nil {
+		return GeneratedWave{}, err
+	}
+
+	return GeneratedWave{Wave: wave, Seed: seed, Budget: wavegen.Budget(difficulty)}, nil
+}
+
+func (s *waveService) generate(ctx context.Context, difficulty int, seed int64) (types.Wave, error) {
+	rows, err := s.repo.ListActiveAlienTemplates(ctx)
+	if err != nil {
+		return types.Wave{}, err
+	}
+
+	templates := make([]types.AlienTemplate, len(rows))
+	for i, row := range rows {
+		if templates[i], err = convertAlienTemplate(row); err != nil {
+			s.logger.Error("failed to decode alien template", zap.String("template_id", row.ID.String()), zap.Error(err))
+			return types.Wave{}, apperrors.NewInternalError("failed to decode alien template", err)
+		}
+	}
+
+	wave, err := wavegen.Generate(difficulty, seed, templates)
+	if err != nil {
+		if errors.Is(err, wavegen.ErrInvalidDifficulty) || errors.Is(err, wavegen.ErrDifficultyTooHigh) || errors.Is(err, wavegen.ErrNoTemplates) {
+			return types.Wave{}, apperrors.NewInvalidInputError(err.Error(), err)
+		}
+		return types.Wave{}, apperrors.NewInternalError("failed to generate wave", err)
+	}
+
+	return wave, nil
+}
+
+// Convert generated models to the shared wave type
+func (s *waveService) convertWave(wave generated.Wave, spawns []generated.WaveSpawn) types.Wave {
+	aliens := make([]types.WaveSpawn, len(spawns))
+	for i, sp := range spawns {
+		aliens[i] = types.WaveSpawn{
+			AlienID: sp.AlienID.String(),
+			Count:   int(sp.Count),
+		}
+	}
+
+	return types.Wave{
+		ID:         wave.ID.String(),
+		Difficulty: int(wave.Difficulty),
+		Aliens:     aliens,
+		CreatedAt:  wave.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,35 @@
+-- +goose Up
+CREATE TABLE alien_templates (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            TEXT NOT NULL,
+    hp              INT NOT NULL,
+    damage          INT NOT NULL,
+    speed           DOUBLE PRECISION NOT NULL,
+    behavior_type   TEXT NOT NULL DEFAULT 'basic',
+    resistances     JSONB NOT NULL DEFAULT '{}',
+    loot_drop       JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE TABLE waves (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    difficulty  INT NOT NULL DEFAULT 1,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE TABLE wave_spawns (
+    wave_id     UUID NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    alien_id    UUID NOT NULL REFERENCES alien_templates(id),
+    count       INT NOT NULL CHECK (count > 0),
+    PRIMARY KEY (wave_id, position)
+);
+
+-- index for finding waves that use an alien
+CREATE INDEX idx_wave_spawns_alien_id ON wave_spawns(alien_id);
+
+
+-- +goose Down
+DROP TABLE IF EXISTS wave_spawns;
+DROP TABLE IF EXISTS waves;
+DROP TABLE IF EXISTS alien_templates;
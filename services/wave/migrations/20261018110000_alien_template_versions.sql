@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE alien_templates
+    ADD COLUMN version      INT NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at   TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    ADD COLUMN retired_at   TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE alien_template_versions (
+    template_id     UUID NOT NULL REFERENCES alien_templates(id) ON DELETE CASCADE,
+    version         INT NOT NULL,
+    name            TEXT NOT NULL,
+    hp              INT NOT NULL,
+    damage          INT NOT NULL,
+    speed           DOUBLE PRECISION NOT NULL,
+    behavior_type   TEXT NOT NULL,
+    resistances     JSONB NOT NULL,
+    loot_drop       JSONB NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    PRIMARY KEY (template_id, version)
+);
+
+-- snapshot templates that existed before versioning
+INSERT INTO alien_template_versions (template_id, version, name, hp, damage, speed, behavior_type, resistances, loot_drop, created_at)
+SELECT id, version, name, hp, damage, speed, behavior_type, resistances, loot_drop, created_at
+FROM alien_templates;
+
+
+-- +goose Down
+DROP TABLE IF EXISTS alien_template_versions;
+
+ALTER TABLE alien_templates
+    DROP COLUMN retired_at,
+    DROP COLUMN updated_at,
+    DROP COLUMN version;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- the damage type of an alien's attacks, which decides how well shields stop it
+ALTER TABLE alien_templates
+    ADD COLUMN damage_type TEXT NOT NULL DEFAULT 'kinetic';
+
+ALTER TABLE alien_template_versions
+    ADD COLUMN damage_type TEXT NOT NULL DEFAULT 'kinetic';
+
+
+-- +goose Down
+ALTER TABLE alien_template_versions
+    DROP COLUMN damage_type;
+
+ALTER TABLE alien_templates
+    DROP COLUMN damage_type;
@@ -92,3 +92,19 @@ func NewAlreadyExistsError(resource string, details string) *AppError {
 		Err:     ErrAlreadyExists,
 	}
 }
+
+func NewUnauthorizedError(msg string) *AppError {
+	return &AppError{
+		Code:    "UNAUTHORIZED",
+		Message: msg,
+		Err:     ErrUnauthorized,
+	}
+}
+
+func NewForbiddenError(msg string) *AppError {
+	return &AppError{
+		Code:    "FORBIDDEN",
+		Message: msg,
+		Err:     ErrForbidden,
+	}
+}
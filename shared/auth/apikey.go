@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/shared/db/generated"
+)
+
+// Scopes an API key can be granted.
+const (
+	ScopePlanetsWrite = "planets:write"
+	ScopeBattlesRun   = "battles:run"
+)
+
+// Scopes lists every known scope.
+var Scopes = []string{ScopePlanetsWrite, ScopeBattlesRun}
+
+// apiKeyPrefix marks API keys so they are easy to tell apart from player
+// tokens, in requests and in leaked logs alike.
+const apiKeyPrefix = "sk_"
+
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKey is a machine credential for calls between services.
+type APIKey struct {
+	ID     uuid.UUID
+	Name   string
+	Scopes []string
+}
+
+// HasScope reports whether the key was granted scope.
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// KeyStore looks API keys up by their hash.
+type KeyStore interface {
+	// LookupAPIKey returns the active key hashed as hash, or
+	// ErrInvalidAPIKey if it is unknown, expired or revoked.
+	LookupAPIKey(ctx context.Context, hash string) (APIKey, error)
+}
+
+// NewAPIKey returns a random API key and the hash to store for it.
+func NewAPIKey() (key, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hash an API key is stored and looked up by.
+func HashAPIKey(key string) string {
+	return hashSecret(key)
+}
+
+type dbKeyStore struct {
+	q *generated.Queries
+}
+
+// NewKeyStore returns a KeyStore backed by the api_keys table.
+func NewKeyStore(q *generated.Queries) KeyStore {
+	return &dbKeyStore{q: q}
+}
+
+func (s *dbKeyStore) LookupAPIKey(ctx context.Context, hash string) (APIKey, error) {
+	key, err := s.q.GetActiveAPIKeyByHash(ctx, hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return APIKey{}, ErrInvalidAPIKey
+		}
+		return APIKey{}, err
+	}
+
+	return APIKey{ID: key.ID, Name: key.Name, Scopes: key.Scopes}, nil
+}
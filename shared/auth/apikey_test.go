@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	key, hash, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		t.Errorf("key %q lacks the %q prefix", key, apiKeyPrefix)
+	}
+	if hash != HashAPIKey(key) {
+		t.Error("NewAPIKey's hash doesn't match HashAPIKey")
+	}
+	if strings.Contains(hash, strings.TrimPrefix(key, apiKeyPrefix)) {
+		t.Error("the stored hash reveals the key")
+	}
+
+	other, otherHash, err := NewAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key || otherHash == hash {
+		t.Error("two API keys are the same")
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	if HashAPIKey("sk_a") != HashAPIKey("sk_a") {
+		t.Error("HashAPIKey is not stable")
+	}
+	if HashAPIKey("sk_a") == HashAPIKey("sk_b") {
+		t.Error("different keys hash the same")
+	}
+	if len(HashAPIKey("sk_a")) != 64 {
+		t.Errorf("hash %q is not hex SHA-256", HashAPIKey("sk_a"))
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	key := APIKey{Scopes: []string{ScopeBattlesRun}}
+	if !key.HasScope(ScopeBattlesRun) {
+		t.Error("key lacks the scope it was granted")
+	}
+	if key.HasScope(ScopePlanetsWrite) {
+		t.Error("key has a scope it wasn't granted")
+	}
+	if (APIKey{}).HasScope(ScopeBattlesRun) {
+		t.Error("key without scopes has a scope")
+	}
+}
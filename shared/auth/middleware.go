@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+// APIKeyHeader is the request header service callers send their API key in.
+const APIKeyHeader = "X-API-Key"
+
+type contextKey struct{}
+
+// Caller is who a request was made by: a player with an access token or
+// a service with an API key.
+type Caller struct {
+	PlayerID uuid.UUID
+	Role     string
+	APIKey   *APIKey // set for service callers
+}
+
+// IsAdmin reports whether the caller is a player who may act on any
+// player's planets.
+func (c Caller) IsAdmin() bool {
+	return c.APIKey == nil && c.Role == RoleAdmin
+}
+
+// IsService reports whether the caller authenticated with an API key.
+func (c Caller) IsService() bool {
+	return c.APIKey != nil
+}
+
+// WithCaller returns a copy of ctx carrying the authenticated caller.
+func WithCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// CallerFrom returns the authenticated caller carried by ctx.
+func CallerFrom(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(contextKey{}).(Caller)
+	return c, ok
+}
+
+// PlayerID returns the authenticated player carried by ctx. Service
+// callers have none.
+func PlayerID(ctx context.Context) (uuid.UUID, bool) {
+	c, ok := CallerFrom(ctx)
+	if !ok || c.IsService() {
+		return uuid.Nil, false
+	}
+	return c.PlayerID, true
+}
+
+// Authenticator identifies callers by a player access token or a service
+// API key.
+type Authenticator struct {
+	signer *Signer
+	keys   KeyStore
+}
+
+// NewAuthenticator returns an Authenticator. Without a key store only
+// player tokens are accepted.
+func NewAuthenticator(signer *Signer, keys KeyStore) *Authenticator {
+	return &Authenticator{signer: signer, keys: keys}
+}
+
+// Authenticate is middleware that rejects requests without valid
+// credentials and puts the caller into the request context. Players send
+// "Authorization: Bearer <access token>", services send their key in the
+// X-API-Key header.
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller, err := a.identify(r)
+		if err != nil {
+			response.WriteError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithCaller(r.Context(), caller)))
+	})
+}
+
+func (a *Authenticator) identify(r *http.Request) (Caller, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" && a.keys != nil {
+		apiKey, err := a.keys.LookupAPIKey(r.Context(), HashAPIKey(key))
+		if err != nil {
+			if errors.Is(err, ErrInvalidAPIKey) {
+				return Caller{}, apperrors.NewUnauthorizedError("invalid or expired API key")
+			}
+			return Caller{}, apperrors.NewInternalError("failed to check API key", err)
+		}
+		return Caller{APIKey: &apiKey}, nil
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		return Caller{}, apperrors.NewUnauthorizedError("missing bearer token or API key")
+	}
+
+	claims, err := a.signer.Verify(token, time.Now())
+	if err != nil {
+		if errors.Is(err, ErrExpiredToken) {
+			return Caller{}, apperrors.NewUnauthorizedError("access token expired")
+		}
+		return Caller{}, apperrors.NewUnauthorizedError("invalid access token")
+	}
+	return Caller{PlayerID: claims.PlayerID, Role: claims.Role}, nil
+}
+
+// RequireScope is middleware that lets service callers through only if
+// their API key was granted scope. Players are let through; what they may
+// change is decided per planet by the services. Use it after
+// Authenticate.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			caller, ok := CallerFrom(r.Context())
+			if !ok {
+				response.WriteError(w, apperrors.NewUnauthorizedError("authentication required"))
+				return
+			}
+			if caller.IsService() && !caller.APIKey.HasScope(scope) {
+				response.WriteError(w, apperrors.NewForbiddenError("API key lacks scope "+scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+// fakeKeyStore holds API keys by their raw value. The key "sk_broken"
+// fails the lookup as if the database were down.
+type fakeKeyStore map[string]APIKey
+
+func (s fakeKeyStore) LookupAPIKey(_ context.Context, hash string) (APIKey, error) {
+	if hash == HashAPIKey("sk_broken") {
+		return APIKey{}, errors.New("connection refused")
+	}
+	for key, apiKey := range s {
+		if HashAPIKey(key) == hash {
+			return apiKey, nil
+		}
+	}
+	return APIKey{}, ErrInvalidAPIKey
+}
+
+// callerHandler writes the caller it finds in the request context.
+var callerHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	caller, _ := CallerFrom(r.Context())
+	response.WriteSuccess(w, caller)
+})
+
+// expectErrorCode checks rec holds an error envelope with status and code.
+func expectErrorCode(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	var body response.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Success || body.Error == nil || body.Error.Code != code {
+		t.Fatalf("body = %s, want error code %s", rec.Body, code)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	signer := newTestSigner(t, time.Minute)
+	playerID := uuid.New()
+	valid, _, err := signer.Issue(playerID, RolePlayer, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, _, err := signer.Issue(playerID, RolePlayer, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	battles := APIKey{ID: uuid.New(), Name: "simulator", Scopes: []string{ScopeBattlesRun}}
+	authn := NewAuthenticator(signer, fakeKeyStore{"sk_battles": battles})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		status  int
+		code    string
+		want    Caller
+	}{
+		{name: "player token", headers: map[string]string{"Authorization": "Bearer " + valid},
+			status: http.StatusOK, want: Caller{PlayerID: playerID, Role: RolePlayer}},
+		{name: "lowercase scheme", headers: map[string]string{"Authorization": "bearer " + valid},
+			status: http.StatusOK, want: Caller{PlayerID: playerID, Role: RolePlayer}},
+		{name: "API key", headers: map[string]string{APIKeyHeader: "sk_battles"},
+			status: http.StatusOK, want: Caller{APIKey: &battles}},
+		{name: "API key wins over a token", headers: map[string]string{APIKeyHeader: "sk_battles", "Authorization": "Bearer " + valid},
+			status: http.StatusOK, want: Caller{APIKey: &battles}},
+		{name: "no credentials", status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "wrong scheme", headers: map[string]string{"Authorization": "Basic " + valid}, status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "expired token", headers: map[string]string{"Authorization": "Bearer " + expired}, status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "forged token", headers: map[string]string{"Authorization": "Bearer " + valid + "x"}, status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "unknown API key", headers: map[string]string{APIKeyHeader: "sk_unknown"}, status: http.StatusUnauthorized, code: "UNAUTHORIZED"},
+		{name: "key store down", headers: map[string]string{APIKeyHeader: "sk_broken"}, status: http.StatusInternalServerError, code: "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			authn.Authenticate(callerHandler).ServeHTTP(rec, req)
+
+			if tt.code != "" {
+				expectErrorCode(t, rec, tt.status, tt.code)
+				return
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.status, rec.Body)
+			}
+			var body struct {
+				Data Caller `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			got := body.Data
+			if got.PlayerID != tt.want.PlayerID || got.Role != tt.want.Role || (got.APIKey == nil) != (tt.want.APIKey == nil) ||
+				(got.APIKey != nil && got.APIKey.ID != tt.want.APIKey.ID) {
+				t.Errorf("caller = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateWithoutKeyStoreIgnoresAPIKeys(t *testing.T) {
+	authn := NewAuthenticator(newTestSigner(t, time.Minute), nil)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(APIKeyHeader, "sk_battles")
+	rec := httptest.NewRecorder()
+	authn.Authenticate(callerHandler).ServeHTTP(rec, req)
+
+	expectErrorCode(t, rec, http.StatusUnauthorized, "UNAUTHORIZED")
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		caller *Caller
+		status int
+		code   string
+	}{
+		{"player", &Caller{PlayerID: uuid.New(), Role: RolePlayer}, http.StatusOK, ""},
+		{"admin", &Caller{PlayerID: uuid.New(), Role: RoleAdmin}, http.StatusOK, ""},
+		{"key with the scope", &Caller{APIKey: &APIKey{Scopes: []string{ScopePlanetsWrite, ScopeBattlesRun}}}, http.StatusOK, ""},
+		{"key with another scope", &Caller{APIKey: &APIKey{Scopes: []string{ScopePlanetsWrite}}}, http.StatusForbidden, "FORBIDDEN"},
+		{"key without scopes", &Caller{APIKey: &APIKey{}}, http.StatusForbidden, "FORBIDDEN"},
+		{"no caller", nil, http.StatusUnauthorized, "UNAUTHORIZED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.caller != nil {
+				req = req.WithContext(WithCaller(req.Context(), *tt.caller))
+			}
+			rec := httptest.NewRecorder()
+			RequireScope(ScopeBattlesRun)(callerHandler).ServeHTTP(rec, req)
+
+			if tt.code != "" {
+				expectErrorCode(t, rec, tt.status, tt.code)
+				return
+			}
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
+
+func TestCallerRoles(t *testing.T) {
+	playerID := uuid.New()
+	tests := []struct {
+		name      string
+		caller    Caller
+		admin     bool
+		service   bool
+		hasPlayer bool
+	}{
+		{"player", Caller{PlayerID: playerID, Role: RolePlayer}, false, false, true},
+		{"admin", Caller{PlayerID: playerID, Role: RoleAdmin}, true, false, true},
+		{"service", Caller{APIKey: &APIKey{}}, false, true, false},
+		{"service claiming admin", Caller{Role: RoleAdmin, APIKey: &APIKey{}}, false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.caller.IsAdmin() != tt.admin || tt.caller.IsService() != tt.service {
+				t.Errorf("IsAdmin %v, IsService %v; want %v, %v", tt.caller.IsAdmin(), tt.caller.IsService(), tt.admin, tt.service)
+			}
+			id, ok := PlayerID(WithCaller(context.Background(), tt.caller))
+			if ok != tt.hasPlayer || (ok && id != playerID) {
+				t.Errorf("PlayerID = %s, %v; want a player: %v", id, ok, tt.hasPlayer)
+			}
+		})
+	}
+
+	if _, ok := CallerFrom(context.Background()); ok {
+		t.Error("CallerFrom found a caller in an empty context")
+	}
+}
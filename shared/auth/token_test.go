@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var testSecret = []byte(strings.Repeat("s", MinSecretLength))
+
+func newTestSigner(t *testing.T, ttl time.Duration) *Signer {
+	t.Helper()
+
+	s, err := NewSigner(testSecret, ttl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestNewSignerRejectsShortSecrets(t *testing.T) {
+	for _, secret := range []string{"", "short", strings.Repeat("s", MinSecretLength-1)} {
+		if _, err := NewSigner([]byte(secret), time.Minute); err == nil {
+			t.Errorf("NewSigner accepted a %d byte secret", len(secret))
+		}
+	}
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	s := newTestSigner(t, 15*time.Minute)
+	playerID := uuid.New()
+	now := time.Unix(1_800_000_000, 0)
+
+	token, issued, err := s.Issue(playerID, RoleAdmin, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Claims{PlayerID: playerID, Role: RoleAdmin, IssuedAt: now.Unix(), ExpiresAt: now.Add(15 * time.Minute).Unix()}
+	if issued != want {
+		t.Fatalf("Issue claims = %+v, want %+v", issued, want)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want error
+	}{
+		{"just issued", now, nil},
+		{"about to expire", now.Add(15*time.Minute - time.Second), nil},
+		{"at expiry", now.Add(15 * time.Minute), ErrExpiredToken},
+		{"long expired", now.Add(24 * time.Hour), ErrExpiredToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := s.Verify(token, tt.at)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify err = %v, want %v", err, tt.want)
+			}
+			if err == nil && claims != want {
+				t.Errorf("Verify claims = %+v, want %+v", claims, want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsForgedTokens(t *testing.T) {
+	s := newTestSigner(t, time.Hour)
+	now := time.Now()
+	token, _, err := s.Issue(uuid.New(), RolePlayer, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+
+	// A player promoting themselves to admin, keeping the signature.
+	admin, _, err := s.Issue(uuid.New(), RoleAdmin, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminPayload, _, _ := strings.Cut(admin, ".")
+
+	other, err := NewSigner([]byte(strings.Repeat("o", MinSecretLength)), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherToken, _, err := other.Issue(uuid.New(), RolePlayer, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"empty":            "",
+		"no signature":     payload,
+		"empty signature":  payload + ".",
+		"swapped payload":  adminPayload + "." + sig,
+		"bad base64":       payload + ".!!!",
+		"other secret":     otherToken,
+		"payload not JSON": "bm90IGpzb24." + sig,
+		"trailing garbage": token + "x",
+	}
+	for name, forged := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Verify(forged, now); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestRefreshTokens(t *testing.T) {
+	token, hash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != HashRefreshToken(token) {
+		t.Error("NewRefreshToken's hash doesn't match HashRefreshToken")
+	}
+	if hash == token || strings.Contains(hash, token) {
+		t.Error("the stored hash reveals the token")
+	}
+
+	next, nextHash, err := NewRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == token || nextHash == hash {
+		t.Error("two refresh tokens are the same")
+	}
+}
@@ -9,21 +9,178 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Planet struct {
+type AlienTemplate struct {
 	ID           uuid.UUID          `json:"id"`
-	PlayerID     uuid.UUID          `json:"player_id"`
 	Name         string             `json:"name"`
-	Resources    pgtype.Int4        `json:"resources"`
-	DefenseLevel pgtype.Int4        `json:"defense_level"`
-	CurrentWave  pgtype.Int4        `json:"current_wave"`
-	Health       pgtype.Int4        `json:"health"`
+	Hp           int32              `json:"hp"`
+	Damage       int32              `json:"damage"`
+	Speed        float64            `json:"speed"`
+	BehaviorType string             `json:"behavior_type"`
+	Resistances  []byte             `json:"resistances"`
+	LootDrop     []byte             `json:"loot_drop"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	Version      int32              `json:"version"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	RetiredAt    pgtype.Timestamptz `json:"retired_at"`
+	DamageType   string             `json:"damage_type"`
+}
+
+type AlienTemplateVersion struct {
+	TemplateID   uuid.UUID          `json:"template_id"`
+	Version      int32              `json:"version"`
+	Name         string             `json:"name"`
+	Hp           int32              `json:"hp"`
+	Damage       int32              `json:"damage"`
+	Speed        float64            `json:"speed"`
+	BehaviorType string             `json:"behavior_type"`
+	Resistances  []byte             `json:"resistances"`
+	LootDrop     []byte             `json:"loot_drop"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	DamageType   string             `json:"damage_type"`
+}
+
+type ApiKey struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	KeyHash     string             `json:"key_hash"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+	RotatedFrom pgtype.UUID        `json:"rotated_from"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Battle struct {
+	ID               uuid.UUID          `json:"id"`
+	PlanetID         uuid.UUID          `json:"planet_id"`
+	WaveID           pgtype.UUID        `json:"wave_id"`
+	WaveNumber       int32              `json:"wave_number"`
+	Seed             int64              `json:"seed"`
+	Outcome          string             `json:"outcome"`
+	DamageTaken      int32              `json:"damage_taken"`
+	ShieldsRemaining int32              `json:"shields_remaining"`
+	HpRemaining      int32              `json:"hp_remaining"`
+	AliensDestroyed  int32              `json:"aliens_destroyed"`
+	Loot             []byte             `json:"loot"`
+	Events           []byte             `json:"events"`
+	Snapshot         []byte             `json:"snapshot"`
+	IdempotencyKey   pgtype.Text        `json:"idempotency_key"`
+	FoughtAt         pgtype.Timestamptz `json:"fought_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type DefenseSystem struct {
+	ID                 uuid.UUID          `json:"id"`
+	PlanetID           uuid.UUID          `json:"planet_id"`
+	Kind               string             `json:"kind"`
+	Name               string             `json:"name"`
+	Damage             int32              `json:"damage"`
+	Range              int32              `json:"range"`
+	FireRate           float64            `json:"fire_rate"`
+	DamageType         string             `json:"damage_type"`
+	Level              int32              `json:"level"`
+	UpgradeCost        []byte             `json:"upgrade_cost"`
+	Position           int32              `json:"position"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	UpgradeStartedAt   pgtype.Timestamptz `json:"upgrade_started_at"`
+	UpgradeCompletesAt pgtype.Timestamptz `json:"upgrade_completes_at"`
+}
+
+type Job struct {
+	ID          uuid.UUID          `json:"id"`
+	PlanetID    uuid.UUID          `json:"planet_id"`
+	Kind        string             `json:"kind"`
+	TargetID    uuid.UUID          `json:"target_id"`
+	TargetLevel int32              `json:"target_level"`
+	RunAt       pgtype.Timestamptz `json:"run_at"`
+	Status      string             `json:"status"`
+	Attempts    int32              `json:"attempts"`
+	LastError   pgtype.Text        `json:"last_error"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+	CompletedAt pgtype.Timestamptz `json:"completed_at"`
+}
+
+type Planet struct {
+	ID               uuid.UUID          `json:"id"`
+	PlayerID         uuid.UUID          `json:"player_id"`
+	Name             string             `json:"name"`
+	Resources        []byte             `json:"resources"`
+	DefenseLevel     int32              `json:"defense_level"`
+	CurrentWave      int32              `json:"current_wave"`
+	Health           int32              `json:"health"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	MaxHealth        int32              `json:"max_health"`
+	Shields          int32              `json:"shields"`
+	MaxShields       int32              `json:"max_shields"`
+	ShieldRegen      float64            `json:"shield_regen"`
+	ShieldRegenDelay float64            `json:"shield_regen_delay"`
+	Status           string             `json:"status"`
+	DestroyedAt      pgtype.Timestamptz `json:"destroyed_at"`
+}
+
+type PlanetBuilding struct {
+	ID               uuid.UUID          `json:"id"`
+	PlanetID         uuid.UUID          `json:"planet_id"`
+	Kind             string             `json:"kind"`
+	Level            int32              `json:"level"`
+	BuildStartedAt   pgtype.Timestamptz `json:"build_started_at"`
+	BuildCompletesAt pgtype.Timestamptz `json:"build_completes_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type PlanetResearch struct {
+	ID                  uuid.UUID          `json:"id"`
+	PlanetID            uuid.UUID          `json:"planet_id"`
+	Tech                string             `json:"tech"`
+	Level               int32              `json:"level"`
+	ResearchStartedAt   pgtype.Timestamptz `json:"research_started_at"`
+	ResearchCompletesAt pgtype.Timestamptz `json:"research_completes_at"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Player struct {
+	ID           uuid.UUID          `json:"id"`
+	Username     string             `json:"username"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+	PasswordHash pgtype.Text        `json:"password_hash"`
+	Role         string             `json:"role"`
+}
+
+type RefreshToken struct {
 	ID        uuid.UUID          `json:"id"`
-	Username  string             `json:"username"`
+	PlayerID  uuid.UUID          `json:"player_id"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ResourceLedger struct {
+	ID           uuid.UUID          `json:"id"`
+	PlanetID     uuid.UUID          `json:"planet_id"`
+	Source       string             `json:"source"`
+	ReferenceID  uuid.UUID          `json:"reference_id"`
+	Base         []byte             `json:"base"`
+	BonusPercent int32              `json:"bonus_percent"`
+	Amount       []byte             `json:"amount"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type Wave struct {
+	ID         uuid.UUID          `json:"id"`
+	Difficulty int32              `json:"difficulty"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type WaveSpawn struct {
+	WaveID   uuid.UUID `json:"wave_id"`
+	Position int32     `json:"position"`
+	AlienID  uuid.UUID `json:"alien_id"`
+	Count    int32     `json:"count"`
 }
@@ -15,7 +15,7 @@ import (
 const createPlanet = `-- name: CreatePlanet :one
 INSERT INTO planets (player_id, name)
 VALUES ($1, $2)
-RETURNING id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at
+RETURNING id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at
 `
 
 type CreatePlanetParams struct {
@@ -36,22 +36,81 @@ func (q *Queries) CreatePlanet(ctx context.Context, arg CreatePlanetParams) (Pla
 		&i.Health,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
 	)
 	return i, err
 }
 
-const deletePlanet = `-- name: DeletePlanet :exec
+const creditPlanetResources = `-- name: CreditPlanetResources :exec
+UPDATE planets
+SET resources = jsonb_build_object(
+        'minerals', COALESCE((resources->>'minerals')::int, 0) + COALESCE(($1::jsonb->>'minerals')::int, 0),
+        'energy', COALESCE((resources->>'energy')::int, 0) + COALESCE(($1::jsonb->>'energy')::int, 0),
+        'tech_parts', COALESCE((resources->>'tech_parts')::int, 0) + COALESCE(($1::jsonb->>'tech_parts')::int, 0)
+    )
+WHERE id = $2
+`
+
+type CreditPlanetResourcesParams struct {
+	Amount []byte    `json:"amount"`
+	ID     uuid.UUID `json:"id"`
+}
+
+func (q *Queries) CreditPlanetResources(ctx context.Context, arg CreditPlanetResourcesParams) error {
+	_, err := q.db.Exec(ctx, creditPlanetResources, arg.Amount, arg.ID)
+	return err
+}
+
+const deletePlanet = `-- name: DeletePlanet :execrows
 DELETE FROM planets
 WHERE id = $1
 `
 
-func (q *Queries) DeletePlanet(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deletePlanet, id)
-	return err
+func (q *Queries) DeletePlanet(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deletePlanet, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getPlanetByID = `-- name: GetPlanetByID :one
+SELECT id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at FROM planets
+WHERE id = $1
+`
+
+func (q *Queries) GetPlanetByID(ctx context.Context, id uuid.UUID) (Planet, error) {
+	row := q.db.QueryRow(ctx, getPlanetByID, id)
+	var i Planet
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.Resources,
+		&i.DefenseLevel,
+		&i.CurrentWave,
+		&i.Health,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
+	)
+	return i, err
 }
 
 const getPlanetByPlayerID = `-- name: GetPlanetByPlayerID :one
-SELECT id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at FROM planets
+SELECT id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at FROM planets
 WHERE player_id = $1
 `
 
@@ -68,35 +127,204 @@ func (q *Queries) GetPlanetByPlayerID(ctx context.Context, playerID uuid.UUID) (
 		&i.Health,
 		&i.UpdatedAt,
 		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
+	)
+	return i, err
+}
+
+const getPlanetForUpdate = `-- name: GetPlanetForUpdate :one
+SELECT id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at FROM planets
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetPlanetForUpdate(ctx context.Context, id uuid.UUID) (Planet, error) {
+	row := q.db.QueryRow(ctx, getPlanetForUpdate, id)
+	var i Planet
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.Resources,
+		&i.DefenseLevel,
+		&i.CurrentWave,
+		&i.Health,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
+	)
+	return i, err
+}
+
+const rebuildPlanet = `-- name: RebuildPlanet :one
+UPDATE planets
+SET resources = $2,
+    health = $3,
+    shields = 0,
+    status = 'active',
+    destroyed_at = NULL,
+    updated_at = $4
+WHERE id = $1 AND status = 'destroyed'
+RETURNING id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at
+`
+
+type RebuildPlanetParams struct {
+	ID        uuid.UUID          `json:"id"`
+	Resources []byte             `json:"resources"`
+	Health    int32              `json:"health"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) RebuildPlanet(ctx context.Context, arg RebuildPlanetParams) (Planet, error) {
+	row := q.db.QueryRow(ctx, rebuildPlanet,
+		arg.ID,
+		arg.Resources,
+		arg.Health,
+		arg.UpdatedAt,
+	)
+	var i Planet
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.Resources,
+		&i.DefenseLevel,
+		&i.CurrentWave,
+		&i.Health,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
 	)
 	return i, err
 }
 
-const updatePlanetState = `-- name: UpdatePlanetState :exec
+const updatePlanetResources = `-- name: UpdatePlanetResources :exec
 UPDATE planets
 SET resources = $2,
-    defense_level = $3,
-    current_wave = $4,
-    health = $5,
-    updated_at = now()
+    health = $3,
+    updated_at = $4
 WHERE id = $1
 `
 
+type UpdatePlanetResourcesParams struct {
+	ID        uuid.UUID          `json:"id"`
+	Resources []byte             `json:"resources"`
+	Health    int32              `json:"health"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+func (q *Queries) UpdatePlanetResources(ctx context.Context, arg UpdatePlanetResourcesParams) error {
+	_, err := q.db.Exec(ctx, updatePlanetResources,
+		arg.ID,
+		arg.Resources,
+		arg.Health,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
+const updatePlanetState = `-- name: UpdatePlanetState :one
+UPDATE planets
+SET name = $2,
+    resources = $3,
+    defense_level = $4,
+    current_wave = $5,
+    health = $6,
+    shields = $7,
+    status = $8,
+    destroyed_at = CASE WHEN $8 = 'destroyed' THEN COALESCE(destroyed_at, now()) END,
+    updated_at = $9
+WHERE id = $1 AND (status = 'active' OR $8 = 'destroyed')
+RETURNING id, player_id, name, resources, defense_level, current_wave, health, updated_at, created_at, max_health, shields, max_shields, shield_regen, shield_regen_delay, status, destroyed_at
+`
+
 type UpdatePlanetStateParams struct {
-	ID           uuid.UUID   `json:"id"`
-	Resources    pgtype.Int4 `json:"resources"`
-	DefenseLevel pgtype.Int4 `json:"defense_level"`
-	CurrentWave  pgtype.Int4 `json:"current_wave"`
-	Health       pgtype.Int4 `json:"health"`
+	ID           uuid.UUID          `json:"id"`
+	Name         string             `json:"name"`
+	Resources    []byte             `json:"resources"`
+	DefenseLevel int32              `json:"defense_level"`
+	CurrentWave  int32              `json:"current_wave"`
+	Health       int32              `json:"health"`
+	Shields      int32              `json:"shields"`
+	Status       string             `json:"status"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 }
 
-func (q *Queries) UpdatePlanetState(ctx context.Context, arg UpdatePlanetStateParams) error {
-	_, err := q.db.Exec(ctx, updatePlanetState,
+func (q *Queries) UpdatePlanetState(ctx context.Context, arg UpdatePlanetStateParams) (Planet, error) {
+	row := q.db.QueryRow(ctx, updatePlanetState,
 		arg.ID,
+		arg.Name,
 		arg.Resources,
 		arg.DefenseLevel,
 		arg.CurrentWave,
 		arg.Health,
+		arg.Shields,
+		arg.Status,
+		arg.UpdatedAt,
+	)
+	var i Planet
+	err := row.Scan(
+		&i.ID,
+		&i.PlayerID,
+		&i.Name,
+		&i.Resources,
+		&i.DefenseLevel,
+		&i.CurrentWave,
+		&i.Health,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+		&i.MaxHealth,
+		&i.Shields,
+		&i.MaxShields,
+		&i.ShieldRegen,
+		&i.ShieldRegenDelay,
+		&i.Status,
+		&i.DestroyedAt,
+	)
+	return i, err
+}
+
+const upgradePlanetShields = `-- name: UpgradePlanetShields :exec
+UPDATE planets
+SET max_shields = max_shields + $1,
+    shield_regen = shield_regen + $2,
+    shield_regen_delay = GREATEST(shield_regen_delay - $3, $4::double precision)
+WHERE id = $5
+`
+
+type UpgradePlanetShieldsParams struct {
+	MaxShieldsBonus int32     `json:"max_shields_bonus"`
+	RegenBonus      float64   `json:"regen_bonus"`
+	DelayReduction  float64   `json:"delay_reduction"`
+	MinRegenDelay   float64   `json:"min_regen_delay"`
+	ID              uuid.UUID `json:"id"`
+}
+
+func (q *Queries) UpgradePlanetShields(ctx context.Context, arg UpgradePlanetShieldsParams) error {
+	_, err := q.db.Exec(ctx, upgradePlanetShields,
+		arg.MaxShieldsBonus,
+		arg.RegenBonus,
+		arg.DelayReduction,
+		arg.MinRegenDelay,
+		arg.ID,
 	)
 	return err
 }
@@ -9,28 +9,36 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createPlayer = `-- name: CreatePlayer :one
-INSERT INTO players (username)
-VALUES ($1)
-RETURNING id, username, created_at, updated_at
+INSERT INTO players (username, password_hash)
+VALUES ($1, $2)
+RETURNING id, username, created_at, updated_at, password_hash, role
 `
 
-func (q *Queries) CreatePlayer(ctx context.Context, username string) (Player, error) {
-	row := q.db.QueryRow(ctx, createPlayer, username)
+type CreatePlayerParams struct {
+	Username     string      `json:"username"`
+	PasswordHash pgtype.Text `json:"password_hash"`
+}
+
+func (q *Queries) CreatePlayer(ctx context.Context, arg CreatePlayerParams) (Player, error) {
+	row := q.db.QueryRow(ctx, createPlayer, arg.Username, arg.PasswordHash)
 	var i Player
 	err := row.Scan(
 		&i.ID,
 		&i.Username,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
 
 const getPlayerByID = `-- name: GetPlayerByID :one
-SELECT id, username, created_at, updated_at FROM players
+SELECT id, username, created_at, updated_at, password_hash, role FROM players
 WHERE id = $1
 `
 
@@ -42,17 +50,102 @@ func (q *Queries) GetPlayerByID(ctx context.Context, id uuid.UUID) (Player, erro
 		&i.Username,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getPlayerByUsername = `-- name: GetPlayerByUsername :one
+SELECT id, username, created_at, updated_at, password_hash, role FROM players
+WHERE username = $1
+`
+
+func (q *Queries) GetPlayerByUsername(ctx context.Context, username string) (Player, error) {
+	row := q.db.QueryRow(ctx, getPlayerByUsername, username)
+	var i Player
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PasswordHash,
+		&i.Role,
 	)
 	return i, err
 }
 
 const listPlayers = `-- name: ListPlayers :many
-SELECT id, username, created_at, updated_at FROM players
-ORDER BY created_at DESC
+SELECT id, username, created_at, updated_at, password_hash, role FROM players
+WHERE ($1::text IS NULL OR username LIKE $1::text || '%')
+  AND ($2::timestamptz IS NULL
+       OR (created_at, id) < ($2::timestamptz, $3::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $4
 `
 
-func (q *Queries) ListPlayers(ctx context.Context) ([]Player, error) {
-	rows, err := q.db.Query(ctx, listPlayers)
+type ListPlayersParams struct {
+	UsernamePrefix pgtype.Text        `json:"username_prefix"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	PageSize       int32              `json:"page_size"`
+}
+
+func (q *Queries) ListPlayers(ctx context.Context, arg ListPlayersParams) ([]Player, error) {
+	rows, err := q.db.Query(ctx, listPlayers,
+		arg.UsernamePrefix,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Player
+	for rows.Next() {
+		var i Player
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PasswordHash,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPlayersOldestFirst = `-- name: ListPlayersOldestFirst :many
+SELECT id, username, created_at, updated_at, password_hash, role FROM players
+WHERE ($1::text IS NULL OR username LIKE $1::text || '%')
+  AND ($2::timestamptz IS NULL
+       OR (created_at, id) > ($2::timestamptz, $3::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListPlayersOldestFirstParams struct {
+	UsernamePrefix pgtype.Text        `json:"username_prefix"`
+	AfterCreatedAt pgtype.Timestamptz `json:"after_created_at"`
+	AfterID        pgtype.UUID        `json:"after_id"`
+	PageSize       int32              `json:"page_size"`
+}
+
+func (q *Queries) ListPlayersOldestFirst(ctx context.Context, arg ListPlayersOldestFirstParams) ([]Player, error) {
+	rows, err := q.db.Query(ctx, listPlayersOldestFirst,
+		arg.UsernamePrefix,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +158,8 @@ func (q *Queries) ListPlayers(ctx context.Context) ([]Player, error) {
 			&i.Username,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.PasswordHash,
+			&i.Role,
 		); err != nil {
 			return nil, err
 		}
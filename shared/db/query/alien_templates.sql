@@ -0,0 +1,48 @@
+-- name: CreateAlienTemplate :one
+INSERT INTO alien_templates (name, hp, damage, speed, behavior_type, resistances, loot_drop, damage_type)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAlienTemplateByID :one
+SELECT * FROM alien_templates
+WHERE id = $1;
+
+-- name: ListAlienTemplates :many
+SELECT * FROM alien_templates
+WHERE @include_retired::boolean OR retired_at IS NULL
+ORDER BY name, created_at;
+
+-- name: ListAlienTemplatesByIDs :many
+SELECT * FROM alien_templates
+WHERE id = ANY(@ids::uuid[]);
+
+-- name: UpdateAlienTemplate :one
+UPDATE alien_templates
+SET name = $2,
+    hp = $3,
+    damage = $4,
+    speed = $5,
+    behavior_type = $6,
+    resistances = $7,
+    loot_drop = $8,
+    damage_type = $9,
+    version = version + 1,
+    updated_at = now()
+WHERE id = $1 AND retired_at IS NULL
+RETURNING *;
+
+-- name: RetireAlienTemplate :one
+UPDATE alien_templates
+SET retired_at = now(),
+    updated_at = now()
+WHERE id = $1 AND retired_at IS NULL
+RETURNING *;
+
+-- name: CreateAlienTemplateVersion :exec
+INSERT INTO alien_template_versions (template_id, version, name, hp, damage, speed, behavior_type, resistances, loot_drop, damage_type)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: ListAlienTemplateVersions :many
+SELECT * FROM alien_template_versions
+WHERE template_id = $1
+ORDER BY version DESC;
@@ -0,0 +1,30 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (name, key_hash, scopes, expires_at, rotated_from)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAPIKeyByID :one
+SELECT * FROM api_keys
+WHERE id = $1;
+
+-- name: GetActiveAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > now());
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+ORDER BY created_at DESC, id DESC;
+
+-- name: ExpireAPIKey :one
+UPDATE api_keys
+SET expires_at = LEAST(expires_at, @expires_at::timestamptz)
+WHERE id = @id AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
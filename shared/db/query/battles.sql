@@ -0,0 +1,28 @@
+-- name: CreateBattle :one
+INSERT INTO battles (
+    planet_id, wave_id, wave_number, seed, outcome, damage_taken, shields_remaining,
+    hp_remaining, aliens_destroyed, loot, events, snapshot, idempotency_key, fought_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: GetBattleByID :one
+SELECT * FROM battles
+WHERE id = $1;
+
+-- name: GetBattleByIdempotencyKey :one
+SELECT * FROM battles
+WHERE planet_id = $1 AND idempotency_key = $2;
+
+-- name: ListPlanetBattles :many
+SELECT id, planet_id, wave_id, wave_number, seed, outcome, damage_taken, shields_remaining,
+       hp_remaining, aliens_destroyed, loot, fought_at, created_at
+FROM battles
+WHERE planet_id = @planet_id
+  AND (sqlc.narg(outcome)::text IS NULL OR outcome = sqlc.narg(outcome)::text)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR fought_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR fought_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
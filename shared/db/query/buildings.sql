@@ -0,0 +1,27 @@
+-- name: ListPlanetBuildings :many
+SELECT * FROM planet_buildings
+WHERE planet_id = $1
+ORDER BY kind;
+
+-- name: GetPlanetBuildingForUpdate :one
+SELECT * FROM planet_buildings
+WHERE planet_id = $1 AND kind = $2
+FOR UPDATE;
+
+-- name: UpsertPlanetBuilding :one
+INSERT INTO planet_buildings (planet_id, kind, level, build_started_at, build_completes_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (planet_id, kind) DO UPDATE
+SET level = EXCLUDED.level,
+    build_started_at = EXCLUDED.build_started_at,
+    build_completes_at = EXCLUDED.build_completes_at,
+    updated_at = now()
+RETURNING *;
+
+-- name: FinishPlanetBuilding :execrows
+UPDATE planet_buildings
+SET level = $2,
+    build_started_at = NULL,
+    build_completes_at = NULL,
+    updated_at = now()
+WHERE id = $1 AND level = $2 - 1 AND build_completes_at IS NOT NULL;
@@ -0,0 +1,66 @@
+-- name: CreateDefenseSystem :one
+INSERT INTO defense_systems (planet_id, kind, name, damage, range, fire_rate, damage_type, upgrade_cost, position)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetDefenseSystem :one
+SELECT * FROM defense_systems
+WHERE id = $1 AND planet_id = $2;
+
+-- name: GetDefenseSystemForUpdate :one
+SELECT * FROM defense_systems
+WHERE id = $1 AND planet_id = $2
+FOR UPDATE;
+
+-- name: GetDefenseSystemAtPosition :one
+SELECT * FROM defense_systems
+WHERE planet_id = $1 AND position = $2
+FOR UPDATE;
+
+-- name: ListDefenseSystemsByPlanetID :many
+SELECT * FROM defense_systems
+WHERE planet_id = $1
+ORDER BY position;
+
+-- name: ListDefenseSystemsByPlanetIDForShare :many
+SELECT * FROM defense_systems
+WHERE planet_id = $1
+ORDER BY position
+FOR SHARE;
+
+-- name: UpdateDefenseSystemPosition :exec
+UPDATE defense_systems
+SET position = $2,
+    updated_at = now()
+WHERE id = $1;
+
+-- name: StartDefenseUpgrade :one
+UPDATE defense_systems
+SET upgrade_started_at = $2,
+    upgrade_completes_at = $3,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: FinishDefenseUpgrade :execrows
+UPDATE defense_systems
+SET level = $2,
+    damage = $3,
+    range = $4,
+    fire_rate = $5,
+    upgrade_cost = $6,
+    upgrade_started_at = NULL,
+    upgrade_completes_at = NULL,
+    updated_at = now()
+WHERE id = $1 AND level = $2 - 1 AND upgrade_completes_at IS NOT NULL;
+
+-- name: CancelDefenseUpgrade :execrows
+UPDATE defense_systems
+SET upgrade_started_at = NULL,
+    upgrade_completes_at = NULL,
+    updated_at = now()
+WHERE id = $1 AND level = $2 - 1 AND upgrade_completes_at IS NOT NULL;
+
+-- name: DeleteDefenseSystem :execrows
+DELETE FROM defense_systems
+WHERE id = $1 AND planet_id = $2;
@@ -0,0 +1,28 @@
+-- name: CreateJob :one
+INSERT INTO jobs (planet_id, kind, target_id, target_level, run_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ClaimDueJob :one
+SELECT * FROM jobs
+WHERE status = 'pending' AND run_at <= now()
+ORDER BY run_at
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done',
+    attempts = attempts + 1,
+    completed_at = now(),
+    updated_at = now()
+WHERE id = $1;
+
+-- name: FailJob :exec
+UPDATE jobs
+SET status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    run_at = $4,
+    updated_at = now()
+WHERE id = $1;
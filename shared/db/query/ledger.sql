@@ -0,0 +1,16 @@
+-- name: CreateLedgerEntry :one
+INSERT INTO resource_ledger (planet_id, source, reference_id, base, bonus_percent, amount)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetLedgerEntry :one
+SELECT * FROM resource_ledger
+WHERE source = $1 AND reference_id = $2;
+
+-- name: ListPlanetLedger :many
+SELECT * FROM resource_ledger
+WHERE planet_id = @planet_id
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
@@ -3,19 +3,67 @@ INSERT INTO planets (player_id, name)
 VALUES ($1, $2)
 RETURNING *;
 
+-- name: GetPlanetByID :one
+SELECT * FROM planets
+WHERE id = $1;
+
+-- name: GetPlanetForUpdate :one
+SELECT * FROM planets
+WHERE id = $1
+FOR UPDATE;
+
 -- name: GetPlanetByPlayerID :one
 SELECT * FROM planets
 WHERE player_id = $1;
 
--- name: UpdatePlanetState :exec
+-- name: UpdatePlanetState :one
+UPDATE planets
+SET name = $2,
+    resources = $3,
+    defense_level = $4,
+    current_wave = $5,
+    health = $6,
+    shields = $7,
+    status = $8,
+    destroyed_at = CASE WHEN $8 = 'destroyed' THEN COALESCE(destroyed_at, now()) END,
+    updated_at = $9
+WHERE id = $1 AND (status = 'active' OR $8 = 'destroyed')
+RETURNING *;
+
+-- name: UpdatePlanetResources :exec
 UPDATE planets
 SET resources = $2,
-    defense_level = $3,
-    current_wave = $4,
-    health = $5,
-    updated_at = now()
+    health = $3,
+    updated_at = $4
 WHERE id = $1;
 
--- name: DeletePlanet :exec
+-- name: RebuildPlanet :one
+UPDATE planets
+SET resources = $2,
+    health = $3,
+    shields = 0,
+    status = 'active',
+    destroyed_at = NULL,
+    updated_at = $4
+WHERE id = $1 AND status = 'destroyed'
+RETURNING *;
+
+-- name: DeletePlanet :execrows
 DELETE FROM planets
 WHERE id = $1;
+
+-- name: UpgradePlanetShields :exec
+UPDATE planets
+SET max_shields = max_shields + @max_shields_bonus,
+    shield_regen = shield_regen + @regen_bonus,
+    shield_regen_delay = GREATEST(shield_regen_delay - @delay_reduction, @min_regen_delay::double precision)
+WHERE id = @id;
+
+-- name: CreditPlanetResources :exec
+UPDATE planets
+SET resources = jsonb_build_object(
+        'minerals', COALESCE((resources->>'minerals')::int, 0) + COALESCE((@amount::jsonb->>'minerals')::int, 0),
+        'energy', COALESCE((resources->>'energy')::int, 0) + COALESCE((@amount::jsonb->>'energy')::int, 0),
+        'tech_parts', COALESCE((resources->>'tech_parts')::int, 0) + COALESCE((@amount::jsonb->>'tech_parts')::int, 0)
+    )
+WHERE id = @id;
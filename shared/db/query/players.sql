@@ -1,12 +1,28 @@
 -- name: CreatePlayer :one
-INSERT INTO players (username)
-VALUES ($1)
+INSERT INTO players (username, password_hash)
+VALUES ($1, $2)
 RETURNING *;
 
 -- name: GetPlayerByID :one
 SELECT * FROM players
 WHERE id = $1;
 
+-- name: GetPlayerByUsername :one
+SELECT * FROM players
+WHERE username = $1;
+
 -- name: ListPlayers :many
 SELECT * FROM players
-ORDER BY created_at DESC;
+WHERE (sqlc.narg(username_prefix)::text IS NULL OR username LIKE sqlc.narg(username_prefix)::text || '%')
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT @page_size;
+
+-- name: ListPlayersOldestFirst :many
+SELECT * FROM players
+WHERE (sqlc.narg(username_prefix)::text IS NULL OR username LIKE sqlc.narg(username_prefix)::text || '%')
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT @page_size;
@@ -0,0 +1,10 @@
+-- name: CreateRefreshToken :one
+INSERT INTO refresh_tokens (player_id, token_hash, expires_at)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ConsumeRefreshToken :one
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > now()
+RETURNING *;
@@ -0,0 +1,28 @@
+-- name: ListPlanetResearch :many
+SELECT * FROM planet_research
+WHERE planet_id = $1
+ORDER BY tech;
+
+-- name: GetPlanetResearchForUpdate :one
+SELECT * FROM planet_research
+WHERE planet_id = $1 AND tech = $2
+FOR UPDATE;
+
+-- name: UpsertPlanetResearch :one
+INSERT INTO planet_research (planet_id, tech, level, research_started_at, research_completes_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (planet_id, tech) DO UPDATE
+SET level = EXCLUDED.level,
+    research_started_at = EXCLUDED.research_started_at,
+    research_completes_at = EXCLUDED.research_completes_at,
+    updated_at = now()
+RETURNING *;
+
+-- name: FinishPlanetResearch :one
+UPDATE planet_research
+SET level = $2,
+    research_started_at = NULL,
+    research_completes_at = NULL,
+    updated_at = now()
+WHERE id = $1 AND level = $2 - 1 AND research_completes_at IS NOT NULL
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: CreateWave :one
+INSERT INTO waves (difficulty)
+VALUES ($1)
+RETURNING *;
+
+-- name: CreateWaveSpawn :exec
+INSERT INTO wave_spawns (wave_id, position, alien_id, count)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetWaveByID :one
+SELECT * FROM waves
+WHERE id = $1;
+
+-- name: ListWaves :many
+SELECT * FROM waves
+ORDER BY difficulty, created_at;
+
+-- name: ListWaveSpawns :many
+SELECT * FROM wave_spawns
+WHERE wave_id = $1
+ORDER BY position;
+
+-- name: ListWaveSpawnsByWaveIDs :many
+SELECT * FROM wave_spawns
+WHERE wave_id = ANY(@wave_ids::uuid[])
+ORDER BY wave_id, position;
+
+-- name: DeleteWave :execrows
+DELETE FROM waves
+WHERE id = $1;
@@ -3,22 +3,226 @@ CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 CREATE TABLE players (
     id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     username    TEXT NOT NULL UNIQUE,
-    created_at  TIMESTAMP WITH TIME ZONE DEFAULT now(),
-    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    -- bcrypt hash, NULL for players created before passwords existed
+    password_hash TEXT,
+    -- admins are promoted by hand, there is no endpoint for it
+    role        TEXT NOT NULL DEFAULT 'player',
+    CONSTRAINT players_role_check CHECK (role IN ('player', 'admin'))
 );
 
+CREATE INDEX players_created_idx ON players (created_at DESC, id DESC);
+-- lets username prefix searches use an index whatever the collation
+CREATE INDEX players_username_pattern_idx ON players (username text_pattern_ops);
+
 CREATE TABLE planets (
     id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     player_id       UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
     name            TEXT NOT NULL,
-    resources       INT DEFAULT 0,
-    defense_level   INT DEFAULT 1,
-    current_wave    INT DEFAULT 0,
-    health          INT DEFAULT 100,
+    resources       JSONB NOT NULL DEFAULT '{"minerals": 0, "energy": 0, "tech_parts": 0}',
+    defense_level   INT NOT NULL DEFAULT 1,
+    current_wave    INT NOT NULL DEFAULT 0,
+    health          INT NOT NULL DEFAULT 100,
     updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
-    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now()
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    max_health      INT NOT NULL DEFAULT 100,
+    shields         INT NOT NULL DEFAULT 0,
+    max_shields         INT NOT NULL DEFAULT 50,
+    -- shield points regained per second once no hit has landed for shield_regen_delay seconds
+    shield_regen        DOUBLE PRECISION NOT NULL DEFAULT 1,
+    shield_regen_delay  DOUBLE PRECISION NOT NULL DEFAULT 3,
+    status              TEXT NOT NULL DEFAULT 'active',
+    destroyed_at        TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT planets_health_range CHECK (health >= 0 AND health <= max_health),
+    CONSTRAINT planets_shields_range CHECK (shields >= 0 AND shields <= max_shields),
+    CONSTRAINT planets_shield_regen_non_negative CHECK (shield_regen >= 0 AND shield_regen_delay >= 0),
+    CONSTRAINT planets_status_check CHECK (status IN ('active', 'destroyed')),
+    -- a planet is destroyed exactly when it has no HP left
+    CONSTRAINT planets_destroyed_health CHECK ((status = 'destroyed') = (health = 0))
 );
 
-
 -- index for fast lookups by player
 CREATE INDEX idx_planets_player_id ON planets(player_id);
+
+CREATE TABLE defense_systems (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind            TEXT NOT NULL,
+    name            TEXT NOT NULL,
+    damage          INT NOT NULL,
+    range           INT NOT NULL,
+    fire_rate       DOUBLE PRECISION NOT NULL,
+    damage_type     TEXT NOT NULL DEFAULT 'kinetic',
+    level           INT NOT NULL DEFAULT 1,
+    upgrade_cost    JSONB NOT NULL DEFAULT '{}',
+    position        INT NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    upgrade_started_at   TIMESTAMP WITH TIME ZONE,
+    upgrade_completes_at TIMESTAMP WITH TIME ZONE,
+    -- deferred so two defenses can swap positions inside a transaction
+    CONSTRAINT defense_systems_planet_position_key UNIQUE (planet_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+
+CREATE TABLE alien_templates (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            TEXT NOT NULL,
+    hp              INT NOT NULL,
+    damage          INT NOT NULL,
+    speed           DOUBLE PRECISION NOT NULL,
+    behavior_type   TEXT NOT NULL DEFAULT 'basic',
+    resistances     JSONB NOT NULL DEFAULT '{}',
+    loot_drop       JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    version         INT NOT NULL DEFAULT 1,
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    retired_at      TIMESTAMP WITH TIME ZONE,
+    damage_type     TEXT NOT NULL DEFAULT 'kinetic'
+);
+
+CREATE TABLE alien_template_versions (
+    template_id     UUID NOT NULL REFERENCES alien_templates(id) ON DELETE CASCADE,
+    version         INT NOT NULL,
+    name            TEXT NOT NULL,
+    hp              INT NOT NULL,
+    damage          INT NOT NULL,
+    speed           DOUBLE PRECISION NOT NULL,
+    behavior_type   TEXT NOT NULL,
+    resistances     JSONB NOT NULL,
+    loot_drop       JSONB NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    damage_type     TEXT NOT NULL DEFAULT 'kinetic',
+    PRIMARY KEY (template_id, version)
+);
+
+CREATE TABLE waves (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    difficulty  INT NOT NULL DEFAULT 1,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE TABLE wave_spawns (
+    wave_id     UUID NOT NULL REFERENCES waves(id) ON DELETE CASCADE,
+    position    INT NOT NULL,
+    alien_id    UUID NOT NULL REFERENCES alien_templates(id),
+    count       INT NOT NULL CHECK (count > 0),
+    PRIMARY KEY (wave_id, position)
+);
+
+CREATE INDEX idx_wave_spawns_alien_id ON wave_spawns(alien_id);
+
+CREATE TABLE planet_buildings (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id           UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind                TEXT NOT NULL,
+    -- level in operation; while build_completes_at is set, level + 1 is under construction
+    level               INT NOT NULL DEFAULT 0,
+    build_started_at    TIMESTAMP WITH TIME ZONE,
+    build_completes_at  TIMESTAMP WITH TIME ZONE,
+    created_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at          TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT planet_buildings_planet_kind_key UNIQUE (planet_id, kind),
+    CONSTRAINT planet_buildings_level_non_negative CHECK (level >= 0)
+);
+
+CREATE TABLE planet_research (
+    id                      UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id               UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    tech                    TEXT NOT NULL,
+    -- level researched; while research_completes_at is set, level + 1 is in progress
+    level                   INT NOT NULL DEFAULT 0,
+    research_started_at     TIMESTAMP WITH TIME ZONE,
+    research_completes_at   TIMESTAMP WITH TIME ZONE,
+    created_at              TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at              TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT planet_research_planet_tech_key UNIQUE (planet_id, tech),
+    CONSTRAINT planet_research_level_non_negative CHECK (level >= 0)
+);
+
+-- timed work finished by the planet-service scheduler
+CREATE TABLE jobs (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    kind            TEXT NOT NULL,
+    -- the defense, building or research row the job finishes, and the level it reaches
+    target_id       UUID NOT NULL,
+    target_level    INT NOT NULL,
+    run_at          TIMESTAMP WITH TIME ZONE NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending',
+    attempts        INT NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    created_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    updated_at      TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'done', 'failed'))
+);
+
+CREATE INDEX jobs_due_idx ON jobs (run_at) WHERE status = 'pending';
+
+CREATE TABLE battles (
+    id                  UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id           UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    -- not a foreign key: generated waves aren't stored and stored ones may be deleted
+    wave_id             UUID,
+    wave_number         INT NOT NULL,
+    seed                BIGINT NOT NULL,
+    outcome             TEXT NOT NULL,
+    damage_taken        INT NOT NULL,
+    shields_remaining   INT NOT NULL,
+    hp_remaining        INT NOT NULL,
+    aliens_destroyed    INT NOT NULL,
+    loot                JSONB NOT NULL,
+    events              JSONB NOT NULL, -- structured battle events, see types.BattleEvent
+    -- planet, wave and alien templates as fought, enough to replay the battle
+    snapshot            JSONB NOT NULL,
+    idempotency_key     TEXT,
+    fought_at           TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at          TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    CONSTRAINT battles_outcome_check CHECK (outcome IN ('victory', 'defeat', 'stalemate')),
+    CONSTRAINT battles_planet_idempotency_key UNIQUE (planet_id, idempotency_key)
+);
+
+CREATE INDEX battles_planet_created_idx ON battles (planet_id, created_at DESC, id DESC);
+
+CREATE TABLE resource_ledger (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    planet_id       UUID NOT NULL REFERENCES planets(id) ON DELETE CASCADE,
+    source          TEXT NOT NULL,
+    -- what was credited, e.g. the battle whose loot this is
+    reference_id    UUID NOT NULL,
+    base            JSONB NOT NULL,
+    bonus_percent   INT NOT NULL DEFAULT 0,
+    amount          JSONB NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    -- each source event is credited at most once
+    CONSTRAINT resource_ledger_source_reference UNIQUE (source, reference_id)
+);
+
+CREATE INDEX resource_ledger_planet_created_idx ON resource_ledger (planet_id, created_at DESC, id DESC);
+
+CREATE TABLE refresh_tokens (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    player_id   UUID NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+    -- SHA-256 of the token, the token itself is only ever sent to the client
+    token_hash  TEXT NOT NULL UNIQUE,
+    expires_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at  TIMESTAMP WITH TIME ZONE,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX refresh_tokens_player_idx ON refresh_tokens (player_id);
+
+CREATE TABLE api_keys (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name            TEXT NOT NULL,
+    -- SHA-256 of the key, the key itself is only shown when it is created
+    key_hash        TEXT NOT NULL UNIQUE,
+    scopes          TEXT[] NOT NULL DEFAULT '{}',
+    expires_at      TIMESTAMP WITH TIME ZONE,
+    revoked_at      TIMESTAMP WITH TIME ZONE,
+    -- the key this one replaced when it was rotated
+    rotated_from    UUID REFERENCES api_keys(id) ON DELETE SET NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
@@ -0,0 +1,278 @@
+// Package handlertest checks HTTP handlers against the envelope written by
+// package response. It is only imported from tests.
+package handlertest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+	"github.com/novaru/scallopticon/shared/request"
+	"github.com/novaru/scallopticon/shared/response"
+)
+
+// Call is one call a fake service received: the method and the arguments
+// after the context.
+type Call struct {
+	Method string
+	Args   []any
+}
+
+// Recorder records the calls a fake service receives. Embed it in the fake
+// and return Record from every method.
+type Recorder struct {
+	Err   error // returned by every call
+	calls []Call
+}
+
+// Record notes a call and returns the error the fake was built with.
+func (r *Recorder) Record(method string, args ...any) error {
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	return r.Err
+}
+
+// Calls returns the calls recorded so far.
+func (r *Recorder) Calls() []Call {
+	return r.calls
+}
+
+// Service is a fake service that records its calls.
+type Service interface {
+	Calls() []Call
+}
+
+// Route describes one route for RunRoutes.
+type Route[S Service] struct {
+	Name    string
+	Method  string
+	Pattern string // chi pattern the handler is mounted on
+	Target  string // a valid request URL
+	Body    string // a valid body; empty for routes that read none
+	Header  http.Header
+	Handler func(S) http.HandlerFunc
+
+	Status       int  // on success
+	OptionalBody bool // the body may be left out
+
+	// Call is the service call a valid request makes, Data what the
+	// response's data encodes to (nil when the route returns none) and
+	// Page its pagination, if any.
+	Call Call
+	Data any
+	Page *response.Pagination
+
+	// Invalid requests the handler must reject with INVALID_INPUT before
+	// calling the service. An empty target or body means the valid one.
+	Invalid []InvalidRequest
+}
+
+type InvalidRequest struct {
+	Name   string
+	Target string
+	Body   string
+}
+
+// Envelope is the body every handler writes.
+type Envelope struct {
+	Success    bool                 `json:"success"`
+	Data       json.RawMessage      `json:"data"`
+	Error      *response.ErrorData  `json:"error"`
+	Pagination *response.Pagination `json:"pagination"`
+}
+
+// RunRoutes checks each route on success, on a service error, on its
+// invalid requests and, for routes with a body, on every way
+// request.DecodeJSON rejects a body. newService builds a fake whose methods
+// return err.
+func RunRoutes[S Service](t *testing.T, newService func(err error) S, routes []Route[S]) {
+	t.Helper()
+
+	for _, rt := range routes {
+		t.Run(rt.Name+"/ok", func(t *testing.T) {
+			svc := newService(nil)
+			rec := ServeWithHeader(rt.Pattern, rt.Handler(svc), rt.Method, rt.Target, rt.Body, rt.Header)
+			body := ExpectSuccess(t, rec, rt.Status)
+			ExpectCalls(t, svc, rt.Call)
+			expectData(t, body, rt.Data, rt.Page)
+		})
+
+		t.Run(rt.Name+"/service error", func(t *testing.T) {
+			svc := newService(apperrors.NewNotFoundError("thing", "thing with given ID does not exist"))
+			rec := ServeWithHeader(rt.Pattern, rt.Handler(svc), rt.Method, rt.Target, rt.Body, rt.Header)
+			ExpectError(t, rec, http.StatusNotFound, "NOT_FOUND", "not found")
+		})
+
+		for _, bad := range rt.Invalid {
+			t.Run(rt.Name+"/"+bad.Name, func(t *testing.T) {
+				target, body := rt.Target, rt.Body
+				if bad.Target != "" {
+					target = bad.Target
+				}
+				if bad.Body != "" {
+					body = bad.Body
+				}
+				svc := newService(nil)
+				rec := ServeWithHeader(rt.Pattern, rt.Handler(svc), rt.Method, target, body, rt.Header)
+				ExpectError(t, rec, http.StatusBadRequest, "INVALID_INPUT", "")
+				ExpectCalls(t, svc)
+			})
+		}
+
+		if rt.Body == "" {
+			continue
+		}
+		for _, dc := range DecodeCases(rt.Body) {
+			t.Run(rt.Name+"/"+dc.Name, func(t *testing.T) {
+				svc := newService(nil)
+				rec := ServeWithHeader(rt.Pattern, rt.Handler(svc), rt.Method, rt.Target, dc.Body, rt.Header)
+				if dc.Body == "" && rt.OptionalBody {
+					ExpectSuccess(t, rec, rt.Status)
+					return
+				}
+				ExpectError(t, rec, http.StatusBadRequest, "INVALID_INPUT", dc.Message)
+				ExpectCalls(t, svc)
+			})
+		}
+	}
+}
+
+// Serve sends one request to the handler mounted on pattern.
+func Serve(pattern string, handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	return ServeWithHeader(pattern, handler, method, target, body, nil)
+}
+
+func ServeWithHeader(pattern string, handler http.HandlerFunc, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rec := httptest.NewRecorder()
+	ServeRequest(pattern, handler, rec, req)
+	return rec
+}
+
+// ServeRequest routes req to the handler mounted on pattern.
+func ServeRequest(pattern string, handler http.HandlerFunc, w http.ResponseWriter, req *http.Request) {
+	r := chi.NewRouter()
+	r.Method(req.Method, pattern, handler)
+	r.ServeHTTP(w, req)
+}
+
+func DecodeEnvelope(t *testing.T, rec *httptest.ResponseRecorder) Envelope {
+	t.Helper()
+
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("Content-Type = %q, want application/json", ct)
+	}
+	var body Envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding body %q: %v", rec.Body, err)
+	}
+	return body
+}
+
+// ExpectSuccess checks rec holds a successful envelope with status and
+// returns it.
+func ExpectSuccess(t *testing.T, rec *httptest.ResponseRecorder, status int) Envelope {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	body := DecodeEnvelope(t, rec)
+	if !body.Success || body.Error != nil {
+		t.Fatalf("body = %s, want success without error", rec.Body)
+	}
+	return body
+}
+
+// ExpectError checks rec holds an error envelope with status and code whose
+// message mentions message.
+func ExpectError(t *testing.T, rec *httptest.ResponseRecorder, status int, code, message string) {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d; body %s", rec.Code, status, rec.Body)
+	}
+	body := DecodeEnvelope(t, rec)
+	if body.Success || body.Error == nil || body.Error.Code != code || body.Data != nil {
+		t.Fatalf("body = %s, want error code %s", rec.Body, code)
+	}
+	if !strings.Contains(body.Error.Message, message) {
+		t.Fatalf("error message = %q, want it to mention %q", body.Error.Message, message)
+	}
+}
+
+// ExpectCalls checks svc received exactly the calls want, in order.
+func ExpectCalls(t *testing.T, svc Service, want ...Call) {
+	t.Helper()
+
+	got := svc.Calls()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("service calls = %+v, want %+v", got, want)
+	}
+}
+
+// expectData checks the envelope's data encodes the same JSON as want and
+// its pagination matches page.
+func expectData(t *testing.T, body Envelope, want any, page *response.Pagination) {
+	t.Helper()
+
+	if want == nil {
+		if body.Data != nil {
+			t.Fatalf("data = %s, want none", body.Data)
+		}
+	} else {
+		wantJSON, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, body.Data, wantJSON) {
+			t.Fatalf("data = %s, want %s", body.Data, wantJSON)
+		}
+	}
+
+	if !reflect.DeepEqual(body.Pagination, page) {
+		t.Fatalf("pagination = %+v, want %+v", body.Pagination, page)
+	}
+}
+
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("decoding %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+type DecodeCase struct {
+	Name    string
+	Body    string
+	Message string
+}
+
+// DecodeCases are bodies request.DecodeJSON rejects, derived from a valid
+// body.
+func DecodeCases(valid string) []DecodeCase {
+	return []DecodeCase{
+		{"unknown field", `{"no_such_field":1}`, `unknown field "no_such_field"`},
+		{"body too large", `{"padding":"` + strings.Repeat("x", request.MaxBodyBytes) + `"}`, "must not be larger than"},
+		{"trailing data", valid + ` {}`, "single JSON object"},
+		{"empty body", "", "request body is empty"},
+	}
+}
@@ -0,0 +1,77 @@
+// Package request reads request bodies for handlers.
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/novaru/scallopticon/shared/apperrors"
+)
+
+// MaxBodyBytes is the largest request body DecodeJSON reads.
+const MaxBodyBytes = 1 << 20
+
+// DecodeJSON decodes the request body, a single JSON object without fields
+// dst doesn't know, into dst. Any problem with the body is returned as an
+// INVALID_INPUT error that says what is wrong with it.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return decode(w, r, dst, false)
+}
+
+// DecodeOptionalJSON is DecodeJSON for requests whose body may be left
+// out; an empty body leaves dst as it is.
+func DecodeOptionalJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	return decode(w, r, dst, true)
+}
+
+func decode(w http.ResponseWriter, r *http.Request, dst any, optional bool) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		if optional && errors.Is(err, io.EOF) {
+			return nil
+		}
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return apperrors.NewInvalidInputError("request body must hold a single JSON object", err)
+	}
+	return nil
+}
+
+// decodeError describes why the body couldn't be decoded.
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return apperrors.NewInvalidInputError("request body is empty", err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperrors.NewInvalidInputError("request body is not valid JSON", err)
+	case errors.As(err, &syntaxErr):
+		return apperrors.NewInvalidInputError(
+			fmt.Sprintf("request body is not valid JSON at offset %d", syntaxErr.Offset), err)
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return apperrors.NewInvalidInputError("request body must be a JSON object", err)
+		}
+		return apperrors.NewInvalidInputError(
+			fmt.Sprintf("field %q must be of type %s", typeErr.Field, typeErr.Type), err)
+	case errors.As(err, &maxErr):
+		return apperrors.NewInvalidInputError(
+			fmt.Sprintf("request body must not be larger than %d bytes", maxErr.Limit), err)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no error type for unknown fields.
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return apperrors.NewInvalidInputError("unknown field "+field, err)
+	default:
+		return apperrors.NewInvalidInputError("invalid JSON format", err)
+	}
+}
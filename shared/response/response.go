@@ -9,9 +9,17 @@ import (
 )
 
 type APIResponse struct {
-	Data    any        `json:"data,omitempty"`
-	Error   *ErrorData `json:"error,omitempty"`
-	Success bool       `json:"success"`
+	Data       any         `json:"data,omitempty"`
+	Error      *ErrorData  `json:"error,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Success    bool        `json:"success"`
+}
+
+// Pagination describes a page of a list. NextCursor is empty on the last
+// page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ErrorData struct {
@@ -28,6 +36,15 @@ func WriteSuccess(w http.ResponseWriter, data any) {
 	})
 }
 
+// WritePage writes a successful JSON response holding one page of a list
+func WritePage(w http.ResponseWriter, data any, page Pagination) {
+	writeJSON(w, http.StatusOK, &APIResponse{
+		Data:       data,
+		Pagination: &page,
+		Success:    true,
+	})
+}
+
 // WriteCreated writes a created response
 func WriteCreated(w http.ResponseWriter, data any) {
 	writeJSON(w, http.StatusCreated, &APIResponse{
@@ -65,10 +82,18 @@ func WriteError(w http.ResponseWriter, e error) {
 	})
 }
 
+// encodeFailure is sent when a response can't be encoded. Encoding happens
+// before anything is written, so the client still gets a single envelope.
+var encodeFailure = []byte(`{"error":{"code":"INTERNAL_ERROR","message":"failed to encode response"},"success":false}`)
+
 func writeJSON(w http.ResponseWriter, status int, data any) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = encodeFailure
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-	}
+	w.Write(append(body, '\n'))
 }
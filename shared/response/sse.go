@@ -0,0 +1,84 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrStreamingUnsupported is returned by NewEventStream when the response
+// can't be flushed. Nothing has been written to the response by then, so
+// the caller can still send an error.
+var ErrStreamingUnsupported = errors.New("response does not support flushing")
+
+// EventStream writes Server-Sent Events. Create it with NewEventStream
+// before writing anything else to the response.
+type EventStream struct {
+	w http.ResponseWriter
+	f http.Flusher
+}
+
+// NewEventStream sends the SSE headers and returns a stream to write
+// events to. It checks the response can be flushed before writing anything.
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	f := flusherOf(w)
+	if f == nil {
+		return nil, ErrStreamingUnsupported
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // keep nginx from buffering the stream
+
+	w.WriteHeader(http.StatusOK)
+	f.Flush()
+	return &EventStream{w: w, f: f}, nil
+}
+
+// flusherOf finds the http.Flusher behind w, following Unwrap the way
+// http.ResponseController does. It returns nil if there is none.
+func flusherOf(w http.ResponseWriter) http.Flusher {
+	for {
+		switch t := w.(type) {
+		case http.Flusher:
+			return t
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = t.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// Send writes one event with data encoded as JSON and flushes it to the
+// client. An empty id leaves the client's last event ID unchanged.
+func (s *EventStream) Send(id, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(s.w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
+
+// Comment writes an SSE comment, which clients ignore. Use it to keep idle
+// connections open.
+func (s *EventStream) Comment(text string) error {
+	if _, err := fmt.Fprintf(s.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	s.f.Flush()
+	return nil
+}
@@ -0,0 +1,108 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// DefaultBehavior is used for templates that leave BehaviorType empty.
+const DefaultBehavior = "basic"
+
+var (
+	ErrUnknownBehavior = errors.New("unknown behavior type")
+	ErrInvalidTemplate = errors.New("invalid alien template")
+)
+
+// Behavior controls how an alien acts during a battle. A new Behavior is
+// created for every spawned alien, so implementations may keep per-alien
+// state.
+type Behavior interface {
+	// Move advances the alien for one tick.
+	Move(b *Battle, a *Alien)
+	// Threat ranks the alien for defense targeting. Defenses fire at the
+	// highest threat in range first.
+	Threat(a *Alien) int
+	// Attack is called on every attack tick once the alien has reached the
+	// planet.
+	Attack(b *Battle, a *Alien)
+	// Special runs the alien's special ability once per tick.
+	Special(b *Battle, a *Alien)
+}
+
+// BehaviorFactory creates the Behavior for one alien.
+type BehaviorFactory func() Behavior
+
+var (
+	behaviorsMu sync.RWMutex
+	behaviors   = map[string]BehaviorFactory{}
+)
+
+// RegisterBehavior makes a behavior available under name. It panics if name
+// is empty or already registered.
+func RegisterBehavior(name string, f BehaviorFactory) {
+	behaviorsMu.Lock()
+	defer behaviorsMu.Unlock()
+
+	if name == "" {
+		panic("simulation: behavior name must not be empty")
+	}
+	if _, dup := behaviors[name]; dup {
+		panic(fmt.Sprintf("simulation: behavior %q registered twice", name))
+	}
+	behaviors[name] = f
+}
+
+// NewBehavior instantiates the behavior registered under name.
+func NewBehavior(name string) (Behavior, error) {
+	if name == "" {
+		name = DefaultBehavior
+	}
+
+	behaviorsMu.RLock()
+	f, ok := behaviors[name]
+	behaviorsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBehavior, name)
+	}
+	return f(), nil
+}
+
+// Behaviors returns the names of all registered behaviors, sorted.
+func Behaviors() []string {
+	behaviorsMu.RLock()
+	defer behaviorsMu.RUnlock()
+
+	names := make([]string, 0, len(behaviors))
+	for name := range behaviors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateTemplate checks that a template can be used in a battle.
+func ValidateTemplate(t types.AlienTemplate) error {
+	if _, err := NewBehavior(t.BehaviorType); err != nil {
+		return fmt.Errorf("alien template %s: %w", t.ID, err)
+	}
+	if t.HP <= 0 {
+		return fmt.Errorf("%w: alien template %s must have positive hp", ErrInvalidTemplate, t.ID)
+	}
+	if t.Damage < 0 || t.Speed < 0 {
+		return fmt.Errorf("%w: alien template %s has negative damage or speed", ErrInvalidTemplate, t.ID)
+	}
+	if err := ValidateDamageType(t.DamageType); err != nil {
+		return fmt.Errorf("%w: alien template %s deals unknown damage type %q", ErrInvalidTemplate, t.ID, t.DamageType)
+	}
+	for dt := range t.Resistances {
+		if err := ValidateDamageType(dt); err != nil || dt == "" {
+			return fmt.Errorf("%w: alien template %s has resistance for unknown damage type %q", ErrInvalidTemplate, t.ID, dt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package simulation
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// newTestBattle returns a battle against planet with no aliens queued.
+func newTestBattle(t *testing.T, planet types.Planet) *Battle {
+	t.Helper()
+
+	b, err := NewBattle(Input{Planet: planet})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// place puts an alien of tmpl on the field at distance.
+func place(t *testing.T, b *Battle, tmpl types.AlienTemplate, distance float64) *Alien {
+	t.Helper()
+
+	behavior, err := NewBehavior(tmpl.BehaviorType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Alien{ID: len(b.active) + 1, Template: tmpl, HP: tmpl.HP, MaxHP: tmpl.HP, Distance: distance, behavior: behavior}
+	b.active = append(b.active, a)
+	return a
+}
+
+func TestBehaviors(t *testing.T) {
+	planet := types.Planet{HP: 100, MaxHP: 100}
+	shielded := types.Planet{HP: 100, MaxHP: 100, Shields: 50, MaxShields: 50}
+	alien := func(behavior string) types.AlienTemplate {
+		return types.AlienTemplate{Name: behavior, HP: 50, Damage: 10, Speed: 20, BehaviorType: behavior}
+	}
+
+	tests := []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{"basic walks at its speed", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("basic"), 50)
+			a.behavior.Move(b, a)
+			if want := 50 - 20.0/TicksPerSecond; a.Distance != want {
+				t.Errorf("distance = %v, want %v", a.Distance, want)
+			}
+		}},
+		{"basic stops at the planet", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("basic"), 1)
+			a.behavior.Move(b, a)
+			if a.Distance != 0 {
+				t.Errorf("distance = %v, want 0", a.Distance)
+			}
+		}},
+		{"basic hits for its damage", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("basic"), 0)
+			a.behavior.Attack(b, a)
+			if r := b.Result(); r.HPRemaining != 90 {
+				t.Errorf("HP = %d, want 90", r.HPRemaining)
+			}
+		}},
+		{"empty behavior type is basic", func(t *testing.T) {
+			behavior, err := NewBehavior("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := behavior.(basicBehavior); !ok {
+				t.Errorf("NewBehavior(\"\") = %T, want basicBehavior", behavior)
+			}
+		}},
+		{"swarm speeds up in a pack", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("swarm"), 50)
+			place(t, b, alien("swarm"), 55)
+			place(t, b, alien("swarm"), 45)
+			a.behavior.Move(b, a)
+			if want := 50 - 20.0/TicksPerSecond*swarmSpeedBonus; a.Distance != want {
+				t.Errorf("distance = %v, want %v", a.Distance, want)
+			}
+		}},
+		{"swarm alone walks at its speed", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("swarm"), 50)
+			place(t, b, alien("swarm"), 80) // too far to count
+			place(t, b, alien("basic"), 50) // not a swarm
+			a.behavior.Move(b, a)
+			if want := 50 - 20.0/TicksPerSecond; a.Distance != want {
+				t.Errorf("distance = %v, want %v", a.Distance, want)
+			}
+		}},
+		{"tank draws fire", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			near := place(t, b, alien("basic"), 10)
+			tank := place(t, b, alien("tank"), 40)
+			if !preferTarget(tank, near) || preferTarget(near, tank) {
+				t.Error("defenses don't prefer the tank over a closer alien")
+			}
+		}},
+		{"kamikaze rams for triple damage and dies", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			tmpl := alien("kamikaze")
+			tmpl.LootDrop = types.Resources{Minerals: 5}
+			a := place(t, b, tmpl, 0)
+			a.behavior.Attack(b, a)
+
+			r := b.Result()
+			if r.HPRemaining != 100-10*kamikazeMultiplier {
+				t.Errorf("HP = %d, want %d", r.HPRemaining, 100-10*kamikazeMultiplier)
+			}
+			if a.Alive() {
+				t.Error("kamikaze survived its attack")
+			}
+			if r.AliensDestroyed != 0 || r.Loot != (types.Resources{}) {
+				t.Errorf("self-destruct counted as a kill: %d destroyed, loot %+v", r.AliensDestroyed, r.Loot)
+			}
+			if n, _ := countEvents(r, types.EventSelfDestruct); n != 1 {
+				t.Errorf("%d self_destruct events, want 1", n)
+			}
+		}},
+		{"shield breaker hits shields for double", func(t *testing.T) {
+			b := newTestBattle(t, shielded)
+			a := place(t, b, alien("shield_breaker"), 0)
+			a.behavior.Attack(b, a)
+			if r := b.Result(); r.ShieldsRemaining != 50-10*shieldBreakerMultiplier || r.HPRemaining != 100 {
+				t.Errorf("shields %d, HP %d; want %d, 100", r.ShieldsRemaining, r.HPRemaining, 50-10*shieldBreakerMultiplier)
+			}
+		}},
+		{"shield breaker hits an unshielded planet normally", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			a := place(t, b, alien("shield_breaker"), 0)
+			a.behavior.Attack(b, a)
+			if r := b.Result(); r.HPRemaining != 90 {
+				t.Errorf("HP = %d, want 90", r.HPRemaining)
+			}
+		}},
+		{"healer heals the most damaged ally nearby once a second", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			healer := place(t, b, alien("healer"), 50)
+			scratched := place(t, b, alien("basic"), 45)
+			wounded := place(t, b, alien("basic"), 55)
+			distant := place(t, b, alien("basic"), 90)
+			scratched.HP, wounded.HP, distant.HP = 45, 20, 1
+
+			healer.behavior.Special(b, healer)
+			if wounded.HP != 30 || scratched.HP != 45 || distant.HP != 1 {
+				t.Fatalf("HP after healing: wounded %d, scratched %d, distant %d; want 30, 45, 1", wounded.HP, scratched.HP, distant.HP)
+			}
+			for range TicksPerSecond - 1 {
+				healer.behavior.Special(b, healer)
+			}
+			if wounded.HP != 30 {
+				t.Fatalf("healed again within a second: HP %d", wounded.HP)
+			}
+			healer.behavior.Special(b, healer)
+			if wounded.HP != 40 {
+				t.Errorf("HP = %d after a second, want 40", wounded.HP)
+			}
+		}},
+		{"healer doesn't heal past full HP", func(t *testing.T) {
+			b := newTestBattle(t, planet)
+			healer := place(t, b, alien("healer"), 50)
+			ally := place(t, b, alien("basic"), 50)
+			ally.HP = 46
+			healer.behavior.Special(b, healer)
+			if ally.HP != 50 {
+				t.Errorf("HP = %d, want 50", ally.HP)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, tt.check)
+	}
+}
+
+func TestBehaviorRegistry(t *testing.T) {
+	for _, name := range []string{"basic", "swarm", "tank", "kamikaze", "shield_breaker", "healer"} {
+		if !slices.Contains(Behaviors(), name) {
+			t.Errorf("behavior %q is not registered", name)
+		}
+	}
+
+	if _, err := NewBehavior("teleporter"); !errors.Is(err, ErrUnknownBehavior) {
+		t.Errorf("NewBehavior(unknown) err = %v, want ErrUnknownBehavior", err)
+	}
+
+	// Unknown behaviors are rejected with the template, before any tick runs.
+	in := testInput(1, nil, 1, types.AlienTemplate{ID: "x", Name: "X", HP: 10, BehaviorType: "teleporter"})
+	if _, err := NewBattle(in); !errors.Is(err, ErrUnknownBehavior) || !IsInvalidInput(err) {
+		t.Errorf("NewBattle err = %v, want ErrUnknownBehavior", err)
+	}
+
+	for _, name := range []string{"", "basic"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RegisterBehavior(%q) didn't panic", name)
+				}
+			}()
+			RegisterBehavior(name, func() Behavior { return basicBehavior{} })
+		}()
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		tmpl types.AlienTemplate
+		want error
+	}{
+		{"valid", types.AlienTemplate{HP: 10, BehaviorType: "tank", DamageType: types.DamageEMP, Resistances: map[string]float64{types.DamageLaser: 0.5}}, nil},
+		{"unknown behavior", types.AlienTemplate{HP: 10, BehaviorType: "teleporter"}, ErrUnknownBehavior},
+		{"no HP", types.AlienTemplate{HP: 0}, ErrInvalidTemplate},
+		{"negative speed", types.AlienTemplate{HP: 10, Speed: -1}, ErrInvalidTemplate},
+		{"unknown damage type", types.AlienTemplate{HP: 10, DamageType: "sonic"}, ErrInvalidTemplate},
+		{"unknown resistance", types.AlienTemplate{HP: 10, Resistances: map[string]float64{"sonic": 0.5}}, ErrInvalidTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateTemplate(tt.tmpl); !errors.Is(err, tt.want) {
+				t.Errorf("ValidateTemplate err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package simulation
+
+import "math"
+
+func init() {
+	RegisterBehavior(DefaultBehavior, func() Behavior { return basicBehavior{} })
+	RegisterBehavior("swarm", func() Behavior { return swarmBehavior{} })
+	RegisterBehavior("tank", func() Behavior { return tankBehavior{} })
+	RegisterBehavior("kamikaze", func() Behavior { return kamikazeBehavior{} })
+	RegisterBehavior("shield_breaker", func() Behavior { return shieldBreakerBehavior{} })
+	RegisterBehavior("healer", func() Behavior { return &healerBehavior{} })
+}
+
+// basicBehavior walks straight at the planet and hits it for its damage.
+type basicBehavior struct{}
+
+func (basicBehavior) Move(b *Battle, a *Alien) {
+	b.Advance(a, a.Template.Speed/TicksPerSecond)
+}
+
+func (basicBehavior) Threat(a *Alien) int { return 0 }
+
+func (basicBehavior) Attack(b *Battle, a *Alien) {
+	b.DamagePlanet(a, a.Template.Damage)
+}
+
+func (basicBehavior) Special(b *Battle, a *Alien) {}
+
+const (
+	swarmRadius     = 10.0
+	swarmMinPack    = 3
+	swarmSpeedBonus = 1.5
+)
+
+// swarmBehavior moves faster while enough swarm allies are close by.
+type swarmBehavior struct{ basicBehavior }
+
+func (swarmBehavior) Move(b *Battle, a *Alien) {
+	pack := 0
+	for _, other := range b.Aliens() {
+		if other != a && other.Alive() && other.Template.BehaviorType == a.Template.BehaviorType &&
+			math.Abs(other.Distance-a.Distance) <= swarmRadius {
+			pack++
+		}
+	}
+
+	speed := a.Template.Speed / TicksPerSecond
+	if pack >= swarmMinPack-1 {
+		speed *= swarmSpeedBonus
+	}
+	b.Advance(a, speed)
+}
+
+// tankBehavior draws fire: defenses target tanks before anything else.
+type tankBehavior struct{ basicBehavior }
+
+func (tankBehavior) Threat(a *Alien) int { return 10 }
+
+const kamikazeMultiplier = 3
+
+// kamikazeBehavior rams the planet once for triple damage and dies doing so.
+type kamikazeBehavior struct{ basicBehavior }
+
+func (kamikazeBehavior) Threat(a *Alien) int { return 5 }
+
+func (kamikazeBehavior) Attack(b *Battle, a *Alien) {
+	b.DamagePlanet(a, a.Template.Damage*kamikazeMultiplier)
+	b.SelfDestruct(a)
+}
+
+const shieldBreakerMultiplier = 2
+
+// shieldBreakerBehavior deals double damage while the planet has shields.
+type shieldBreakerBehavior struct{ basicBehavior }
+
+func (shieldBreakerBehavior) Attack(b *Battle, a *Alien) {
+	if b.Shields() > 0 {
+		b.DamageShields(a, a.Template.Damage*shieldBreakerMultiplier)
+		return
+	}
+	b.DamagePlanet(a, a.Template.Damage)
+}
+
+const healerRadius = 20.0
+
+// healerBehavior restores HP to the most damaged ally nearby once a second,
+// healing for its own damage value.
+type healerBehavior struct {
+	basicBehavior
+	cooldown int
+}
+
+func (h *healerBehavior) Threat(a *Alien) int { return 5 }
+
+func (h *healerBehavior) Special(b *Battle, a *Alien) {
+	if h.cooldown > 0 {
+		h.cooldown--
+		return
+	}
+
+	var target *Alien
+	for _, other := range b.Aliens() {
+		if other == a || !other.Alive() || other.HP >= other.MaxHP ||
+			math.Abs(other.Distance-a.Distance) > healerRadius {
+			continue
+		}
+		if target == nil || other.MaxHP-other.HP > target.MaxHP-target.HP {
+			target = other
+		}
+	}
+	if target == nil {
+		return
+	}
+
+	b.Heal(a, target, a.Template.Damage)
+	h.cooldown = TicksPerSecond - 1
+}
@@ -0,0 +1,87 @@
+package simulation
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestBattleEventString(t *testing.T) {
+	loot := types.Resources{Minerals: 3, Energy: 2, TechParts: 1}
+	tests := []struct {
+		event types.BattleEvent
+		want  string
+	}{
+		{types.BattleEvent{Tick: 0, Kind: types.EventSpawn, Target: "Drone#1", Distance: 100}, "[tick 0] Drone#1 spawned at distance 100"},
+		{types.BattleEvent{Tick: 3, Kind: types.EventFire, Source: "Turret", Target: "Drone#1"}, "[tick 3] Turret fired at Drone#1"},
+		{types.BattleEvent{Tick: 3, Kind: types.EventFire, Source: "Turret", Target: "Drone#1", Critical: true}, "[tick 3] Turret scored a critical hit on Drone#1"},
+		{types.BattleEvent{Tick: 3, Kind: types.EventHit, Source: "Laser", Target: "Drone#1", Amount: 5, Raw: 10, DamageType: types.DamageLaser, Multiplier: 0.5},
+			"[tick 3] Laser hit Drone#1 for 5 laser damage (raw 10, x0.50)"},
+		{types.BattleEvent{Tick: 9, Kind: types.EventShieldAbsorb, Source: "Drone#1", Amount: 4, DamageType: types.DamageKinetic}, "[tick 9] shields absorbed 4 kinetic damage from Drone#1"},
+		{types.BattleEvent{Tick: 20, Kind: types.EventShieldRegen, Amount: 2}, "[tick 20] shields recharged by 2"},
+		{types.BattleEvent{Tick: 9, Kind: types.EventPlanetHit, Source: "Drone#1", Amount: 6, Raw: 10}, "[tick 9] Drone#1 hit the planet for 10 (4 absorbed by shields)"},
+		{types.BattleEvent{Tick: 4, Kind: types.EventAlienDestroyed, Source: "Turret", Target: "Drone#1"}, "[tick 4] Drone#1 destroyed"},
+		{types.BattleEvent{Tick: 4, Kind: types.EventLoot, Source: "Drone#1", Loot: &loot}, "[tick 4] Drone#1 dropped 3 minerals, 2 energy and 1 tech parts"},
+		{types.BattleEvent{Tick: 5, Kind: types.EventHeal, Source: "Medic#2", Target: "Drone#1", Amount: 8}, "[tick 5] Medic#2 healed Drone#1 for 8"},
+		{types.BattleEvent{Tick: 6, Kind: types.EventSelfDestruct, Source: "Bomber#3"}, "[tick 6] Bomber#3 self-destructed"},
+		{types.BattleEvent{Tick: 7, Kind: types.EventPlanetDestroyed, Target: types.PlanetTarget}, "[tick 7] planet destroyed"},
+		{types.BattleEvent{Tick: 8, Kind: types.EventMessage, Text: "the swarm gathers"}, "[tick 8] the swarm gathers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event.Kind, func(t *testing.T) {
+			if got := tt.event.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderEventLog(t *testing.T) {
+	result, err := Run(testInput(3, []types.DefenseSystem{turret}, 4, drone, brute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.EventLog != nil {
+		t.Fatal("EventLog is filled before it was asked for")
+	}
+
+	result.RenderEventLog()
+
+	if len(result.EventLog) != len(result.Events) {
+		t.Fatalf("%d log lines for %d events", len(result.EventLog), len(result.Events))
+	}
+	for i, e := range result.Events {
+		if result.EventLog[i] != e.String() {
+			t.Errorf("line %d = %q, want %q", i, result.EventLog[i], e.String())
+		}
+		if i > 0 && e.Tick < result.Events[i-1].Tick {
+			t.Errorf("event %d at tick %d comes after tick %d", i, e.Tick, result.Events[i-1].Tick)
+		}
+		if e.Kind == types.EventMessage || strings.Contains(result.EventLog[i], "%!") {
+			t.Errorf("line %d is not rendered from a known kind: %q", i, result.EventLog[i])
+		}
+	}
+}
+
+func TestEventsRoundTripAsJSON(t *testing.T) {
+	result, err := Run(testInput(5, []types.DefenseSystem{turret}, 3, drone))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(result.Events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []types.BattleEvent
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, result.Events) {
+		t.Error("events changed in a JSON round trip")
+	}
+}
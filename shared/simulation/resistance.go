@@ -0,0 +1,51 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// Resistance multipliers are clamped to this range. A multiplier of 0 makes
+// an alien immune to a damage type, 1 is neutral and 2 doubles the damage.
+const (
+	MinResistance = 0.0
+	MaxResistance = 2.0
+)
+
+var ErrUnknownDamageType = errors.New("unknown damage type")
+
+// ValidateDamageType checks that t is a known damage type. The empty string is
+// accepted and treated as kinetic.
+func ValidateDamageType(t string) error {
+	if t == "" || slices.Contains(types.DamageTypes, t) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownDamageType, t)
+}
+
+// damageType returns the damage type of a defense, defaulting to kinetic.
+func damageType(d types.DefenseSystem) string {
+	if d.DamageType == "" {
+		return types.DamageKinetic
+	}
+	return d.DamageType
+}
+
+// ResistanceMultiplier returns the clamped multiplier the template applies to
+// damage of the given type. Types missing from Resistances are neutral.
+func ResistanceMultiplier(t types.AlienTemplate, damageType string) float64 {
+	m, ok := t.Resistances[damageType]
+	if !ok {
+		return 1
+	}
+	return min(max(m, MinResistance), MaxResistance)
+}
+
+// Mitigate scales raw damage by multiplier, rounding to the nearest point.
+func Mitigate(raw int, multiplier float64) int {
+	return int(math.Round(float64(raw) * multiplier))
+}
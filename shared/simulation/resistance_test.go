@@ -0,0 +1,107 @@
+package simulation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+func TestResistanceMultiplier(t *testing.T) {
+	tmpl := types.AlienTemplate{Resistances: map[string]float64{
+		types.DamageLaser:     0.25,
+		types.DamagePlasma:    0,
+		types.DamageEMP:       -3,
+		types.DamageExplosive: 5,
+	}}
+	tests := []struct {
+		damageType string
+		want       float64
+	}{
+		{types.DamageLaser, 0.25},
+		{types.DamagePlasma, 0},                // immune
+		{types.DamageEMP, MinResistance},       // clamped up
+		{types.DamageExplosive, MaxResistance}, // clamped down
+		{types.DamageKinetic, 1},               // not listed
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.damageType, func(t *testing.T) {
+			if got := ResistanceMultiplier(tmpl, tt.damageType); got != tt.want {
+				t.Errorf("ResistanceMultiplier = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMitigate(t *testing.T) {
+	tests := []struct {
+		raw        int
+		multiplier float64
+		want       int
+	}{
+		{10, 1, 10},
+		{10, 0, 0},
+		{10, 2, 20},
+		{10, 0.25, 3}, // 2.5 rounds up
+		{7, 0.5, 4},
+		{9, 0.33, 3},
+	}
+
+	for _, tt := range tests {
+		if got := Mitigate(tt.raw, tt.multiplier); got != tt.want {
+			t.Errorf("Mitigate(%d, %v) = %d, want %d", tt.raw, tt.multiplier, got, tt.want)
+		}
+	}
+}
+
+func TestHitRecordsRawAndMitigatedDamage(t *testing.T) {
+	tests := []struct {
+		name        string
+		damageType  string
+		resistances map[string]float64
+		wantType    string
+		multiplier  float64
+	}{
+		{"resisted", types.DamageLaser, map[string]float64{types.DamageLaser: 0.5}, types.DamageLaser, 0.5},
+		{"weakness clamped", types.DamagePlasma, map[string]float64{types.DamagePlasma: 9}, types.DamagePlasma, MaxResistance},
+		{"immune", types.DamageEMP, map[string]float64{types.DamageEMP: 0}, types.DamageEMP, 0},
+		{"defense without a type is kinetic", "", map[string]float64{types.DamageKinetic: 0.5}, types.DamageKinetic, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBattle(t, types.Planet{HP: 100})
+			a := place(t, b, types.AlienTemplate{Name: "Mirror", HP: 1000, Resistances: tt.resistances}, 10)
+			d := &defense{system: types.DefenseSystem{Name: "Gun", Damage: 15, DamageType: tt.damageType}}
+
+			b.hit(d, a)
+
+			events := b.Result().Events
+			hit := events[len(events)-1]
+			if hit.Kind != types.EventHit {
+				t.Fatalf("last event is %s, want %s", hit.Kind, types.EventHit)
+			}
+			if hit.Raw != 15 && hit.Raw != 15*CritMultiplier {
+				t.Errorf("Raw = %d, want 15 or a critical %d", hit.Raw, 15*CritMultiplier)
+			}
+			if want := Mitigate(hit.Raw, tt.multiplier); hit.Amount != want || a.HP != 1000-want {
+				t.Errorf("Amount = %d and alien HP %d, want %d damage", hit.Amount, a.HP, want)
+			}
+			if hit.DamageType != tt.wantType || hit.Multiplier != tt.multiplier {
+				t.Errorf("hit %s x%v, want %s x%v", hit.DamageType, hit.Multiplier, tt.wantType, tt.multiplier)
+			}
+		})
+	}
+}
+
+func TestValidateDamageType(t *testing.T) {
+	for _, dt := range append([]string{""}, types.DamageTypes...) {
+		if err := ValidateDamageType(dt); err != nil {
+			t.Errorf("ValidateDamageType(%q) = %v", dt, err)
+		}
+	}
+	if err := ValidateDamageType("sonic"); !errors.Is(err, ErrUnknownDamageType) {
+		t.Errorf("ValidateDamageType(sonic) = %v, want ErrUnknownDamageType", err)
+	}
+}
@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// shieldEffectiveness is how much damage of each type one shield point
+// stops. Shields shrug off lasers but EMP burns through them.
+var shieldEffectiveness = map[string]float64{
+	types.DamageKinetic:   1,
+	types.DamageLaser:     1.5,
+	types.DamagePlasma:    1,
+	types.DamageEMP:       0.5,
+	types.DamageExplosive: 0.75,
+}
+
+// ShieldEffectiveness returns how much damage of the given type one shield
+// point stops. Unknown types count as kinetic.
+func ShieldEffectiveness(damageType string) float64 {
+	if e, ok := shieldEffectiveness[damageType]; ok {
+		return e
+	}
+	return 1
+}
+
+// alienDamageType returns the damage type of an alien's attacks, defaulting
+// to kinetic.
+func alienDamageType(a *Alien) string {
+	if a.Template.DamageType == "" {
+		return types.DamageKinetic
+	}
+	return a.Template.DamageType
+}
+
+// absorb lets the shields stop as much of dmg from a as they can and
+// returns the damage stopped. Any hit, absorbed or not, resets the shield
+// regeneration delay.
+func (b *Battle) absorb(a *Alien, dmg int) int {
+	b.lastHit = b.tick
+
+	e := ShieldEffectiveness(alienDamageType(a))
+	if b.shields <= 0 || dmg <= 0 || e <= 0 {
+		return 0
+	}
+
+	absorbed := min(dmg, int(math.Floor(float64(b.shields)*e)))
+	b.shields -= min(b.shields, int(math.Ceil(float64(absorbed)/e)))
+	if absorbed > 0 {
+		b.Emit(types.BattleEvent{
+			Kind:       types.EventShieldAbsorb,
+			Source:     a.Name(),
+			Target:     types.PlanetTarget,
+			Amount:     absorbed,
+			Raw:        dmg,
+			DamageType: alienDamageType(a),
+			Multiplier: e,
+		})
+	}
+	return absorbed
+}
+
+// regenShields recharges the shields once no hit has landed for the
+// planet's regeneration delay.
+func (b *Battle) regenShields() {
+	if b.shields >= b.maxShields || b.tick-b.lastHit < b.regenDelay {
+		b.regenCharge = 0
+		return
+	}
+
+	b.regenCharge += b.regenRate / TicksPerSecond
+	if b.regenCharge < 1 {
+		return
+	}
+	points := min(int(b.regenCharge), b.maxShields-b.shields)
+	b.regenCharge -= float64(int(b.regenCharge))
+	b.shields += points
+	b.Emit(types.BattleEvent{Kind: types.EventShieldRegen, Target: types.PlanetTarget, Amount: points})
+}
@@ -0,0 +1,485 @@
+// Package simulation runs tick-based battles between a planet's defenses and
+// an incoming alien wave.
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+const (
+	// TicksPerSecond is the simulation resolution. Alien Speed and defense
+	// FireRate are expressed per second and scaled down to ticks.
+	TicksPerSecond = 10
+
+	// SpawnDistance is how far from the planet aliens enter the battle, in the
+	// same units as DefenseSystem.Range.
+	SpawnDistance = 100.0
+
+	// SpawnInterval is the number of ticks between two consecutive spawns.
+	SpawnInterval = 5
+
+	// SpawnJitter is the maximum random delay, in ticks, added to a spawn.
+	SpawnJitter = 3
+
+	// CritChance is the probability that a defense shot is a critical hit,
+	// dealing CritMultiplier times its damage.
+	CritChance     = 0.1
+	CritMultiplier = 2
+
+	// RetargetChance is the probability that a defense ignores its preferred
+	// target and fires at another alien in range.
+	RetargetChance = 0.25
+
+	// MaxTicks caps a battle at ten minutes of simulated time.
+	MaxTicks = 10 * 60 * TicksPerSecond
+
+	// alienAttackCooldown is the number of ticks between two attacks of an
+	// alien that has reached the planet.
+	alienAttackCooldown = TicksPerSecond
+)
+
+var (
+	ErrUnknownAlien  = errors.New("unknown alien template")
+	ErrInvalidSpawn  = errors.New("invalid wave spawn")
+	ErrInvalidPlanet = errors.New("invalid planet")
+)
+
+// IsInvalidInput reports whether err was caused by a bad Input rather than
+// by the engine itself.
+func IsInvalidInput(err error) bool {
+	return errors.Is(err, ErrUnknownAlien) ||
+		errors.Is(err, ErrInvalidSpawn) ||
+		errors.Is(err, ErrInvalidPlanet) ||
+		errors.Is(err, ErrUnknownBehavior) ||
+		errors.Is(err, ErrInvalidTemplate) ||
+		errors.Is(err, ErrUnknownDamageType)
+}
+
+// Input is everything a battle needs. Templates are keyed by AlienTemplate.ID
+// and must cover every WaveSpawn.AlienID of the wave.
+//
+// Every random decision is drawn from Seed, so running the same Input twice
+// yields the same result and event list. Timestamp is copied to the result
+// as-is; it defaults to the current time when zero.
+type Input struct {
+	Planet    types.Planet
+	Wave      types.Wave
+	Templates map[string]types.AlienTemplate
+	Seed      int64
+	Timestamp time.Time
+}
+
+// Alien is a single spawned instance of an AlienTemplate.
+type Alien struct {
+	ID       int
+	Template types.AlienTemplate
+	HP       int
+	MaxHP    int
+	Distance float64
+
+	behavior       Behavior
+	spawnTick      int
+	attackCooldown int
+}
+
+// Name identifies the alien in the event log.
+func (a *Alien) Name() string {
+	return fmt.Sprintf("%s#%d", a.Template.Name, a.ID)
+}
+
+// Alive reports whether the alien is still in the fight.
+func (a *Alien) Alive() bool {
+	return a.HP > 0
+}
+
+type defense struct {
+	system types.DefenseSystem
+	charge float64
+}
+
+// Battle is the state of a running simulation. Behaviors receive it to
+// inspect the field and act on the planet.
+type Battle struct {
+	rng     *rand.Rand
+	tick    int
+	done    bool
+	hp      int
+	shields int
+
+	maxShields  int
+	regenRate   float64 // shield points per second
+	regenDelay  int     // ticks without hits before shields regenerate
+	regenCharge float64 // fraction of a shield point regenerated so far
+	lastHit     int
+
+	pending   []*Alien
+	active    []*Alien
+	defenses  []*defense
+	seed      int64
+	timestamp time.Time
+	result    types.SimulationResult
+}
+
+// Run simulates the wave attacking the planet until every alien is destroyed,
+// the planet falls or MaxTicks is reached.
+func Run(in Input) (types.SimulationResult, error) {
+	b, err := NewBattle(in)
+	if err != nil {
+		return types.SimulationResult{}, err
+	}
+
+	for !b.Done() {
+		b.Step()
+	}
+	return b.Result(), nil
+}
+
+// NewBattle sets up a battle to be advanced one tick at a time with Step.
+// Stepping it to the end gives the same result as Run with the same Input.
+func NewBattle(in Input) (*Battle, error) {
+	if in.Planet.HP <= 0 {
+		return nil, fmt.Errorf("%w: planet %s has no HP left", ErrInvalidPlanet, in.Planet.ID)
+	}
+	if in.Planet.ShieldRegen < 0 || in.Planet.ShieldRegenDelay < 0 {
+		return nil, fmt.Errorf("%w: planet %s has negative shield regeneration", ErrInvalidPlanet, in.Planet.ID)
+	}
+
+	// Reject bad templates up front rather than in the middle of a fight.
+	for _, t := range in.Templates {
+		if err := ValidateTemplate(t); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &Battle{
+		rng:       NewRand(in.Seed),
+		hp:        in.Planet.HP,
+		shields:   max(in.Planet.Shields, 0),
+		seed:      in.Seed,
+		timestamp: in.Timestamp,
+	}
+	// Planets without a shield maximum keep whatever shields they start with.
+	b.maxShields = max(in.Planet.MaxShields, b.shields)
+	b.regenRate = in.Planet.ShieldRegen
+	b.regenDelay = int(math.Ceil(in.Planet.ShieldRegenDelay * TicksPerSecond))
+	b.lastHit = -b.regenDelay
+	if b.timestamp.IsZero() {
+		b.timestamp = time.Now().UTC()
+	}
+
+	for _, d := range in.Planet.Defenses {
+		if err := ValidateDamageType(d.DamageType); err != nil {
+			return nil, fmt.Errorf("defense %s: %w", d.Name, err)
+		}
+		b.defenses = append(b.defenses, &defense{system: d})
+	}
+
+	aliens, err := expandWave(in.Wave, in.Templates, b.rng)
+	if err != nil {
+		return nil, err
+	}
+	b.pending = aliens
+
+	return b, nil
+}
+
+// Step simulates one tick and returns the events it produced. It does
+// nothing once the battle is done.
+func (b *Battle) Step() []types.BattleEvent {
+	if b.done {
+		return nil
+	}
+
+	start := len(b.result.Events)
+	b.spawn()
+	b.fireDefenses()
+	b.moveAliens()
+	b.attackPlanet()
+	b.specials()
+	b.regenShields()
+	b.removeDead()
+
+	b.done = b.hp <= 0 || (len(b.pending) == 0 && len(b.active) == 0) || b.tick+1 >= MaxTicks
+	if !b.done {
+		b.tick++
+	}
+	return b.result.Events[start:len(b.result.Events):len(b.result.Events)]
+}
+
+// Done reports whether every alien is destroyed, the planet has fallen or
+// MaxTicks was reached.
+func (b *Battle) Done() bool {
+	return b.done
+}
+
+// Result returns the battle's result so far. It is final once Done.
+func (b *Battle) Result() types.SimulationResult {
+	result := b.result
+	result.HPRemaining = max(b.hp, 0)
+	result.ShieldsRemaining = b.shields
+	switch {
+	case b.hp <= 0:
+		result.Outcome = types.OutcomeDefeat
+	case len(b.pending) > 0 || len(b.active) > 0:
+		result.Outcome = types.OutcomeStalemate
+	default:
+		result.Outcome = types.OutcomeVictory
+	}
+	result.Seed = b.seed
+	result.Timestamp = b.timestamp
+	return result
+}
+
+// NewRand returns the random source used for a battle with the given seed.
+func NewRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(seed), uint64(seed)^0x9e3779b97f4a7c15))
+}
+
+// expandWave turns the wave's spawn entries into alien instances, each
+// scheduled SpawnInterval ticks after the previous one plus a random jitter.
+func expandWave(w types.Wave, templates map[string]types.AlienTemplate, rng *rand.Rand) ([]*Alien, error) {
+	var aliens []*Alien
+	for _, s := range w.Aliens {
+		if s.Count <= 0 {
+			return nil, fmt.Errorf("%w: count for alien %s must be positive", ErrInvalidSpawn, s.AlienID)
+		}
+		tmpl, ok := templates[s.AlienID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownAlien, s.AlienID)
+		}
+		for range s.Count {
+			behavior, err := NewBehavior(tmpl.BehaviorType)
+			if err != nil {
+				return nil, err
+			}
+			n := len(aliens)
+			aliens = append(aliens, &Alien{
+				ID:        n + 1,
+				Template:  tmpl,
+				HP:        tmpl.HP,
+				MaxHP:     tmpl.HP,
+				Distance:  SpawnDistance,
+				behavior:  behavior,
+				spawnTick: n*SpawnInterval + rng.IntN(SpawnJitter+1),
+			})
+		}
+	}
+	slices.SortStableFunc(aliens, func(a, b *Alien) int {
+		return a.spawnTick - b.spawnTick
+	})
+	return aliens, nil
+}
+
+// Tick returns the current tick number.
+func (b *Battle) Tick() int {
+	return b.tick
+}
+
+// Rand returns the battle's seeded random source. Behaviors must use it for
+// every random decision to keep battles replayable.
+func (b *Battle) Rand() *rand.Rand {
+	return b.rng
+}
+
+// Aliens returns the aliens currently on the field.
+func (b *Battle) Aliens() []*Alien {
+	return b.active
+}
+
+// Shields returns the planet's current shield points.
+func (b *Battle) Shields() int {
+	return b.shields
+}
+
+func (b *Battle) spawn() {
+	for len(b.pending) > 0 && b.pending[0].spawnTick <= b.tick {
+		a := b.pending[0]
+		b.pending = b.pending[1:]
+		b.active = append(b.active, a)
+		b.Emit(types.BattleEvent{Kind: types.EventSpawn, Target: a.Name(), Distance: a.Distance})
+	}
+}
+
+func (b *Battle) fireDefenses() {
+	for _, d := range b.defenses {
+		d.charge += d.system.FireRate / TicksPerSecond
+		for d.charge >= 1 {
+			target := b.pickTarget(float64(d.system.Range))
+			if target == nil {
+				// Nothing to shoot at; don't bank shots for later.
+				d.charge = min(d.charge, 1)
+				break
+			}
+			d.charge--
+			b.hit(d, target)
+		}
+	}
+}
+
+// pickTarget returns the alien in range r with the highest threat, breaking
+// ties by distance, or occasionally a random alien in range.
+func (b *Battle) pickTarget(r float64) *Alien {
+	var target *Alien
+	var inRange []*Alien
+	for _, a := range b.active {
+		if !a.Alive() || a.Distance > r {
+			continue
+		}
+		inRange = append(inRange, a)
+		if target == nil || preferTarget(a, target) {
+			target = a
+		}
+	}
+	if len(inRange) > 1 && b.rng.Float64() < RetargetChance {
+		target = inRange[b.rng.IntN(len(inRange))]
+	}
+	return target
+}
+
+func preferTarget(a, current *Alien) bool {
+	ta, tc := a.behavior.Threat(a), current.behavior.Threat(current)
+	if ta != tc {
+		return ta > tc
+	}
+	return a.Distance < current.Distance
+}
+
+// hit fires one shot of d at a. Critical hits scale the raw damage before
+// the alien's resistance to the defense's damage type is applied.
+func (b *Battle) hit(d *defense, a *Alien) {
+	raw := d.system.Damage
+	crit := b.rng.Float64() < CritChance
+	if crit {
+		raw *= CritMultiplier
+	}
+	b.Emit(types.BattleEvent{Kind: types.EventFire, Source: d.system.Name, Target: a.Name(), Raw: raw, Critical: crit})
+
+	dt := damageType(d.system)
+	multiplier := ResistanceMultiplier(a.Template, dt)
+	dmg := Mitigate(raw, multiplier)
+	a.HP -= dmg
+	b.Emit(types.BattleEvent{
+		Kind:       types.EventHit,
+		Source:     d.system.Name,
+		Target:     a.Name(),
+		Amount:     dmg,
+		Raw:        raw,
+		DamageType: dt,
+		Multiplier: multiplier,
+	})
+
+	if a.Alive() {
+		return
+	}
+
+	b.result.AliensDestroyed++
+	b.Emit(types.BattleEvent{Kind: types.EventAlienDestroyed, Source: d.system.Name, Target: a.Name()})
+
+	loot := a.Template.LootDrop
+	if loot != (types.Resources{}) {
+		b.result.Loot = b.result.Loot.Add(loot)
+		b.Emit(types.BattleEvent{Kind: types.EventLoot, Source: a.Name(), Loot: &loot})
+	}
+}
+
+// removeDead drops aliens killed this tick from the field.
+func (b *Battle) removeDead() {
+	alive := b.active[:0]
+	for _, a := range b.active {
+		if a.Alive() {
+			alive = append(alive, a)
+		}
+	}
+	b.active = alive
+}
+
+func (b *Battle) moveAliens() {
+	for _, a := range b.active {
+		if a.Alive() {
+			a.behavior.Move(b, a)
+		}
+	}
+}
+
+func (b *Battle) attackPlanet() {
+	for _, a := range b.active {
+		if !a.Alive() || a.Distance > 0 {
+			continue
+		}
+		if a.attackCooldown > 0 {
+			a.attackCooldown--
+			continue
+		}
+		a.attackCooldown = alienAttackCooldown - 1
+		a.behavior.Attack(b, a)
+		if b.hp <= 0 {
+			b.Emit(types.BattleEvent{Kind: types.EventPlanetDestroyed, Target: types.PlanetTarget})
+			return
+		}
+	}
+}
+
+func (b *Battle) specials() {
+	for _, a := range b.active {
+		if a.Alive() {
+			a.behavior.Special(b, a)
+		}
+	}
+}
+
+// Advance moves the alien up to dist units toward the planet.
+func (b *Battle) Advance(a *Alien, dist float64) {
+	a.Distance = max(a.Distance-dist, 0)
+}
+
+// DamagePlanet applies incoming damage from a to shields first and the
+// remainder to HP. How much the shields stop depends on the alien's damage
+// type, see ShieldEffectiveness.
+func (b *Battle) DamagePlanet(a *Alien, dmg int) {
+	absorbed := b.absorb(a, dmg)
+	hit := dmg - absorbed
+	b.result.DamageTaken += absorbed + min(hit, max(b.hp, 0))
+	b.hp -= hit
+	b.Emit(types.BattleEvent{Kind: types.EventPlanetHit, Source: a.Name(), Target: types.PlanetTarget, Amount: hit, Raw: dmg})
+}
+
+// DamageShields applies damage from a to the planet's shields only. Damage
+// the shields can't stop is lost and not counted as taken.
+func (b *Battle) DamageShields(a *Alien, dmg int) {
+	b.result.DamageTaken += b.absorb(a, dmg)
+}
+
+// Heal restores up to amount HP to a, without exceeding its maximum.
+func (b *Battle) Heal(source, a *Alien, amount int) {
+	healed := min(amount, a.MaxHP-a.HP)
+	if healed <= 0 {
+		return
+	}
+	a.HP += healed
+	b.Emit(types.BattleEvent{Kind: types.EventHeal, Source: source.Name(), Target: a.Name(), Amount: healed})
+}
+
+// SelfDestruct removes a from the battle without counting it as destroyed
+// by the defenses, so it drops no loot.
+func (b *Battle) SelfDestruct(a *Alien) {
+	a.HP = 0
+	b.Emit(types.BattleEvent{Kind: types.EventSelfDestruct, Source: a.Name()})
+}
+
+// Emit appends e to the battle's event log, stamped with the current tick.
+func (b *Battle) Emit(e types.BattleEvent) {
+	e.Tick = b.tick
+	b.result.Events = append(b.result.Events, e)
+}
+
+// Logf appends a free-text message to the battle's event log.
+func (b *Battle) Logf(format string, args ...any) {
+	b.Emit(types.BattleEvent{Kind: types.EventMessage, Text: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,233 @@
+package simulation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+// Templates the tests fight with, keyed by ID.
+var (
+	drone = types.AlienTemplate{ID: "drone", Name: "Drone", HP: 20, Damage: 5, Speed: 20, LootDrop: types.Resources{Minerals: 3}}
+	brute = types.AlienTemplate{ID: "brute", Name: "Brute", HP: 500, Damage: 40, Speed: 30, BehaviorType: "tank"}
+	dummy = types.AlienTemplate{ID: "dummy", Name: "Dummy", HP: 10_000, Speed: 50}
+)
+
+var turret = types.DefenseSystem{Name: "Turret", Damage: 15, Range: 60, FireRate: 4, DamageType: types.DamageKinetic}
+
+// testInput is a fight between a planet with defenses and a wave of count
+// aliens of each template.
+func testInput(seed int64, defenses []types.DefenseSystem, count int, templates ...types.AlienTemplate) Input {
+	in := Input{
+		Planet:    types.Planet{ID: "planet", HP: 200, MaxHP: 200, Shields: 20, MaxShields: 20, ShieldRegen: 2, ShieldRegenDelay: 1, Defenses: defenses},
+		Templates: map[string]types.AlienTemplate{},
+		Seed:      seed,
+		Timestamp: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+	}
+	for _, t := range templates {
+		in.Templates[t.ID] = t
+		in.Wave.Aliens = append(in.Wave.Aliens, types.WaveSpawn{AlienID: t.ID, Count: count})
+	}
+	return in
+}
+
+// countEvents returns how many of the result's events are of kind, and the
+// sum of their amounts.
+func countEvents(result types.SimulationResult, kind string) (n, amount int) {
+	for _, e := range result.Events {
+		if e.Kind == kind {
+			n++
+			amount += e.Amount
+		}
+	}
+	return n, amount
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        Input
+		outcome   string
+		destroyed int
+		loot      types.Resources
+	}{
+		{
+			name:      "defenses win",
+			in:        testInput(1, []types.DefenseSystem{turret, turret}, 5, drone),
+			outcome:   types.OutcomeVictory,
+			destroyed: 5,
+			loot:      types.Resources{Minerals: 15},
+		},
+		{
+			name:    "undefended planet falls",
+			in:      testInput(1, nil, 3, brute),
+			outcome: types.OutcomeDefeat,
+		},
+		{
+			name:    "harmless aliens outlast the battle",
+			in:      testInput(1, nil, 1, dummy),
+			outcome: types.OutcomeStalemate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Outcome != tt.outcome {
+				t.Errorf("Outcome = %s, want %s", result.Outcome, tt.outcome)
+			}
+			if result.AliensDestroyed != tt.destroyed {
+				t.Errorf("AliensDestroyed = %d, want %d", result.AliensDestroyed, tt.destroyed)
+			}
+			if result.Loot != tt.loot {
+				t.Errorf("Loot = %+v, want %+v", result.Loot, tt.loot)
+			}
+			if n, _ := countEvents(result, types.EventAlienDestroyed); n != result.AliensDestroyed {
+				t.Errorf("%d alien_destroyed events for %d aliens destroyed", n, result.AliensDestroyed)
+			}
+			if n, _ := countEvents(result, types.EventPlanetDestroyed); (n == 1) != (tt.outcome == types.OutcomeDefeat) {
+				t.Errorf("%d planet_destroyed events in a %s", n, result.Outcome)
+			}
+			if tt.outcome == types.OutcomeDefeat && result.HPRemaining != 0 {
+				t.Errorf("HPRemaining = %d after a defeat", result.HPRemaining)
+			}
+
+			_, absorbed := countEvents(result, types.EventShieldAbsorb)
+			if lost := tt.in.Planet.HP - result.HPRemaining; result.DamageTaken != absorbed+lost {
+				t.Errorf("DamageTaken = %d, want absorbed %d plus HP lost %d", result.DamageTaken, absorbed, lost)
+			}
+			if result.Seed != tt.in.Seed || !result.Timestamp.Equal(tt.in.Timestamp) {
+				t.Errorf("result seed %d at %v, want %d at %v", result.Seed, result.Timestamp, tt.in.Seed, tt.in.Timestamp)
+			}
+		})
+	}
+}
+
+func TestRunRejectsInvalidInput(t *testing.T) {
+	valid := func() Input { return testInput(1, []types.DefenseSystem{turret}, 1, drone) }
+	tests := []struct {
+		name   string
+		modify func(*Input)
+		want   error
+	}{
+		{"planet without HP", func(in *Input) { in.Planet.HP = 0 }, ErrInvalidPlanet},
+		{"negative shield regeneration", func(in *Input) { in.Planet.ShieldRegen = -1 }, ErrInvalidPlanet},
+		{"unknown alien", func(in *Input) { in.Wave.Aliens[0].AlienID = "ghost" }, ErrUnknownAlien},
+		{"empty spawn", func(in *Input) { in.Wave.Aliens[0].Count = 0 }, ErrInvalidSpawn},
+		{"unknown defense damage type", func(in *Input) { in.Planet.Defenses[0].DamageType = "sonic" }, ErrUnknownDamageType},
+		{"invalid template", func(in *Input) { in.Templates["drone"] = types.AlienTemplate{ID: "drone", HP: 0} }, ErrInvalidTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := valid()
+			tt.modify(&in)
+
+			_, err := Run(in)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			if !IsInvalidInput(err) {
+				t.Errorf("IsInvalidInput(%v) = false", err)
+			}
+		})
+	}
+}
+
+func TestDamageTaken(t *testing.T) {
+	tests := []struct {
+		name       string
+		hp         int
+		shields    int
+		damageType string
+		shieldOnly bool // hit with DamageShields, like a shield breaker
+		dmg        int
+		want       int
+	}{
+		{name: "shields absorb part", hp: 100, shields: 5, dmg: 10, want: 10},
+		{name: "no shields", hp: 100, dmg: 10, want: 10},
+		{name: "overkill is not counted", hp: 4, dmg: 10, want: 4},
+		{name: "lasers are absorbed at 1.5x", hp: 100, shields: 4, damageType: types.DamageLaser, dmg: 10, want: 10},
+		{name: "shield hit fully absorbed", hp: 100, shields: 30, shieldOnly: true, dmg: 20, want: 20},
+		{name: "shield hit overflow is lost", hp: 100, shields: 5, shieldOnly: true, dmg: 20, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBattle(Input{Planet: types.Planet{HP: tt.hp, MaxHP: tt.hp, Shields: tt.shields}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := &Alien{ID: 1, Template: types.AlienTemplate{Name: "Drone", DamageType: tt.damageType}}
+
+			if tt.shieldOnly {
+				b.DamageShields(a, tt.dmg)
+			} else {
+				b.DamagePlanet(a, tt.dmg)
+			}
+
+			result := b.Result()
+			if result.DamageTaken != tt.want {
+				t.Errorf("DamageTaken = %d, want %d", result.DamageTaken, tt.want)
+			}
+			absorbed := 0
+			for _, e := range result.Events {
+				if e.Kind == types.EventShieldAbsorb {
+					absorbed += e.Amount
+				}
+			}
+			if lost := tt.hp - result.HPRemaining; result.DamageTaken != absorbed+lost {
+				t.Errorf("DamageTaken = %d, want absorbed %d plus HP lost %d", result.DamageTaken, absorbed, lost)
+			}
+		})
+	}
+}
+
+func TestSameSeedReplays(t *testing.T) {
+	laser := types.DefenseSystem{Name: "Laser", Damage: 8, Range: 80, FireRate: 6, DamageType: types.DamageLaser}
+	aliens := []types.AlienTemplate{drone, brute}
+	for _, name := range []string{"swarm", "kamikaze", "shield_breaker", "healer"} {
+		aliens = append(aliens, types.AlienTemplate{ID: name, Name: name, HP: 40, Damage: 6, Speed: 25, BehaviorType: name})
+	}
+	input := func(seed int64) Input { return testInput(seed, []types.DefenseSystem{turret, laser}, 3, aliens...) }
+
+	for _, seed := range []int64{0, 1, 42, -7} {
+		first, err := Run(input(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := Run(input(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("seed %d: two runs differ", seed)
+		}
+
+		// Stepping a battle by hand plays out the same as Run.
+		b, err := NewBattle(input(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var events []types.BattleEvent
+		for !b.Done() {
+			events = append(events, b.Step()...)
+		}
+		if stepped := b.Result(); !reflect.DeepEqual(stepped, first) || !reflect.DeepEqual(events, first.Events) {
+			t.Fatalf("seed %d: stepping differs from Run", seed)
+		}
+	}
+
+	a, _ := Run(input(1))
+	b, _ := Run(input(2))
+	if reflect.DeepEqual(a.Events, b.Events) {
+		t.Error("seeds 1 and 2 produced the same events")
+	}
+}
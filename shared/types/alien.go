@@ -7,10 +7,13 @@ type AlienTemplate struct {
 	Name         string             `json:"name" db:"name"`
 	HP           int                `json:"hp" db:"hp"`
 	Damage       int                `json:"damage" db:"damage"`
+	DamageType   string             `json:"damage_type" db:"damage_type"` // of the alien's attacks, defaults to kinetic
 	Speed        float64            `json:"speed" db:"speed"`
 	BehaviorType string             `json:"behavior_type" db:"behavior_type"` // used to instantiate behavior
 	Resistances  map[string]float64 `json:"resistances" db:"resistances"`     // JSONB
 	LootDrop     Resources          `json:"loot_drop" db:"loot_drop"`         // JSONB
+	Version      int                `json:"version" db:"version"`
+	RetiredAt    *time.Time         `json:"retired_at,omitempty" db:"retired_at"` // retired templates can't be used in new waves
 }
 
 type Wave struct {
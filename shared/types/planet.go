@@ -10,23 +10,116 @@ type Resources struct {
 	TechParts int `json:"tech_parts" db:"tech_parts"`
 }
 
+// Add returns the sum of r and o.
+func (r Resources) Add(o Resources) Resources {
+	return Resources{
+		Minerals:  r.Minerals + o.Minerals,
+		Energy:    r.Energy + o.Energy,
+		TechParts: r.TechParts + o.TechParts,
+	}
+}
+
+// Sub returns r minus o.
+func (r Resources) Sub(o Resources) Resources {
+	return Resources{
+		Minerals:  r.Minerals - o.Minerals,
+		Energy:    r.Energy - o.Energy,
+		TechParts: r.TechParts - o.TechParts,
+	}
+}
+
+// Covers reports whether r holds at least as much of every resource as cost.
+func (r Resources) Covers(cost Resources) bool {
+	return r.Minerals >= cost.Minerals && r.Energy >= cost.Energy && r.TechParts >= cost.TechParts
+}
+
+// Planet statuses. A planet is destroyed when its HP reaches 0 and stays
+// destroyed until it is rebuilt.
+const (
+	PlanetActive    = "active"
+	PlanetDestroyed = "destroyed"
+)
+
 type Planet struct {
-	ID          string          `json:"id" db:"id"`
-	Name        string          `json:"name" db:"name"`
-	HP          int             `json:"hp" db:"hp"`
-	Shields     int             `json:"shields" db:"shields"`
-	Resources   Resources       `json:"resources" db:"resources"` // JSONB
-	Defenses    []DefenseSystem `json:"defenses,omitempty" db:"-"`
-	LastUpdated time.Time       `json:"last_updated" db:"last_updated"`
+	ID               string          `json:"id" db:"id"`
+	PlayerID         string          `json:"player_id" db:"player_id"`
+	Name             string          `json:"name" db:"name"`
+	HP               int             `json:"hp" db:"health"`
+	MaxHP            int             `json:"max_hp" db:"max_health"`
+	Shields          int             `json:"shields" db:"shields"`
+	MaxShields       int             `json:"max_shields" db:"max_shields"`
+	ShieldRegen      float64         `json:"shield_regen" db:"shield_regen"`             // shield points per second in battle
+	ShieldRegenDelay float64         `json:"shield_regen_delay" db:"shield_regen_delay"` // seconds without hits before shields regenerate
+	Status           string          `json:"status" db:"status"`
+	DestroyedAt      *time.Time      `json:"destroyed_at,omitempty" db:"destroyed_at"`
+	CurrentWave      int             `json:"current_wave" db:"current_wave"`
+	Resources        Resources       `json:"resources" db:"resources"` // JSONB
+	Defenses         []DefenseSystem `json:"defenses,omitempty" db:"-"`
+	LastUpdated      time.Time       `json:"last_updated" db:"updated_at"`
 }
 
 type DefenseSystem struct {
 	ID          string    `json:"id" db:"id"`
 	PlanetID    string    `json:"planet_id" db:"planet_id"`
+	Kind        string    `json:"kind" db:"kind"`
 	Name        string    `json:"name" db:"name"`
 	Damage      int       `json:"damage" db:"damage"`
 	Range       int       `json:"range" db:"range"`
 	FireRate    float64   `json:"fire_rate" db:"fire_rate"`
+	DamageType  string    `json:"damage_type" db:"damage_type"`
 	Level       int       `json:"level" db:"level"`
 	UpgradeCost Resources `json:"upgrade_cost" db:"upgrade_cost"` // JSONB
+	Position    int       `json:"position" db:"position"`         // slot on the planet, defenses fire in slot order
+
+	UpgradeStartedAt   *time.Time `json:"upgrade_started_at,omitempty" db:"upgrade_started_at"`
+	UpgradeCompletesAt *time.Time `json:"upgrade_completes_at,omitempty" db:"upgrade_completes_at"` // set while the next level is being installed
 }
+
+// Research is a tech a planet has studied.
+type Research struct {
+	ID                  string     `json:"id" db:"id"`
+	PlanetID            string     `json:"planet_id" db:"planet_id"`
+	Tech                string     `json:"tech" db:"tech"`
+	Level               int        `json:"level" db:"level"`
+	ResearchStartedAt   *time.Time `json:"research_started_at,omitempty" db:"research_started_at"`
+	ResearchCompletesAt *time.Time `json:"research_completes_at,omitempty" db:"research_completes_at"` // set while the next level is in progress
+	ResearchProgress    float64    `json:"research_progress,omitempty" db:"-"`                         // 0 to 1
+}
+
+// LedgerEntry records resources credited to a planet from outside its own
+// production, such as battle loot.
+type LedgerEntry struct {
+	ID           string    `json:"id" db:"id"`
+	PlanetID     string    `json:"planet_id" db:"planet_id"`
+	Source       string    `json:"source" db:"source"`
+	ReferenceID  string    `json:"reference_id" db:"reference_id"` // e.g. the battle the loot came from
+	Base         Resources `json:"base" db:"base"`                 // JSONB, before bonuses
+	BonusPercent int       `json:"bonus_percent" db:"bonus_percent"`
+	Amount       Resources `json:"amount" db:"amount"` // JSONB, what was credited
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Building is a resource producing structure on a planet.
+type Building struct {
+	ID               string     `json:"id" db:"id"`
+	PlanetID         string     `json:"planet_id" db:"planet_id"`
+	Kind             string     `json:"kind" db:"kind"`
+	Level            int        `json:"level" db:"level"`
+	Production       Resources  `json:"production" db:"-"` // per production tick at the current level
+	BuildStartedAt   *time.Time `json:"build_started_at,omitempty" db:"build_started_at"`
+	BuildCompletesAt *time.Time `json:"build_completes_at,omitempty" db:"build_completes_at"` // set while the next level is under construction
+	BuildProgress    float64    `json:"build_progress,omitempty" db:"-"`                      // 0 to 1
+}
+
+// Damage types dealt by defense systems. AlienTemplate.Resistances is keyed
+// by these values.
+const (
+	DamageKinetic   = "kinetic"
+	DamageLaser     = "laser"
+	DamagePlasma    = "plasma"
+	DamageEMP       = "emp"
+	DamageExplosive = "explosive"
+)
+
+// DamageTypes lists every known damage type.
+var DamageTypes = []string{DamageKinetic, DamageLaser, DamagePlasma, DamageEMP, DamageExplosive}
@@ -1,18 +1,128 @@
 package types
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type SimulationRequest struct {
 	PlanetID string `json:"planet_id"`
 	WaveID   string `json:"wave_id"`
+	Seed     *int64 `json:"seed,omitempty"` // random seed; generated when omitted
 }
 
+// Battle outcomes. A stalemate is a battle that hit the simulation's tick
+// limit with aliens still alive.
+const (
+	OutcomeVictory   = "victory"
+	OutcomeDefeat    = "defeat"
+	OutcomeStalemate = "stalemate"
+)
+
 type SimulationResult struct {
-	DamageTaken      int       `json:"damage_taken"`
-	ShieldsRemaining int       `json:"shields_remaining"`
-	HPRemaining      int       `json:"hp_remaining"`
-	AliensDestroyed  int       `json:"aliens_destroyed"`
-	Loot             Resources `json:"loot"`
-	Events           []string  `json:"events,omitempty"`
-	Timestamp        time.Time `json:"timestamp"`
+	Outcome          string        `json:"outcome"`
+	DamageTaken      int           `json:"damage_taken"` // what the shields absorbed plus the HP lost
+	ShieldsRemaining int           `json:"shields_remaining"`
+	HPRemaining      int           `json:"hp_remaining"`
+	AliensDestroyed  int           `json:"aliens_destroyed"`
+	Loot             Resources     `json:"loot"`
+	Events           []BattleEvent `json:"events,omitempty"`
+	EventLog         []string      `json:"event_log,omitempty"` // text rendering of Events, only filled on request
+	Seed             int64         `json:"seed"`                // replaying with the same seed reproduces the battle
+	Timestamp        time.Time     `json:"timestamp"`
+}
+
+// Battle event kinds. EventMessage carries free text from a behavior, or a
+// line of an event log recorded before events were structured.
+const (
+	EventSpawn           = "spawn"
+	EventFire            = "fire"
+	EventHit             = "hit"
+	EventShieldAbsorb    = "shield_absorb"
+	EventShieldRegen     = "shield_regen"
+	EventPlanetHit       = "planet_hit"
+	EventAlienDestroyed  = "alien_destroyed"
+	EventLoot            = "loot"
+	EventHeal            = "heal"
+	EventSelfDestruct    = "self_destruct"
+	EventPlanetDestroyed = "planet_destroyed"
+	EventMessage         = "message"
+)
+
+// BattleEvent is one step of a battle. Source and Target name the defense or
+// alien involved, or "planet". Amount is the effective amount (damage dealt,
+// shield points absorbed, HP healed) and Raw the amount before resistances
+// and shields.
+type BattleEvent struct {
+	Tick       int        `json:"tick"`
+	Kind       string     `json:"kind"`
+	Source     string     `json:"source,omitempty"`
+	Target     string     `json:"target,omitempty"`
+	Amount     int        `json:"amount,omitempty"`
+	Raw        int        `json:"raw,omitempty"`
+	DamageType string     `json:"damage_type,omitempty"`
+	Multiplier float64    `json:"multiplier,omitempty"`
+	Critical   bool       `json:"critical,omitempty"`
+	Distance   float64    `json:"distance,omitempty"`
+	Loot       *Resources `json:"loot,omitempty"`
+	Text       string     `json:"text,omitempty"`
+}
+
+// PlanetTarget is the Source or Target of events involving the planet.
+const PlanetTarget = "planet"
+
+// String renders the event as a line of the classic text event log.
+func (e BattleEvent) String() string {
+	var line string
+	switch e.Kind {
+	case EventSpawn:
+		line = fmt.Sprintf("%s spawned at distance %.0f", e.Target, e.Distance)
+	case EventFire:
+		line = fmt.Sprintf("%s fired at %s", e.Source, e.Target)
+		if e.Critical {
+			line = fmt.Sprintf("%s scored a critical hit on %s", e.Source, e.Target)
+		}
+	case EventHit:
+		line = fmt.Sprintf("%s hit %s for %d %s damage (raw %d, x%.2f)", e.Source, e.Target, e.Amount, e.DamageType, e.Raw, e.Multiplier)
+	case EventShieldAbsorb:
+		line = fmt.Sprintf("shields absorbed %d %s damage from %s", e.Amount, e.DamageType, e.Source)
+	case EventShieldRegen:
+		line = fmt.Sprintf("shields recharged by %d", e.Amount)
+	case EventPlanetHit:
+		line = fmt.Sprintf("%s hit the planet for %d (%d absorbed by shields)", e.Source, e.Raw, e.Raw-e.Amount)
+	case EventAlienDestroyed:
+		line = fmt.Sprintf("%s destroyed", e.Target)
+	case EventLoot:
+		var loot Resources
+		if e.Loot != nil {
+			loot = *e.Loot
+		}
+		line = fmt.Sprintf("%s dropped %d minerals, %d energy and %d tech parts", e.Source, loot.Minerals, loot.Energy, loot.TechParts)
+	case EventHeal:
+		line = fmt.Sprintf("%s healed %s for %d", e.Source, e.Target, e.Amount)
+	case EventSelfDestruct:
+		line = fmt.Sprintf("%s self-destructed", e.Source)
+	case EventPlanetDestroyed:
+		line = "planet destroyed"
+	default:
+		line = e.Text
+	}
+	return fmt.Sprintf("[tick %d] %s", e.Tick, line)
+}
+
+// RenderEventLog fills EventLog from Events.
+func (r *SimulationResult) RenderEventLog() {
+	r.EventLog = make([]string, len(r.Events))
+	for i, e := range r.Events {
+		r.EventLog[i] = e.String()
+	}
+}
+
+// Battle is a fought battle as stored in the battle history.
+type Battle struct {
+	ID       string `json:"id"`
+	PlanetID string `json:"planet_id"`
+	WaveID   string `json:"wave_id"`
+	SimulationResult
+	LootCredited *Resources `json:"loot_credited,omitempty"` // loot with bonuses, as credited to the planet
 }
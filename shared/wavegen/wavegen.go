@@ -0,0 +1,148 @@
+// Package wavegen builds waves procedurally from a difficulty and a seed.
+package wavegen
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/novaru/scallopticon/shared/simulation"
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+const (
+	// BaseBudget is the threat budget of a difficulty 1 wave; every further
+	// difficulty level multiplies it by BudgetGrowth.
+	BaseBudget   = 100.0
+	BudgetGrowth = 1.25
+
+	// Weights used to turn an alien's stats into a threat cost.
+	damageWeight = 4.0
+	speedWeight  = 2.0
+
+	// MaxDifficulty is the hardest wave Generate builds. Its budget is already
+	// in the tens of thousands; past it waves would grow without bound.
+	MaxDifficulty = 30
+
+	// maxKinds caps the number of distinct aliens in a generated wave.
+	maxKinds = 4
+)
+
+var (
+	ErrInvalidDifficulty = errors.New("difficulty must be at least 1")
+	ErrDifficultyTooHigh = fmt.Errorf("difficulty must be at most %d", MaxDifficulty)
+	ErrNoTemplates       = errors.New("no alien templates available")
+)
+
+// Budget returns the threat budget of a wave of the given difficulty.
+func Budget(difficulty int) float64 {
+	return BaseBudget * math.Pow(BudgetGrowth, float64(difficulty-1))
+}
+
+// Cost returns the threat one alien of template t adds to a wave.
+func Cost(t types.AlienTemplate) float64 {
+	return float64(t.HP) + float64(t.Damage)*damageWeight + t.Speed*speedWeight
+}
+
+// ClampDifficulty caps a wave number at MaxDifficulty, so planets past the
+// last difficulty keep fighting waves of that difficulty.
+func ClampDifficulty(n int) int {
+	return min(n, MaxDifficulty)
+}
+
+// SeedFor derives the seed of a planet's n-th wave, so asking for the same
+// wave twice yields the same aliens.
+func SeedFor(planetID string, n int) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s/%d", planetID, n)
+	return int64(h.Sum64())
+}
+
+// Generate builds a wave whose total alien cost fits the budget for
+// difficulty. Retired templates are skipped. The same difficulty, seed and
+// templates always produce the same wave.
+//
+// The one exception to the budget is when every template costs more than
+// it: the wave is then a single alien of the cheapest template, so a planet
+// always has something to fight rather than an empty or failed wave.
+func Generate(difficulty int, seed int64, templates []types.AlienTemplate) (types.Wave, error) {
+	if difficulty < 1 {
+		return types.Wave{}, ErrInvalidDifficulty
+	}
+	if difficulty > MaxDifficulty {
+		return types.Wave{}, ErrDifficultyTooHigh
+	}
+
+	var pool []types.AlienTemplate
+	for _, t := range templates {
+		if t.RetiredAt == nil && Cost(t) > 0 {
+			pool = append(pool, t)
+		}
+	}
+	if len(pool) == 0 {
+		return types.Wave{}, ErrNoTemplates
+	}
+	// Sort so the result doesn't depend on the order templates were loaded in.
+	slices.SortFunc(pool, func(a, b types.AlienTemplate) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	rng := simulation.NewRand(seed)
+	budget := Budget(difficulty)
+
+	affordable := slices.DeleteFunc(slices.Clone(pool), func(t types.AlienTemplate) bool {
+		return Cost(t) > budget
+	})
+	if len(affordable) == 0 {
+		// Even the weakest alien is over budget; send a single one of it
+		// anyway, see above.
+		cheapest := slices.MinFunc(pool, func(a, b types.AlienTemplate) int {
+			return cmp.Compare(Cost(a), Cost(b))
+		})
+		return types.Wave{
+			Difficulty: difficulty,
+			Aliens:     []types.WaveSpawn{{AlienID: cheapest.ID, Count: 1}},
+		}, nil
+	}
+
+	kinds := min(len(affordable), 1+difficulty/3, maxKinds)
+	rng.Shuffle(len(affordable), func(i, j int) {
+		affordable[i], affordable[j] = affordable[j], affordable[i]
+	})
+	chosen := affordable[:kinds]
+
+	// Give each kind a random share of the budget and buy as many of it as
+	// the share allows, then spend what is left on the cheapest kind.
+	weights := make([]float64, len(chosen))
+	var total float64
+	for i := range chosen {
+		weights[i] = 1 + rng.Float64()
+		total += weights[i]
+	}
+
+	counts := make([]int, len(chosen))
+	remaining := budget
+	for i, t := range chosen {
+		counts[i] = int(budget * weights[i] / total / Cost(t))
+		remaining -= float64(counts[i]) * Cost(t)
+	}
+	cheapest := 0
+	for i, t := range chosen {
+		if Cost(t) < Cost(chosen[cheapest]) {
+			cheapest = i
+		}
+	}
+	counts[cheapest] += int(remaining / Cost(chosen[cheapest]))
+
+	wave := types.Wave{Difficulty: difficulty}
+	for i, t := range chosen {
+		if counts[i] > 0 {
+			wave.Aliens = append(wave.Aliens, types.WaveSpawn{AlienID: t.ID, Count: counts[i]})
+		}
+	}
+	return wave, nil
+}
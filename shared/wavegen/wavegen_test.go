@@ -0,0 +1,196 @@
+package wavegen
+
+import (
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/novaru/scallopticon/shared/types"
+)
+
+var templates = []types.AlienTemplate{
+	{ID: "drone", HP: 20, Damage: 2, Speed: 10},    // costs 48
+	{ID: "runner", HP: 15, Damage: 1, Speed: 40},   // costs 99
+	{ID: "brute", HP: 200, Damage: 20, Speed: 5},   // costs 290
+	{ID: "bomber", HP: 60, Damage: 30, Speed: 20},  // costs 220
+	{ID: "medic", HP: 80, Damage: 5, Speed: 15},    // costs 130
+	{ID: "titan", HP: 5000, Damage: 100, Speed: 2}, // costs 5404
+}
+
+// waveCost returns the total cost of w and the cost of its cheapest alien.
+func waveCost(t *testing.T, w types.Wave) (total, cheapest float64) {
+	t.Helper()
+
+	byID := map[string]types.AlienTemplate{}
+	for _, tmpl := range templates {
+		byID[tmpl.ID] = tmpl
+	}
+	for _, s := range w.Aliens {
+		tmpl, ok := byID[s.AlienID]
+		if !ok {
+			t.Fatalf("wave uses unknown alien %q", s.AlienID)
+		}
+		if s.Count <= 0 {
+			t.Fatalf("wave has %d of %q", s.Count, s.AlienID)
+		}
+		total += float64(s.Count) * Cost(tmpl)
+		if cheapest == 0 || Cost(tmpl) < cheapest {
+			cheapest = Cost(tmpl)
+		}
+	}
+	return total, cheapest
+}
+
+func TestGenerateFitsBudget(t *testing.T) {
+	for difficulty := 1; difficulty <= MaxDifficulty; difficulty++ {
+		for seed := range int64(20) {
+			w, err := Generate(difficulty, seed, templates)
+			if err != nil {
+				t.Fatalf("difficulty %d seed %d: %v", difficulty, seed, err)
+			}
+			if w.Difficulty != difficulty {
+				t.Fatalf("Difficulty = %d, want %d", w.Difficulty, difficulty)
+			}
+			if kinds := len(w.Aliens); kinds == 0 || kinds > maxKinds || kinds > 1+difficulty/3 {
+				t.Fatalf("difficulty %d seed %d: %d kinds of alien", difficulty, seed, kinds)
+			}
+
+			total, cheapest := waveCost(t, w)
+			budget := Budget(difficulty)
+			if total > budget {
+				t.Fatalf("difficulty %d seed %d: wave costs %.1f, over budget %.1f", difficulty, seed, total, budget)
+			}
+			// What is left over can't buy another of the cheapest kind.
+			if budget-total >= cheapest {
+				t.Fatalf("difficulty %d seed %d: wave costs %.1f, leaving %.1f of budget %.1f unspent",
+					difficulty, seed, total, budget-total, budget)
+			}
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	for _, difficulty := range []int{1, 5, 12, MaxDifficulty} {
+		first, err := Generate(difficulty, 99, templates)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		again, err := Generate(difficulty, 99, templates)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, again) {
+			t.Errorf("difficulty %d: same seed gave %v and %v", difficulty, first.Aliens, again.Aliens)
+		}
+
+		// Template order doesn't matter.
+		shuffled := append([]types.AlienTemplate(nil), templates...)
+		rand.New(rand.NewPCG(1, 2)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		reordered, err := Generate(difficulty, 99, shuffled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(first, reordered) {
+			t.Errorf("difficulty %d: reordering templates changed the wave from %v to %v", difficulty, first.Aliens, reordered.Aliens)
+		}
+	}
+
+	// Different seeds don't all produce the same wave.
+	first, _ := Generate(10, 0, templates)
+	for seed := int64(1); seed < 10; seed++ {
+		w, _ := Generate(10, seed, templates)
+		if !reflect.DeepEqual(first, w) {
+			return
+		}
+	}
+	t.Error("ten seeds produced the same wave")
+}
+
+func TestGenerateSkipsRetiredTemplates(t *testing.T) {
+	retired := time.Now()
+	pool := append([]types.AlienTemplate(nil), templates...)
+	for i := range pool {
+		if pool[i].ID != "brute" {
+			pool[i].RetiredAt = &retired
+		}
+	}
+
+	for seed := range int64(10) {
+		w, err := Generate(8, seed, pool)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(w.Aliens) != 1 || w.Aliens[0].AlienID != "brute" {
+			t.Fatalf("seed %d: wave %v uses retired templates", seed, w.Aliens)
+		}
+	}
+}
+
+func TestGenerateOverBudgetSendsOneCheapestAlien(t *testing.T) {
+	expensive := []types.AlienTemplate{templates[5], templates[2]} // titan and brute, both over 100
+	w, err := Generate(1, 1, expensive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.WaveSpawn{{AlienID: "brute", Count: 1}}
+	if !reflect.DeepEqual(w.Aliens, want) {
+		t.Errorf("Aliens = %v, want %v", w.Aliens, want)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	retired := time.Now()
+	tests := []struct {
+		name       string
+		difficulty int
+		templates  []types.AlienTemplate
+		want       error
+	}{
+		{"difficulty 0", 0, templates, ErrInvalidDifficulty},
+		{"difficulty too high", MaxDifficulty + 1, templates, ErrDifficultyTooHigh},
+		{"no templates", 1, nil, ErrNoTemplates},
+		{"only retired templates", 1, []types.AlienTemplate{{ID: "old", HP: 10, RetiredAt: &retired}}, ErrNoTemplates},
+		{"only free templates", 1, []types.AlienTemplate{{ID: "nothing"}}, ErrNoTemplates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Generate(tt.difficulty, 1, tt.templates); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBudgetGrows(t *testing.T) {
+	if Budget(1) != BaseBudget {
+		t.Errorf("Budget(1) = %v, want %v", Budget(1), BaseBudget)
+	}
+	for d := 2; d <= MaxDifficulty; d++ {
+		if Budget(d) <= Budget(d-1) {
+			t.Fatalf("Budget(%d) = %v is not above Budget(%d) = %v", d, Budget(d), d-1, Budget(d-1))
+		}
+	}
+}
+
+func TestClampDifficulty(t *testing.T) {
+	for n, want := range map[int]int{1: 1, MaxDifficulty: MaxDifficulty, MaxDifficulty + 1: MaxDifficulty, 1000: MaxDifficulty} {
+		if got := ClampDifficulty(n); got != want {
+			t.Errorf("ClampDifficulty(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestSeedFor(t *testing.T) {
+	if SeedFor("planet-a", 3) != SeedFor("planet-a", 3) {
+		t.Error("SeedFor is not stable")
+	}
+	if SeedFor("planet-a", 3) == SeedFor("planet-a", 4) || SeedFor("planet-a", 3) == SeedFor("planet-b", 3) {
+		t.Error("SeedFor gives different waves the same seed")
+	}
+}